@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/config"
+	"console-ai/pkg/gemini"
+)
+
+// pingTimeout bounds the test request to the Gemini API
+const pingTimeout = 15 * time.Second
+
+// checkStatus is the outcome of one --check diagnostic
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+)
+
+// checkResult is the outcome of one diagnostic with an explanation
+type checkResult struct {
+	name   string
+	status checkStatus
+	detail string
+}
+
+// shellCommands give the AI an unrestricted command line when allowlisted
+var shellCommands = []string{"sh", "bash", "zsh", "fish", "cmd", "cmd.exe", "powershell", "pwsh"}
+
+// runChecks runs the --check diagnostics, prints a report to w and returns
+// the exit code: 1 when any check failed, 0 otherwise.
+func runChecks(cfg *config.Config, w io.Writer) int {
+	results := []checkResult{
+		checkAPIKey(cfg),
+		checkGemini(cfg),
+		checkAllowlist(cfg.AllowedCommands),
+		checkHistoryWritable(cfg.ConversationHistory),
+		checkProject(cfg),
+	}
+
+	code := 0
+	for _, result := range results {
+		fmt.Fprintf(w, "[%s] %-10s %s\n", result.status, result.name, result.detail)
+		if result.status == checkFail {
+			code = 1
+		}
+	}
+	if code != 0 {
+		fmt.Fprintln(w, "\nSome checks failed.")
+	} else {
+		fmt.Fprintln(w, "\nAll checks passed.")
+	}
+	return code
+}
+
+// checkAPIKey verifies that an API key is configured
+func checkAPIKey(cfg *config.Config) checkResult {
+	if cfg.GeminiAPIKey == "" {
+		return checkResult{"API key", checkFail, "not set; export GEMINI_API_KEY (get one at https://aistudio.google.com/apikey)"}
+	}
+	return checkResult{"API key", checkPass, "set"}
+}
+
+// checkGemini sends a token count request to verify that the API is
+// reachable, the key is accepted and the model exists
+func checkGemini(cfg *config.Config) checkResult {
+	if cfg.GeminiAPIKey == "" {
+		return checkResult{"Gemini", checkFail, "skipped, no API key"}
+	}
+	model, client, err := gemini.NewClient(cfg, cfg.ModelName)
+	if err != nil {
+		return checkResult{"Gemini", checkFail, err.Error()}
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := gemini.Ping(ctx, model); err != nil {
+		return checkResult{"Gemini", checkFail, fmt.Sprintf("%s: %v", cfg.ModelName, err)}
+	}
+	return checkResult{"Gemini", checkPass, fmt.Sprintf("%s is reachable", cfg.ModelName)}
+}
+
+// checkAllowlist verifies that the allowed commands can match anything and
+// points out entries that give away a whole shell
+func checkAllowlist(allowed []string) checkResult {
+	if len(allowed) == 0 {
+		return checkResult{"Allowlist", checkWarn, "no commands are allowed; the AI can't run builds or tests"}
+	}
+
+	var invalid, shells []string
+	for _, command := range allowed {
+		switch {
+		case command == "" || strings.ContainsAny(command, " \t") || command != strings.ToLower(command):
+			// Commands are matched by their lowercase first word only
+			invalid = append(invalid, fmt.Sprintf("%q", command))
+		case containsCommand(shellCommands, command):
+			shells = append(shells, command)
+		}
+	}
+
+	var problems []string
+	status := checkPass
+	if len(invalid) > 0 {
+		status = checkFail
+		problems = append(problems, "entries that never match, use single lowercase command names: "+strings.Join(invalid, ", "))
+	}
+	if len(shells) > 0 {
+		status = worse(status, checkWarn)
+		problems = append(problems, "shells allow any command: "+strings.Join(shells, ", "))
+	}
+	if len(problems) == 0 {
+		return checkResult{"Allowlist", checkPass, fmt.Sprintf("%d commands allowed", len(allowed))}
+	}
+	return checkResult{"Allowlist", status, strings.Join(problems, "; ")}
+}
+
+// checkHistoryWritable verifies that the session history can be saved
+func checkHistoryWritable(path string) checkResult {
+	if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+		f.Close()
+		return checkResult{"History", checkPass, fmt.Sprintf("%s is writable", path)}
+	} else if !os.IsNotExist(err) {
+		return checkResult{"History", checkFail, fmt.Sprintf("%s is not writable: %v", path, err)}
+	}
+
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, ".cb-check-*")
+	if err != nil {
+		return checkResult{"History", checkFail, fmt.Sprintf("can't create %s in %s: %v", filepath.Base(path), dir, err)}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return checkResult{"History", checkPass, fmt.Sprintf("%s will be created on the first save", path)}
+}
+
+// checkProject verifies that the project root can be analyzed in time
+func checkProject(cfg *config.Config) checkResult {
+	analyzer := agent.NewProjectAnalyzer(cfg.ProjectRoot)
+	analyzer.IgnorePatterns = cfg.Agent.IgnorePatterns
+	ctx, cancel := context.WithTimeout(context.Background(), startupAnalysisTimeout)
+	defer cancel()
+
+	info, err := analyzer.AnalyzeProject(ctx)
+	switch {
+	case err != nil:
+		return checkResult{"Project", checkFail, err.Error()}
+	case info.Truncated:
+		return checkResult{"Project", checkWarn, fmt.Sprintf("analysis stopped after %s with %d files; skip generated directories with CONSOLE_AI_IGNORE_PATTERNS", startupAnalysisTimeout, len(info.Files))}
+	case info.Language == "" || info.Language == "Unknown":
+		return checkResult{"Project", checkWarn, fmt.Sprintf("no known project type detected in %s", cfg.ProjectRoot)}
+	}
+	return checkResult{"Project", checkPass, fmt.Sprintf("%s project with %d files in %s", info.Language, len(info.Files), cfg.ProjectRoot)}
+}
+
+// worse returns the more severe of two statuses
+func worse(a, b checkStatus) checkStatus {
+	if a == checkFail || b == checkFail {
+		return checkFail
+	}
+	if a == checkWarn || b == checkWarn {
+		return checkWarn
+	}
+	return checkPass
+}
+
+// containsCommand reports whether commands holds command
+func containsCommand(commands []string, command string) bool {
+	for _, c := range commands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
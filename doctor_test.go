@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/config"
+)
+
+// checkConfig returns the default configuration without an API key, rooted
+// in a temporary directory
+func checkConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig("")
+	if err != nil && !errors.Is(err, config.ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	cfg.GeminiAPIKey = ""
+	cfg.ProjectRoot = t.TempDir()
+	cfg.ConversationHistory = filepath.Join(cfg.ProjectRoot, "CB.hist")
+	return cfg
+}
+
+func TestCheckAPIKey(t *testing.T) {
+	cfg := checkConfig(t)
+	if result := checkAPIKey(cfg); result.status != checkFail {
+		t.Errorf("without a key: %+v, want FAIL", result)
+	}
+	cfg.GeminiAPIKey = "key"
+	if result := checkAPIKey(cfg); result.status != checkPass {
+		t.Errorf("with a key: %+v, want PASS", result)
+	}
+}
+
+func TestCheckGeminiWithoutKey(t *testing.T) {
+	if result := checkGemini(checkConfig(t)); result.status != checkFail || !strings.Contains(result.detail, "skipped") {
+		t.Errorf("checkGemini = %+v, want a skipped FAIL", result)
+	}
+}
+
+func TestCheckAllowlist(t *testing.T) {
+	tests := []struct {
+		allowed []string
+		want    checkStatus
+		detail  string
+	}{
+		{[]string{"go", "git"}, checkPass, "2 commands allowed"},
+		{nil, checkWarn, "no commands are allowed"},
+		{[]string{"go", "bash"}, checkWarn, "shells allow any command: bash"},
+		{[]string{"go test"}, checkFail, `"go test"`},
+		{[]string{"Go"}, checkFail, `"Go"`},
+		{[]string{"", "sh"}, checkFail, "shells allow any command: sh"},
+	}
+	for _, tt := range tests {
+		result := checkAllowlist(tt.allowed)
+		if result.status != tt.want || !strings.Contains(result.detail, tt.detail) {
+			t.Errorf("checkAllowlist(%q) = %+v, want %s with %q", tt.allowed, result, tt.want, tt.detail)
+		}
+	}
+}
+
+func TestCheckHistoryWritable(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "CB.hist")
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := checkHistoryWritable(existing); result.status != checkPass || !strings.Contains(result.detail, "is writable") {
+		t.Errorf("existing file: %+v", result)
+	}
+	if result := checkHistoryWritable(filepath.Join(dir, "new.hist")); result.status != checkPass || !strings.Contains(result.detail, "will be created") {
+		t.Errorf("new file: %+v", result)
+	}
+	if result := checkHistoryWritable(filepath.Join(dir, "missing", "CB.hist")); result.status != checkFail {
+		t.Errorf("missing directory: %+v, want FAIL", result)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("the check left %d files behind", len(entries)-1)
+	}
+}
+
+func TestCheckProject(t *testing.T) {
+	cfg := checkConfig(t)
+	if result := checkProject(cfg); result.status != checkWarn {
+		t.Errorf("empty directory: %+v, want WARN", result)
+	}
+
+	if err := os.WriteFile(filepath.Join(cfg.ProjectRoot, "go.mod"), []byte("module example\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := checkProject(cfg); result.status != checkPass || !strings.Contains(result.detail, "Go project") {
+		t.Errorf("Go project: %+v, want PASS", result)
+	}
+}
+
+func TestWorse(t *testing.T) {
+	tests := []struct{ a, b, want checkStatus }{
+		{checkPass, checkPass, checkPass},
+		{checkPass, checkWarn, checkWarn},
+		{checkFail, checkWarn, checkFail},
+		{checkWarn, checkFail, checkFail},
+	}
+	for _, tt := range tests {
+		if got := worse(tt.a, tt.b); got != tt.want {
+			t.Errorf("worse(%s, %s) = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRunChecksReportsFailures(t *testing.T) {
+	var out bytes.Buffer
+	if code := runChecks(checkConfig(t), &out); code != 1 {
+		t.Errorf("exit code = %d, want 1 without an API key", code)
+	}
+
+	report := out.String()
+	for _, want := range []string{"[FAIL] API key", "[FAIL] Gemini", "[PASS] History", "Some checks failed."} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/config"
+	"console-ai/pkg/gemini"
+	"console-ai/pkg/history"
+	"console-ai/pkg/logger"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// promptRun holds what a non-interactive run needs besides the prompt
+type promptRun struct {
+	cfg                 *config.Config
+	model               *genai.GenerativeModel
+	projectInfo         *agent.ProjectInfo
+	conversationHistory []string
+	toolActions         [][]history.ToolAction
+	jsonOutput          bool // print a single jsonResult instead of text
+	quiet               bool // print only the reply, without progress lines
+}
+
+// jsonResult is printed by --json runs. Failed runs still produce one, with
+// Error set and whatever tool actions were taken before the failure.
+type jsonResult struct {
+	Reply       string               `json:"reply"`
+	ToolActions []history.ToolAction `json:"tool_actions"`
+	Usage       gemini.TokenUsage    `json:"usage"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// continueConversation runs a turn; tests replace it to script the model
+var continueConversation = gemini.ContinueConversation
+
+// escapePattern matches ANSI escape sequences, which tool output such as
+// colored compiler errors may contain
+var escapePattern = regexp.MustCompile(`\x1b(?:\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// progressLine renders a step as one plain line: escape sequences and
+// other control characters are removed and line breaks collapsed.
+func progressLine(title, content string) string {
+	content = escapePattern.ReplaceAllString(content, "")
+	content = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || r == '\r' {
+			return ' '
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, content)
+	return fmt.Sprintf("%s: %s", title, strings.Join(strings.Fields(content), " "))
+}
+
+// readPrompt builds the prompt for a non-interactive run from the --prompt
+// flag and, with --stdin, everything read from standard input.
+func readPrompt(prompt string, fromStdin bool, stdin io.Reader) (string, error) {
+	if fromStdin {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt from stdin: %w", err)
+		}
+		if input := strings.TrimSpace(string(data)); input != "" {
+			if prompt != "" {
+				prompt += "\n\n"
+			}
+			prompt += input
+		}
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return "", fmt.Errorf("empty prompt")
+	}
+	return prompt, nil
+}
+
+// run sends a single prompt, executing tools as needed, prints the final
+// reply to stdout and returns the process exit code. Progress goes to stderr
+// as plain lines, one per step, so stdout only carries the reply.
+func (r *promptRun) run(ctx context.Context, prompt string, stdout, stderr io.Writer) int {
+	reply, actions, err := continueConversation(ctx, r.model, r.conversationHistory, r.projectInfo, prompt, r.cfg.HumorLevel, r.cfg, func(title, content string) {
+		// The reply is printed once complete; tool output stays out of the way
+		switch title {
+		case gemini.StepThinking, gemini.StepResponse, gemini.StepToolOutput, gemini.StepTransformed:
+		default:
+			if !r.jsonOutput && !r.quiet {
+				fmt.Fprintln(stderr, progressLine(title, content))
+			}
+		}
+	})
+
+	if r.jsonOutput {
+		result := jsonResult{Reply: reply, ToolActions: actions}
+		if result.ToolActions == nil {
+			result.ToolActions = []history.ToolAction{}
+		}
+		if turn := gemini.LastTurn(); turn != nil {
+			result.Usage = turn.TotalUsage()
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		writeJSON(stdout, result)
+	}
+	if err != nil {
+		if !r.jsonOutput {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+		}
+		return 1
+	}
+
+	if !r.jsonOutput {
+		fmt.Fprintln(stdout, reply)
+	}
+
+	toolActions := append(history.AlignToolActions(r.toolActions, r.conversationHistory), actions)
+	conversationHistory := history.PruneHistory(append(r.conversationHistory, prompt, reply), r.cfg.MaxHistoryTurns)
+	toolActions = history.AlignToolActions(toolActions, conversationHistory)
+	if err := history.SaveSession(r.cfg.ConversationHistory, conversationHistory, toolActions, r.projectInfo, r.cfg.HumorLevel, r.cfg.MaxHistoryTurns); err != nil {
+		logger.Warn("Failed to save session: %v", err)
+	}
+	return 0
+}
+
+// writeJSON prints v as a single line of JSON
+func writeJSON(w io.Writer, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Failed to write JSON output: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/config"
+	"console-ai/pkg/gemini"
+	"console-ai/pkg/history"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// fakeTurn replaces the model for the test with one that reports steps and
+// then answers with reply, actions and err
+func fakeTurn(t *testing.T, reply string, actions []history.ToolAction, err error) {
+	t.Helper()
+	previous := continueConversation
+	continueConversation = func(ctx context.Context, model *genai.GenerativeModel, conversationHistory []string, projectInfo *agent.ProjectInfo, input string, humorLevel int, cfg *config.Config, stepCallback func(title, content string)) (string, []history.ToolAction, error) {
+		stepCallback(gemini.StepThinking, "")
+		stepCallback(gemini.StepToolCall, "list_files with args: {\"path\":\".\"}")
+		stepCallback(gemini.StepToolOutput, "main.go\ngo.mod")
+		stepCallback(gemini.StepResponse, reply)
+		return reply, actions, err
+	}
+	t.Cleanup(func() { continueConversation = previous })
+}
+
+// newPromptRun returns a run keeping its session in a temporary file
+func newPromptRun(t *testing.T) *promptRun {
+	t.Helper()
+	cfg, err := config.LoadConfig("")
+	if err != nil && !errors.Is(err, config.ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	cfg.ConversationHistory = filepath.Join(t.TempDir(), "CB.hist")
+	return &promptRun{cfg: cfg}
+}
+
+func TestPromptRunPrintsReply(t *testing.T) {
+	fakeTurn(t, "Created the Dockerfile.", []history.ToolAction{{Name: "create_file"}}, nil)
+	r := newPromptRun(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := r.run(context.Background(), "create a Dockerfile", &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr:\n%s", code, stderr.String())
+	}
+	if stdout.String() != "Created the Dockerfile.\n" {
+		t.Errorf("stdout = %q, want only the reply", stdout.String())
+	}
+	if want := "Tool Call: list_files with args: {\"path\":\".\"}\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+
+	data, err := history.LoadSession(r.cfg.ConversationHistory)
+	if err != nil || data == nil {
+		t.Fatalf("LoadSession = %v, %v", data, err)
+	}
+	if len(data.Conversations) != 2 || data.Conversations[1] != "Created the Dockerfile." || data.ToolActions[0][0].Name != "create_file" {
+		t.Errorf("saved session = %+v", data)
+	}
+}
+
+func TestPromptRunQuiet(t *testing.T) {
+	fakeTurn(t, "Done.", nil, nil)
+	r := newPromptRun(t)
+	r.quiet = true
+
+	var stdout, stderr bytes.Buffer
+	if code := r.run(context.Background(), "hi", &stdout, &stderr); code != 0 || stderr.Len() != 0 {
+		t.Errorf("exit code = %d, stderr = %q; want no progress lines", code, stderr.String())
+	}
+}
+
+// fakeColoredTurn replaces the model for the test with one whose steps
+// carry ANSI escapes, like colored compiler output
+func fakeColoredTurn(t *testing.T, reply string) {
+	t.Helper()
+	previous := continueConversation
+	continueConversation = func(ctx context.Context, model *genai.GenerativeModel, conversationHistory []string, projectInfo *agent.ProjectInfo, input string, humorLevel int, cfg *config.Config, stepCallback func(title, content string)) (string, []history.ToolAction, error) {
+		stepCallback(gemini.StepThinking, "\x1b[2mthinking\x1b[0m")
+		stepCallback(gemini.StepToolCall, "run_tests with args: {}")
+		stepCallback(gemini.StepToolOutput, "\x1b[31mFAIL\x1b[0m pkg/app")
+		stepCallback(gemini.StepToolError, "execution: \x1b[1;31mexit status 1\x1b[0m")
+		stepCallback(gemini.StepResponse, reply)
+		return reply, nil, nil
+	}
+	t.Cleanup(func() { continueConversation = previous })
+}
+
+func TestPromptRunQuietHasNoEscapes(t *testing.T) {
+	fakeColoredTurn(t, "The tests in pkg/app fail.")
+	r := newPromptRun(t)
+	r.quiet = true
+
+	var stdout, stderr bytes.Buffer
+	if code := r.run(context.Background(), "run the tests", &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr:\n%s", code, stderr.String())
+	}
+	if strings.Contains(stdout.String(), "\x1b") || stderr.Len() != 0 {
+		t.Errorf("stdout = %q, stderr = %q; want only the plain reply", stdout.String(), stderr.String())
+	}
+	if stdout.String() != "The tests in pkg/app fail.\n" {
+		t.Errorf("stdout = %q, want the reply", stdout.String())
+	}
+}
+
+func TestPromptRunProgressHasNoEscapes(t *testing.T) {
+	fakeColoredTurn(t, "The tests in pkg/app fail.")
+	r := newPromptRun(t)
+
+	var stdout, stderr bytes.Buffer
+	r.run(context.Background(), "run the tests", &stdout, &stderr)
+
+	want := "Tool Call: run_tests with args: {}\nTool Error: execution: exit status 1\n"
+	if stderr.String() != want {
+		t.Errorf("stderr = %q, want plain progress lines %q", stderr.String(), want)
+	}
+}
+
+func TestPromptRunFailure(t *testing.T) {
+	fakeTurn(t, "", nil, errors.New("stream error: quota exceeded"))
+	r := newPromptRun(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := r.run(context.Background(), "hi", &stdout, &stderr); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), "Error: stream error: quota exceeded") {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+	if data, _ := history.LoadSession(r.cfg.ConversationHistory); data != nil && len(data.Conversations) != 0 {
+		t.Error("a failed run was saved to the session")
+	}
+}
+
+func TestReadPrompt(t *testing.T) {
+	tests := []struct {
+		prompt    string
+		fromStdin bool
+		stdin     string
+		want      string
+	}{
+		{"explain", false, "ignored", "explain"},
+		{"", true, "  from stdin\n", "from stdin"},
+		{"review this diff", true, "+added line\n", "review this diff\n\n+added line"},
+		{"only flag", true, "", "only flag"},
+	}
+	for _, tt := range tests {
+		got, err := readPrompt(tt.prompt, tt.fromStdin, strings.NewReader(tt.stdin))
+		if err != nil || got != tt.want {
+			t.Errorf("readPrompt(%q, %v, %q) = %q, %v; want %q", tt.prompt, tt.fromStdin, tt.stdin, got, err, tt.want)
+		}
+	}
+	if _, err := readPrompt("  ", true, strings.NewReader("\n")); err == nil {
+		t.Error("an empty prompt was accepted")
+	}
+}
+
+func TestProgressLine(t *testing.T) {
+	got := progressLine("Tool Output", "\x1b[31merror\x1b[0m:\n\tmissing\x07 semicolon")
+	if got != "Tool Output: error: missing semicolon" {
+		t.Errorf("progressLine = %q", got)
+	}
+}
+
+// decodeResult decodes the single JSON object of a --json run, checking
+// that it has exactly the documented keys
+func decodeResult(t *testing.T, out string, wantKeys ...string) jsonResult {
+	t.Helper()
+	if strings.Count(strings.TrimSpace(out), "\n") != 0 {
+		t.Errorf("output is not a single line:\n%s", out)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(out), &raw); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	var keys []string
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sort.Strings(wantKeys)
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("keys = %q, want %q", keys, wantKeys)
+	}
+	var usage map[string]int
+	if err := json.Unmarshal(raw["usage"], &usage); err != nil || len(usage) != 3 {
+		t.Errorf("usage = %s, want prompt, response and total tokens", raw["usage"])
+	}
+
+	var result jsonResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestJSONOutputSuccess(t *testing.T) {
+	fakeTurn(t, "Created it.", []history.ToolAction{history.NewToolAction("create_file", `{"path":"Dockerfile"}`, "File created", nil)}, nil)
+	r := newPromptRun(t)
+	r.jsonOutput = true
+
+	var stdout, stderr bytes.Buffer
+	if code := r.run(context.Background(), "create a Dockerfile", &stdout, &stderr); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q, want no progress lines", stderr.String())
+	}
+	result := decodeResult(t, stdout.String(), "reply", "tool_actions", "usage")
+	if result.Reply != "Created it." || len(result.ToolActions) != 1 || result.ToolActions[0].Name != "create_file" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestJSONOutputFailure(t *testing.T) {
+	partial := []history.ToolAction{history.NewToolAction("run_tests", "{}", "", errors.New("tests failed"))}
+	fakeTurn(t, "", partial, errors.New("stream error: context deadline exceeded"))
+	r := newPromptRun(t)
+	r.jsonOutput = true
+
+	var stdout, stderr bytes.Buffer
+	if code := r.run(context.Background(), "run the tests", &stdout, &stderr); code == 0 {
+		t.Error("a failed run exited with 0")
+	}
+	result := decodeResult(t, stdout.String(), "reply", "tool_actions", "usage", "error")
+	if result.Error != "stream error: context deadline exceeded" || len(result.ToolActions) != 1 {
+		t.Errorf("result = %+v, want the error and the actions taken", result)
+	}
+}
+
+func TestJSONOutputWithoutActions(t *testing.T) {
+	fakeTurn(t, "Hi.", nil, nil)
+	r := newPromptRun(t)
+	r.jsonOutput = true
+
+	var stdout bytes.Buffer
+	r.run(context.Background(), "hi", &stdout, io.Discard)
+	if !strings.Contains(stdout.String(), `"tool_actions":[]`) {
+		t.Errorf("output = %s, want an empty tool_actions list rather than null", stdout.String())
+	}
+}
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -19,13 +20,24 @@ type ProjectInfo struct {
 	Dependencies   []string          `json:"dependencies,omitempty"`
 	Scripts        map[string]string `json:"scripts,omitempty"`
 	Files          []string          `json:"files,omitempty"`
+	SubProjects    []*ProjectInfo    `json:"sub_projects,omitempty"` // e.g. Cargo workspace members
+	Truncated      bool              `json:"truncated,omitempty"`    // The file scan was cancelled, Files is incomplete
+	Git            *GitInfo          `json:"git,omitempty"`          // nil outside a git working tree
 }
 
 // ProjectAnalyzer analyzes project structure and context
 type ProjectAnalyzer struct {
 	rootPath string
+
+	// IgnorePatterns are glob patterns of files and directories skipped by
+	// the file scan in addition to defaultIgnoredDirs. A pattern matches
+	// either the base name or the slash-separated path below the root.
+	IgnorePatterns []string
 }
 
+// defaultIgnoredDirs are never scanned; hidden directories are skipped too
+var defaultIgnoredDirs = map[string]bool{"node_modules": true, "vendor": true, "target": true}
+
 // NewProjectAnalyzer creates a new project analyzer
 func NewProjectAnalyzer(rootPath string) *ProjectAnalyzer {
 	return &ProjectAnalyzer{
@@ -33,34 +45,41 @@ func NewProjectAnalyzer(rootPath string) *ProjectAnalyzer {
 	}
 }
 
-// AnalyzeProject analyzes the current project structure
-func (pa *ProjectAnalyzer) AnalyzeProject() (*ProjectInfo, error) {
+// AnalyzeProject analyzes the current project structure.
+// Cancelling ctx aborts the file walk; the files found so far are returned
+// with Truncated set.
+func (pa *ProjectAnalyzer) AnalyzeProject(ctx context.Context) (*ProjectInfo, error) {
 	info := &ProjectInfo{
 		RootPath: pa.rootPath,
 		Scripts:  make(map[string]string),
 	}
 
 	// Detect project language and tools
-	if err := pa.detectLanguageAndTools(info); err != nil {
+	if err := pa.detectLanguageAndTools(ctx, info); err != nil {
 		return nil, fmt.Errorf("failed to detect project language: %w", err)
 	}
 
+	pa.analyzeGit(ctx, info)
+
 	// Scan project files
-	if err := pa.scanProjectFiles(info); err != nil {
-		return nil, fmt.Errorf("failed to scan project files: %w", err)
+	if err := pa.scanProjectFiles(ctx, info); err != nil {
+		if ctx.Err() == nil {
+			return nil, fmt.Errorf("failed to scan project files: %w", err)
+		}
+		info.Truncated = true
 	}
 
 	return info, nil
 }
 
 // detectLanguageAndTools detects the primary language and tools used in the project
-func (pa *ProjectAnalyzer) detectLanguageAndTools(info *ProjectInfo) error {
+func (pa *ProjectAnalyzer) detectLanguageAndTools(ctx context.Context, info *ProjectInfo) error {
 	// Check for Go project
 	if pa.fileExists("go.mod") {
 		info.Language = "Go"
 		info.BuildTool = "go"
 		info.PackageManager = "go"
-		return pa.analyzeGoProject(info)
+		return pa.analyzeGoProject(ctx, info)
 	}
 
 	// Check for Node.js project
@@ -99,7 +118,7 @@ func (pa *ProjectAnalyzer) detectLanguageAndTools(info *ProjectInfo) error {
 }
 
 // analyzeGoProject analyzes Go-specific project details
-func (pa *ProjectAnalyzer) analyzeGoProject(info *ProjectInfo) error {
+func (pa *ProjectAnalyzer) analyzeGoProject(ctx context.Context, info *ProjectInfo) error {
 	// Read go.mod for dependencies
 	goModPath := filepath.Join(pa.rootPath, "go.mod")
 	content, err := os.ReadFile(goModPath)
@@ -138,14 +157,41 @@ func (pa *ProjectAnalyzer) analyzeGoProject(info *ProjectInfo) error {
 		}
 	}
 
+	// Detect web framework
+	info.Framework = goWebFramework(info.Dependencies)
+
 	// Check for common Go testing frameworks
-	if pa.containsImport("github.com/stretchr/testify") {
+	if pa.containsImport(ctx, "github.com/stretchr/testify") {
 		info.TestFramework = "testify"
 	}
 
 	return nil
 }
 
+// goWebFrameworks maps module paths of Go web frameworks to their names
+var goWebFrameworks = []struct {
+	module string
+	name   string
+}{
+	{"github.com/gin-gonic/gin", "Gin"},
+	{"github.com/labstack/echo", "Echo"},
+	{"github.com/gofiber/fiber", "Fiber"},
+	{"github.com/go-chi/chi", "Chi"},
+}
+
+// goWebFramework returns the web framework used by a Go module, matching
+// major-version suffixes such as /v4, or "" if none is found
+func goWebFramework(dependencies []string) string {
+	for _, framework := range goWebFrameworks {
+		for _, dep := range dependencies {
+			if dep == framework.module || strings.HasPrefix(dep, framework.module+"/v") {
+				return framework.name
+			}
+		}
+	}
+	return ""
+}
+
 // analyzeNodeProject analyzes Node.js-specific project details
 func (pa *ProjectAnalyzer) analyzeNodeProject(info *ProjectInfo) error {
 	packagePath := filepath.Join(pa.rootPath, "package.json")
@@ -228,7 +274,8 @@ func (pa *ProjectAnalyzer) analyzePythonProject(info *ProjectInfo) error {
 	return nil
 }
 
-// analyzeRustProject analyzes Rust-specific project details
+// analyzeRustProject analyzes Rust-specific project details, including
+// dev-dependencies and the member crates of a Cargo workspace
 func (pa *ProjectAnalyzer) analyzeRustProject(info *ProjectInfo) error {
 	cargoPath := filepath.Join(pa.rootPath, "Cargo.toml")
 	content, err := os.ReadFile(cargoPath)
@@ -236,53 +283,169 @@ func (pa *ProjectAnalyzer) analyzeRustProject(info *ProjectInfo) error {
 		return err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	inDepsSection := false
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		
-		if strings.HasPrefix(line, "[dependencies]") {
-			inDepsSection = true
-			continue
+	manifest := parseCargoManifest(string(content))
+	info.Dependencies = append(info.Dependencies, manifest.dependencies...)
+	info.Dependencies = append(info.Dependencies, manifest.devDependencies...)
+
+	// Detect test framework from dev-dependencies
+	for _, framework := range []string{"rstest", "proptest", "criterion"} {
+		if pa.containsDependency(manifest.devDependencies, framework) {
+			info.TestFramework = framework
+			break
 		}
-		
-		if strings.HasPrefix(line, "[") && inDepsSection {
-			inDepsSection = false
+	}
+
+	// Analyze each workspace member as a sub-project
+	for _, pattern := range manifest.workspaceMembers {
+		dirs, err := filepath.Glob(filepath.Join(pa.rootPath, pattern))
+		if err != nil {
 			continue
 		}
-		
-		if inDepsSection && strings.Contains(line, "=") {
-			parts := strings.Split(line, "=")
-			if len(parts) >= 2 {
-				dep := strings.TrimSpace(parts[0])
-				info.Dependencies = append(info.Dependencies, dep)
+		for _, dir := range dirs {
+			member := NewProjectAnalyzer(dir)
+			if !member.fileExists("Cargo.toml") {
+				continue
 			}
+			sub := &ProjectInfo{Language: "Rust", BuildTool: "cargo", PackageManager: "cargo"}
+			if rel, err := filepath.Rel(pa.rootPath, dir); err == nil {
+				sub.RootPath = filepath.ToSlash(rel)
+			}
+			if err := member.analyzeRustProject(sub); err != nil {
+				continue
+			}
+			info.SubProjects = append(info.SubProjects, sub)
 		}
 	}
 
 	return nil
 }
 
+// cargoManifest holds the parts of a Cargo.toml used for analysis
+type cargoManifest struct {
+	dependencies     []string
+	devDependencies  []string
+	workspaceMembers []string
+}
+
+// parseCargoManifest extracts dependency names and workspace members from
+// a Cargo.toml. Both inline entries ([dependencies] foo = "1") and table
+// entries ([dependencies.foo]) are recognised.
+func parseCargoManifest(content string) cargoManifest {
+	var manifest cargoManifest
+	section := ""
+	inMembers := false
+
+	for _, line := range strings.Split(content, "\n") {
+		line = stripComment(strings.TrimSpace(line))
+		if line == "" {
+			continue
+		}
+
+		if inMembers {
+			manifest.workspaceMembers = append(manifest.workspaceMembers, quotedStrings(line)...)
+			if strings.Contains(line, "]") {
+				inMembers = false
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[] ")
+			switch {
+			case strings.HasPrefix(section, "dependencies."):
+				manifest.dependencies = append(manifest.dependencies, strings.TrimPrefix(section, "dependencies."))
+			case strings.HasPrefix(section, "dev-dependencies."):
+				manifest.devDependencies = append(manifest.devDependencies, strings.TrimPrefix(section, "dev-dependencies."))
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+
+		switch section {
+		case "dependencies":
+			manifest.dependencies = append(manifest.dependencies, key)
+		case "dev-dependencies":
+			manifest.devDependencies = append(manifest.devDependencies, key)
+		case "workspace":
+			if key == "members" {
+				manifest.workspaceMembers = append(manifest.workspaceMembers, quotedStrings(value)...)
+				inMembers = !strings.Contains(value, "]")
+			}
+		}
+	}
+	return manifest
+}
+
+// stripComment removes a trailing # comment from a TOML line. A # inside a
+// quoted string, such as a git URL fragment, is kept.
+func stripComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return strings.TrimSpace(line[:i])
+		}
+	}
+	return line
+}
+
+// quotedStrings returns the double-quoted strings in s
+func quotedStrings(s string) []string {
+	var values []string
+	for {
+		start := strings.Index(s, `"`)
+		if start < 0 {
+			return values
+		}
+		end := strings.Index(s[start+1:], `"`)
+		if end < 0 {
+			return values
+		}
+		values = append(values, s[start+1:start+1+end])
+		s = s[start+end+2:]
+	}
+}
+
 // scanProjectFiles scans and lists important project files
-func (pa *ProjectAnalyzer) scanProjectFiles(projectInfo *ProjectInfo) error {
+func (pa *ProjectAnalyzer) scanProjectFiles(ctx context.Context, projectInfo *ProjectInfo) error {
 	return filepath.Walk(pa.rootPath, func(path string, fileInfo os.FileInfo, err error) error {
+		// Stop walking as soon as the analysis is cancelled
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil // Continue walking even if there's an error
 		}
 
+		relPath, err := filepath.Rel(pa.rootPath, path)
+		if err != nil {
+			return nil
+		}
+
 		// Skip hidden directories and common ignore patterns
 		if fileInfo.IsDir() {
-			name := fileInfo.Name()
-			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "target" {
+			if relPath != "." && pa.isIgnored(relPath, fileInfo.Name(), true) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-
-		// Add relevant files
-		relPath, err := filepath.Rel(pa.rootPath, path)
-		if err != nil {
+		if pa.isIgnored(relPath, fileInfo.Name(), false) {
 			return nil
 		}
 
@@ -298,6 +461,25 @@ func (pa *ProjectAnalyzer) scanProjectFiles(projectInfo *ProjectInfo) error {
 	})
 }
 
+// isIgnored reports whether the file scan skips relPath, using the default
+// directory skip set and the configured IgnorePatterns
+func (pa *ProjectAnalyzer) isIgnored(relPath, name string, isDir bool) bool {
+	if isDir && (strings.HasPrefix(name, ".") || defaultIgnoredDirs[name]) {
+		return true
+	}
+
+	for _, pattern := range pa.IgnorePatterns {
+		pattern = filepath.FromSlash(strings.TrimSuffix(pattern, "/"))
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, relPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // isRelevantFile determines if a file is relevant for project analysis
 func isRelevantFile(ext, name string) bool {
 	relevantExts := []string{
@@ -330,9 +512,12 @@ func (pa *ProjectAnalyzer) fileExists(filename string) bool {
 	return !os.IsNotExist(err)
 }
 
-func (pa *ProjectAnalyzer) containsImport(importPath string) bool {
+func (pa *ProjectAnalyzer) containsImport(ctx context.Context, importPath string) bool {
 	// This is a simplified check - in practice, you'd parse Go files
 	return filepath.Walk(pa.rootPath, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil || !strings.HasSuffix(path, ".go") {
 			return nil
 		}
@@ -0,0 +1,291 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files below root, creating their directories
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// manyFiles returns n small Go files spread over a few directories
+func manyFiles(n int) map[string]string {
+	files := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		files[fmt.Sprintf("pkg%d/file%d.go", i%10, i)] = "package p\n"
+	}
+	return files
+}
+
+func TestAnalyzeProjectCancelledStopsWalk(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, manyFiles(200))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	info, err := NewProjectAnalyzer(root).AnalyzeProject(ctx)
+	if err != nil {
+		t.Fatalf("AnalyzeProject: %v", err)
+	}
+	if !info.Truncated {
+		t.Error("Truncated = false for a cancelled analysis")
+	}
+	if len(info.Files) != 0 {
+		t.Errorf("found %d files after cancellation, want 0", len(info.Files))
+	}
+}
+
+// countingContext is a context reporting cancellation once Err has been
+// called more than limit times, so a walk can be cancelled part way through
+type countingContext struct {
+	context.Context
+	limit int
+	calls int
+}
+
+func (c *countingContext) Err() error {
+	c.calls++
+	if c.calls > c.limit {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestAnalyzeProjectCancelledMidWalk(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, manyFiles(200))
+
+	ctx := &countingContext{Context: context.Background(), limit: 50}
+	info, err := NewProjectAnalyzer(root).AnalyzeProject(ctx)
+	if err != nil {
+		t.Fatalf("AnalyzeProject: %v", err)
+	}
+	if !info.Truncated {
+		t.Error("Truncated = false for an analysis cancelled mid-walk")
+	}
+	if len(info.Files) == 0 || len(info.Files) >= 200 {
+		t.Errorf("found %d files, want the partial results found before cancellation", len(info.Files))
+	}
+	// The walk checks the context once per entry, so it stops right away
+	if extra := ctx.calls - ctx.limit; extra > 2 {
+		t.Errorf("context checked %d more times after cancellation, want the walk to stop", extra)
+	}
+}
+
+func TestAnalyzeProjectNotTruncated(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, manyFiles(20))
+
+	info, err := NewProjectAnalyzer(root).AnalyzeProject(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Truncated || len(info.Files) != 20 {
+		t.Errorf("Truncated = %v with %d files, want all 20 files", info.Truncated, len(info.Files))
+	}
+}
+
+func TestGoWebFrameworkDetection(t *testing.T) {
+	tests := []struct {
+		require string
+		want    string
+	}{
+		{"github.com/gin-gonic/gin v1.9.1", "Gin"},
+		{"github.com/labstack/echo/v4 v4.11.4", "Echo"},
+		{"github.com/gofiber/fiber/v2 v2.52.0", "Fiber"},
+		{"github.com/go-chi/chi/v5 v5.0.12", "Chi"},
+		{"github.com/go-chi/chi v1.5.5", "Chi"},
+		{"github.com/spf13/cobra v1.8.0", ""},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{
+			"go.mod":  "module example.com/web\n\ngo 1.22\n\nrequire (\n\t" + tt.require + "\n\tgolang.org/x/net v0.20.0 // indirect\n)\n",
+			"main.go": "package main\n",
+		})
+
+		info, err := NewProjectAnalyzer(root).AnalyzeProject(context.Background())
+		if err != nil {
+			t.Fatalf("AnalyzeProject: %v", err)
+		}
+		if info.Language != "Go" || info.Framework != tt.want {
+			t.Errorf("%s: language %q, framework %q; want Go, %q", tt.require, info.Language, info.Framework, tt.want)
+		}
+	}
+}
+
+func TestGoWebFrameworkSingleLineRequire(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/web\n\ngo 1.22\n\nrequire github.com/labstack/echo/v4 v4.11.4\n",
+	})
+	info, err := NewProjectAnalyzer(root).AnalyzeProject(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Framework != "Echo" {
+		t.Errorf("Framework = %q, want Echo", info.Framework)
+	}
+}
+
+func TestRustWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"Cargo.toml": `# Workspace root
+[workspace]
+members = [
+    "crates/core",  # the library
+    "crates/cli",
+]
+
+[dependencies]
+serde = "1.0" # serialization
+
+[dev-dependencies]
+proptest = "1"
+`,
+		"crates/core/Cargo.toml": `[package]
+name = "core"
+
+[dependencies]
+regex = "1"
+tokio = { version = "1", features = ["full"] }
+
+[dependencies.rand]
+version = "0.8"
+
+[dev-dependencies]
+rstest = "0.18"
+`,
+		"crates/cli/Cargo.toml": `[package]
+name = "cli"
+
+[dependencies]
+clap = "4"
+`,
+		"crates/notes/README.md": "not a crate\n",
+	})
+
+	info, err := NewProjectAnalyzer(root).AnalyzeProject(context.Background())
+	if err != nil {
+		t.Fatalf("AnalyzeProject: %v", err)
+	}
+	if info.Language != "Rust" || info.TestFramework != "proptest" {
+		t.Errorf("language %q, test framework %q; want Rust, proptest", info.Language, info.TestFramework)
+	}
+	if !reflect.DeepEqual(info.Dependencies, []string{"serde", "proptest"}) {
+		t.Errorf("Dependencies = %q", info.Dependencies)
+	}
+
+	members := map[string]*ProjectInfo{}
+	for _, sub := range info.SubProjects {
+		members[sub.RootPath] = sub
+	}
+	if len(members) != 2 {
+		t.Fatalf("got %d workspace members, want 2: %+v", len(info.SubProjects), info.SubProjects)
+	}
+	core := members["crates/core"]
+	if core == nil || !reflect.DeepEqual(core.Dependencies, []string{"regex", "tokio", "rand", "rstest"}) || core.TestFramework != "rstest" {
+		t.Errorf("core = %+v", core)
+	}
+	if cli := members["crates/cli"]; cli == nil || !reflect.DeepEqual(cli.Dependencies, []string{"clap"}) {
+		t.Errorf("cli = %+v", cli)
+	}
+}
+
+func TestParseCargoManifestKeepsHashInStrings(t *testing.T) {
+	manifest := parseCargoManifest(`[workspace]
+members = ["crates/#gen", 'tools/a#b'] # generated crates
+
+[dependencies]
+patched = { git = "https://example.com/patched.git#main" } # pinned
+regex = "1"
+`)
+	if !reflect.DeepEqual(manifest.workspaceMembers, []string{"crates/#gen"}) {
+		t.Errorf("workspaceMembers = %q", manifest.workspaceMembers)
+	}
+	if !reflect.DeepEqual(manifest.dependencies, []string{"patched", "regex"}) {
+		t.Errorf("dependencies = %q", manifest.dependencies)
+	}
+
+	for line, want := range map[string]string{
+		`a = "x" # note`:      `a = "x"`,
+		`a = "x#y"`:           `a = "x#y"`,
+		`a = "esc\"#" # note`: `a = "esc\"#"`,
+		`a = 'lit#' # note`:   `a = 'lit#'`,
+		`# whole line`:        ``,
+	} {
+		if got := stripComment(line); got != want {
+			t.Errorf("stripComment(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestIgnorePatternsSkipBazelOutput(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":                   "package main\n",
+		"bazel-out/gen/gen.go":      "package gen\n",
+		"bazel-bin/tool/tool.go":    "package tool\n",
+		"dist/bundle.js":            "",
+		"node_modules/dep/index.js": "",
+		"docs/notes.md":             "",
+		"docs/draft.md":             "",
+	})
+
+	analyzer := NewProjectAnalyzer(root)
+	analyzer.IgnorePatterns = []string{"bazel-*", "dist/", "docs/draft.md"}
+	info, err := analyzer.AnalyzeProject(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"docs/notes.md", "main.go"}
+	var got []string
+	for _, file := range info.Files {
+		got = append(got, filepath.ToSlash(file))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanned files = %q, want %q", got, want)
+	}
+}
+
+func TestDefaultIgnoresWithoutPatterns(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":                 "package main\n",
+		"bazel-out/gen/gen.go":    "package gen\n",
+		"vendor/lib/lib.go":       "package lib\n",
+		".cache/state/state.json": "{}",
+	})
+
+	info, err := NewProjectAnalyzer(root).AnalyzeProject(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"bazel-out/gen/gen.go", "main.go"}
+	var got []string
+	for _, file := range info.Files {
+		got = append(got, filepath.ToSlash(file))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanned files = %q, want %q", got, want)
+	}
+}
@@ -2,8 +2,10 @@ package agent
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 )
 
 // CodeGenerator generates code based on project context and requirements
@@ -31,7 +33,7 @@ func (cg *CodeGenerator) GenerateTemplate(templateType string, context map[strin
 	context["Framework"] = cg.projectInfo.Framework
 
 	var builder strings.Builder
-	t, err := template.New(templateType).Parse(tmpl)
+	t, err := template.New(templateType).Funcs(templateFuncs).Parse(tmpl)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -43,6 +45,22 @@ func (cg *CodeGenerator) GenerateTemplate(templateType string, context map[strin
 	return builder.String(), nil
 }
 
+// templateFuncs are the helper functions available to templates
+var templateFuncs = template.FuncMap{
+	// default returns value, or fallback when value is missing or empty:
+	// {{.Options.port | default "8080"}}
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	// fields splits a command line into words: {{range fields .Options.startCommand}}
+	"fields": func(value interface{}) []string {
+		return strings.Fields(fmt.Sprint(value))
+	},
+}
+
 // GenerateFunction generates a function based on specifications
 func (cg *CodeGenerator) GenerateFunction(functionName, description string, params, returns []string) (string, error) {
 	context := map[string]interface{}{
@@ -85,13 +103,39 @@ func (cg *CodeGenerator) GenerateTest(targetName, testType string) (string, erro
 	return cg.GenerateTemplate(templateType, context)
 }
 
-// GenerateConfigFile generates configuration files
+// makefileLanguages lists the languages with a dedicated Makefile template
+var makefileLanguages = map[string]bool{
+	"Go": true, "JavaScript": true, "TypeScript": true, "Python": true, "Rust": true,
+}
+
+// GenerateConfigFile generates configuration files.
+// Makefiles for languages without a dedicated template use a generic one with
+// build, test, clean and run targets. For an undetected language at least one
+// of those commands must be given in the options.
 func (cg *CodeGenerator) GenerateConfigFile(configType string, options map[string]interface{}) (string, error) {
+	if options == nil {
+		options = make(map[string]interface{})
+	}
+	if options["name"] == nil && cg.projectInfo.RootPath != "" {
+		options["name"] = filepath.Base(cg.projectInfo.RootPath)
+	}
 	context := map[string]interface{}{
 		"Options": options,
 	}
 
 	templateType := fmt.Sprintf("config_%s", configType)
+	if configType == "makefile" && !makefileLanguages[cg.projectInfo.Language] {
+		unknown := cg.projectInfo.Language == "" || cg.projectInfo.Language == "Unknown"
+		if unknown && options["build"] == nil && options["test"] == nil && options["run"] == nil && options["clean"] == nil {
+			return "", fmt.Errorf("cannot generate a Makefile: the project language is unknown; pass build, test, clean or run commands in the options for a generic Makefile")
+		}
+		templateType = "config_makefile_generic"
+	}
+	if configType == "license" {
+		if err := licenseOptions(options); err != nil {
+			return "", err
+		}
+	}
 	return cg.GenerateTemplate(templateType, context)
 }
 
@@ -158,6 +202,11 @@ func (cg *CodeGenerator) getTemplate(templateType string) (string, bool) {
 		"config_dockerfile": dockerfileTemplate,
 		"config_gitignore":  gitignoreTemplate,
 		"config_makefile":   makefileTemplate,
+		"config_makefile_generic": genericMakefileTemplate,
+		"config_readme":           readmeTemplate,
+		"config_editorconfig":     editorconfigTemplate,
+		"config_license":          licenseTemplate,
+		"config_github_actions":   githubActionsTemplate,
 		
 		// Web templates (unique to avoid recitation)
 		"web_html": uniqueHTMLTemplate,
@@ -307,16 +356,15 @@ const dockerfileTemplate = `FROM {{.Options.baseImage | default "alpine:latest"}
 
 WORKDIR /app
 
-{{if .Options.installCommands}}{{range .Options.installCommands}}RUN {{.}}
-{{end}}{{end}}
-
 COPY . .
 
+{{if .Options.installCommands}}{{range .Options.installCommands}}RUN {{.}}
+{{end}}{{end}}
 {{if .Options.buildCommand}}RUN {{.Options.buildCommand}}{{end}}
 
 {{if .Options.port}}EXPOSE {{.Options.port}}{{end}}
 
-CMD ["{{.Options.startCommand | default "echo", "Hello World"}}"]`
+CMD [{{if .Options.startCommand}}{{range $i, $arg := fields .Options.startCommand}}{{if $i}}, {{end}}"{{$arg}}"{{end}}{{else}}"echo", "Hello World"{{end}}]`
 
 const gitignoreTemplate = `# Dependencies
 {{if eq .ProjectInfo.Language "Go"}}vendor/
@@ -368,7 +416,7 @@ CB.hist`
 const makefileTemplate = `{{if eq .ProjectInfo.Language "Go"}}.PHONY: build test clean run
 
 build:
-	go build -o bin/{{.ProjectInfo.Name}} .
+	go build -o bin/{{.Options.name | default "app"}} .
 
 test:
 	go test ./...
@@ -377,7 +425,7 @@ clean:
 	rm -rf bin/
 
 run: build
-	./bin/{{.ProjectInfo.Name}}
+	./bin/{{.Options.name | default "app"}}
 
 install:
 	go mod download
@@ -412,8 +460,273 @@ clean:
 
 dev:
 	python -m pip install -e .
+{{end}}{{if eq .ProjectInfo.Language "Rust"}}.PHONY: build release test clean run
+
+build:
+	cargo build
+
+release:
+	cargo build --release
+
+test:
+	cargo test
+
+clean:
+	cargo clean
+
+run:
+	cargo run
 {{end}}`
 
+const readmeTemplate = `# {{.Options.name | default "Project"}}
+
+{{.Options.description | default "A short description of what this project does."}}
+{{if or .ProjectInfo.Framework .ProjectInfo.Language}}
+Built with {{.ProjectInfo.Language}}{{if .ProjectInfo.Framework}} and {{.ProjectInfo.Framework}}{{end}}.
+{{end}}
+## Installation
+
+` + "```" + `bash
+{{if eq .ProjectInfo.Language "Go"}}go mod download
+{{else if or (eq .ProjectInfo.Language "JavaScript") (eq .ProjectInfo.Language "TypeScript")}}{{.ProjectInfo.PackageManager | default "npm"}} install
+{{else if eq .ProjectInfo.Language "Python"}}pip install -r requirements.txt
+{{else if eq .ProjectInfo.Language "Rust"}}cargo fetch
+{{else}}# Add installation steps here
+{{end}}` + "```" + `
+
+## Build
+
+` + "```" + `bash
+{{if eq .ProjectInfo.Language "Go"}}go build -o {{.Options.name | default "app"}} .
+{{else if or (eq .ProjectInfo.Language "JavaScript") (eq .ProjectInfo.Language "TypeScript")}}{{.ProjectInfo.PackageManager | default "npm"}} run build
+{{else if eq .ProjectInfo.Language "Python"}}python -m build
+{{else if eq .ProjectInfo.Language "Rust"}}cargo build --release
+{{else}}# Add build steps here
+{{end}}` + "```" + `
+
+## Test
+
+` + "```" + `bash
+{{if eq .ProjectInfo.Language "Go"}}go test ./...
+{{else if or (eq .ProjectInfo.Language "JavaScript") (eq .ProjectInfo.Language "TypeScript")}}{{.ProjectInfo.PackageManager | default "npm"}} test
+{{else if eq .ProjectInfo.Language "Python"}}{{if eq .ProjectInfo.TestFramework "pytest"}}pytest{{else}}python -m unittest discover{{end}}
+{{else if eq .ProjectInfo.Language "Rust"}}cargo test
+{{else}}# Add test steps here
+{{end}}` + "```" + `
+{{if .Options.license}}
+## License
+
+{{.Options.license}}
+{{end}}`
+
+const editorconfigTemplate = `root = true
+
+[*]
+charset = utf-8
+end_of_line = lf
+insert_final_newline = true
+trim_trailing_whitespace = true
+indent_style = space
+indent_size = {{.Options.indentSize | default "4"}}
+
+[*.md]
+trim_trailing_whitespace = false
+
+[Makefile]
+indent_style = tab
+{{if eq .ProjectInfo.Language "Go"}}
+[*.go]
+indent_style = tab
+{{end}}{{if or (eq .ProjectInfo.Language "JavaScript") (eq .ProjectInfo.Language "TypeScript")}}
+[*.{js,jsx,ts,tsx,json,css,html}]
+indent_size = 2
+{{end}}{{if eq .ProjectInfo.Language "Python"}}
+[*.py]
+indent_size = 4
+max_line_length = 88
+{{end}}{{if eq .ProjectInfo.Language "Rust"}}
+[*.rs]
+indent_size = 4
+max_line_length = 100
+{{end}}
+[*.{yml,yaml}]
+indent_size = 2
+`
+
+// licenseTemplate renders the license selected by its SPDX id; the id, year
+// and author are filled in by licenseOptions before rendering.
+const licenseTemplate = `{{if eq .Options.license "MIT"}}MIT License
+
+Copyright (c) {{.Options.year}} {{.Options.author}}
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+{{else if eq .Options.license "Apache-2.0"}}Copyright {{.Options.year}} {{.Options.author}}
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+{{else if eq .Options.license "GPL-3.0"}}Copyright (C) {{.Options.year}} {{.Options.author}}
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+{{end}}`
+
+// githubActionsTemplate runs the detected install, build and test commands on
+// pushes and pull requests; unknown languages fall back to the options.
+const githubActionsTemplate = `name: CI
+
+on:
+  push:
+    branches: [ {{.Options.branch | default "main"}} ]
+  pull_request:
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+{{if eq .ProjectInfo.Language "Go"}}
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+
+      - name: Build
+        run: go build ./...
+
+      - name: Test
+        run: go test ./...
+{{else if or (eq .ProjectInfo.Language "JavaScript") (eq .ProjectInfo.Language "TypeScript")}}
+      - uses: actions/setup-node@v4
+        with:
+          node-version: {{.Options.nodeVersion | default "20"}}
+
+      - name: Install
+        run: {{if eq .ProjectInfo.PackageManager "yarn"}}yarn install --frozen-lockfile{{else if eq .ProjectInfo.PackageManager "pnpm"}}npx pnpm install --frozen-lockfile{{else}}npm ci{{end}}
+{{if index .ProjectInfo.Scripts "build"}}
+      - name: Build
+        run: {{.ProjectInfo.PackageManager | default "npm"}} run build
+{{end}}
+      - name: Test
+        run: {{.ProjectInfo.PackageManager | default "npm"}} test
+{{else if eq .ProjectInfo.Language "Python"}}
+      - uses: actions/setup-python@v5
+        with:
+          python-version: "{{.Options.pythonVersion | default "3.12"}}"
+
+      - name: Install
+        run: |
+          python -m pip install --upgrade pip
+          pip install -r requirements.txt
+
+      - name: Test
+        run: {{if eq .ProjectInfo.TestFramework "pytest"}}pytest{{else}}python -m unittest discover{{end}}
+{{else if eq .ProjectInfo.Language "Rust"}}
+      - name: Build
+        run: cargo build --verbose
+
+      - name: Test
+        run: cargo test --verbose
+{{else}}
+      - name: Build
+        run: {{.Options.build | default "echo \"Add a build command\""}}
+
+      - name: Test
+        run: {{.Options.test | default "echo \"Add a test command\""}}
+{{end}}`
+
+// supportedLicenses lists the SPDX ids the license template can render.
+// Only MIT is rendered in full; Apache-2.0 and GPL-3.0 render the notice
+// that goes in a NOTICE file or source headers next to the official text.
+var supportedLicenses = []string{"MIT", "Apache-2.0", "GPL-3.0"}
+
+// LicenseFilename returns the file a rendered license belongs in: LICENSE
+// for the full MIT text and NOTICE for the other licenses' notices.
+func LicenseFilename(id string) string {
+	if id == "MIT" {
+		return "LICENSE"
+	}
+	return "NOTICE"
+}
+
+// licenseOptions validates the requested SPDX id and fills in the year and
+// author defaults used by the license template.
+func licenseOptions(options map[string]interface{}) error {
+	id, _ := options["license"].(string)
+	if id == "" {
+		id = "MIT"
+	}
+	matched := ""
+	for _, supported := range supportedLicenses {
+		if strings.EqualFold(id, supported) {
+			matched = supported
+			break
+		}
+	}
+	if matched == "" {
+		return fmt.Errorf("unsupported license '%s' (supported: %s)", id, strings.Join(supportedLicenses, ", "))
+	}
+	options["license"] = matched
+
+	if options["year"] == nil || options["year"] == "" {
+		options["year"] = time.Now().Year()
+	}
+	if author, _ := options["author"].(string); author == "" {
+		options["author"] = "The Authors"
+	}
+	return nil
+}
+
+// genericMakefileTemplate is used for languages without a dedicated Makefile;
+// each target runs the command given in the options or explains what to add
+const genericMakefileTemplate = `.PHONY: build test clean run
+
+build:
+	{{.Options.build | default "@echo \"Add your build command here\""}}
+
+test:
+	{{.Options.test | default "@echo \"Add your test command here\""}}
+
+clean:
+	{{.Options.clean | default "@echo \"Add your clean command here\""}}
+
+run:
+	{{.Options.run | default "@echo \"Add your run command here\""}}
+`
+
 // Unique web templates designed to avoid recitation blocks
 const uniqueHTMLTemplate = `<!DOCTYPE html>
 <html lang="en">
@@ -699,6 +1012,28 @@ func (cg *CodeGenerator) GetSuggestedFilename(codeType, name string) string {
 	}
 }
 
+// GetSuggestedConfigFilename returns the conventional filename for a config type
+func (cg *CodeGenerator) GetSuggestedConfigFilename(configType string) string {
+	switch strings.ToLower(configType) {
+	case "makefile":
+		return "Makefile"
+	case "dockerfile":
+		return "Dockerfile"
+	case "gitignore":
+		return ".gitignore"
+	case "readme":
+		return "README.md"
+	case "editorconfig":
+		return ".editorconfig"
+	case "license":
+		return "LICENSE"
+	case "github_actions":
+		return filepath.Join(".github", "workflows", "ci.yml")
+	default:
+		return configType
+	}
+}
+
 // GetSuggestedTestFilename returns a suggested filename for test files
 func (cg *CodeGenerator) GetSuggestedTestFilename(name string) string {
 	switch strings.ToLower(cg.projectInfo.Language) {
@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRustMakefile(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Rust", PackageManager: "cargo"})
+
+	makefile, err := generator.GenerateConfigFile("makefile", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"build:\n\tcargo build\n", "test:\n\tcargo test\n", "clean:\n\tcargo clean\n", "run:\n\tcargo run\n"} {
+		if !strings.Contains(makefile, want) {
+			t.Errorf("Makefile is missing %q:\n%s", want, makefile)
+		}
+	}
+}
+
+func TestGenericMakefile(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Unknown"})
+
+	makefile, err := generator.GenerateConfigFile("makefile", map[string]interface{}{"build": "./build.sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(makefile, "build:\n\t./build.sh\n") {
+		t.Errorf("Makefile does not use the build command:\n%s", makefile)
+	}
+	for _, target := range []string{"test:", "clean:", "run:"} {
+		if !strings.Contains(makefile, target) {
+			t.Errorf("Makefile is missing the %s target:\n%s", target, makefile)
+		}
+	}
+}
+
+func TestMakefileForUnknownLanguageNeedsCommands(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Unknown"})
+
+	makefile, err := generator.GenerateConfigFile("makefile", nil)
+	if err == nil {
+		t.Fatalf("generated a Makefile for an unknown language without commands:\n%s", makefile)
+	}
+	if !strings.Contains(err.Error(), "language is unknown") {
+		t.Errorf("error = %v, want it to name the unknown language", err)
+	}
+}
+
+func TestMakefileForOtherDetectedLanguage(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Java"})
+
+	makefile, err := generator.GenerateConfigFile("makefile", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(makefile) == "" || !strings.Contains(makefile, ".PHONY: build test clean run") {
+		t.Errorf("Java Makefile = %q, want the generic targets", makefile)
+	}
+}
+
+func TestGoReadme(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Go", Framework: "Gin"})
+
+	readme, err := generator.GenerateConfigFile("readme", map[string]interface{}{"name": "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# hello\n", "Built with Go and Gin.", "go mod download", "go build -o hello .", "go test ./..."} {
+		if !strings.Contains(readme, want) {
+			t.Errorf("README is missing %q:\n%s", want, readme)
+		}
+	}
+	if name := generator.GetSuggestedConfigFilename("readme"); name != "README.md" {
+		t.Errorf("suggested filename = %q, want README.md", name)
+	}
+}
+
+func TestNodeReadme(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "JavaScript", PackageManager: "yarn", RootPath: "/work/webapp"})
+
+	readme, err := generator.GenerateConfigFile("readme", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# webapp\n", "yarn install", "yarn run build", "yarn test"} {
+		if !strings.Contains(readme, want) {
+			t.Errorf("README is missing %q:\n%s", want, readme)
+		}
+	}
+	if strings.Contains(readme, "go ") {
+		t.Errorf("Node README has Go commands:\n%s", readme)
+	}
+}
+
+func TestMITLicense(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Go"})
+	options := map[string]interface{}{"license": "mit", "year": "2024", "author": "Ada Lovelace"}
+
+	license, err := generator.GenerateConfigFile("license", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(license, "MIT License\n\nCopyright (c) 2024 Ada Lovelace\n") {
+		t.Errorf("license header = %q", strings.SplitN(license, "\n", 4))
+	}
+	if !strings.Contains(license, "THE SOFTWARE IS PROVIDED \"AS IS\"") {
+		t.Error("MIT license is not the full text")
+	}
+	if id := options["license"].(string); LicenseFilename(id) != "LICENSE" {
+		t.Errorf("MIT goes in %s, want LICENSE", LicenseFilename(id))
+	}
+}
+
+func TestApacheLicenseNotice(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Go"})
+	options := map[string]interface{}{"license": "Apache-2.0", "year": 2023, "author": "Example Corp"}
+
+	notice, err := generator.GenerateConfigFile("license", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Copyright 2023 Example Corp", "Apache License, Version 2.0", "http://www.apache.org/licenses/LICENSE-2.0"} {
+		if !strings.Contains(notice, want) {
+			t.Errorf("notice is missing %q:\n%s", want, notice)
+		}
+	}
+	if strings.Contains(notice, "{{") || strings.Contains(notice, "<no value>") {
+		t.Errorf("notice has unsubstituted fields:\n%s", notice)
+	}
+	if name := LicenseFilename("Apache-2.0"); name != "NOTICE" {
+		t.Errorf("Apache notice goes in %s, want NOTICE", name)
+	}
+}
+
+func TestLicenseDefaults(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{})
+
+	license, err := generator.GenerateConfigFile("license", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(license, "MIT License") || !strings.Contains(license, "The Authors") {
+		t.Errorf("default license = %q", strings.SplitN(license, "\n", 4))
+	}
+}
+
+func TestUnknownLicense(t *testing.T) {
+	generator := NewCodeGenerator(&ProjectInfo{})
+	if _, err := generator.GenerateConfigFile("license", map[string]interface{}{"license": "WTFPL"}); err == nil {
+		t.Error("rendered an unsupported license")
+	}
+}
+
+// workflowRuns returns the run commands of a rendered workflow
+func workflowRuns(t *testing.T, info *ProjectInfo) []string {
+	t.Helper()
+	generator := NewCodeGenerator(info)
+	workflow, err := generator.GenerateConfigFile("github_actions", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(workflow, "on:\n  push:") || !strings.Contains(workflow, "  pull_request:") {
+		t.Errorf("workflow does not run on push and pull requests:\n%s", workflow)
+	}
+	var runs []string
+	for _, line := range strings.Split(workflow, "\n") {
+		if run, ok := strings.CutPrefix(strings.TrimSpace(line), "run: "); ok {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+func TestGoWorkflow(t *testing.T) {
+	runs := workflowRuns(t, &ProjectInfo{Language: "Go"})
+	if want := []string{"go build ./...", "go test ./..."}; strings.Join(runs, "; ") != strings.Join(want, "; ") {
+		t.Errorf("runs = %q, want %q", runs, want)
+	}
+}
+
+func TestNodeWorkflow(t *testing.T) {
+	runs := workflowRuns(t, &ProjectInfo{Language: "JavaScript", PackageManager: "yarn", Scripts: map[string]string{"build": "webpack"}})
+	if want := []string{"yarn install --frozen-lockfile", "yarn run build", "yarn test"}; strings.Join(runs, "; ") != strings.Join(want, "; ") {
+		t.Errorf("runs = %q, want %q", runs, want)
+	}
+
+	runs = workflowRuns(t, &ProjectInfo{Language: "TypeScript", PackageManager: "npm"})
+	if want := []string{"npm ci", "npm test"}; strings.Join(runs, "; ") != strings.Join(want, "; ") {
+		t.Errorf("runs without a build script = %q, want %q", runs, want)
+	}
+}
+
+func TestPythonWorkflow(t *testing.T) {
+	runs := workflowRuns(t, &ProjectInfo{Language: "Python", TestFramework: "pytest"})
+	if len(runs) != 2 || runs[0] != "|" || runs[1] != "pytest" {
+		t.Errorf("runs = %q, want an install block and pytest", runs)
+	}
+
+	generator := NewCodeGenerator(&ProjectInfo{Language: "Python"})
+	if name := generator.GetSuggestedConfigFilename("github_actions"); filepath.ToSlash(name) != ".github/workflows/ci.yml" {
+		t.Errorf("workflow path = %q", name)
+	}
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"console-ai/pkg/commander"
+)
+
+// GitInfo describes the state of a git working tree at the time of the
+// analysis.
+type GitInfo struct {
+	Branch        string `json:"branch"`                   // "HEAD" when detached
+	DefaultBranch string `json:"default_branch,omitempty"` // the branch origin/HEAD points to
+	Dirty         bool   `json:"dirty"`
+	ChangedFiles  int    `json:"changed_files,omitempty"` // modified, staged and untracked files
+	Upstream      string `json:"upstream,omitempty"`
+	Ahead         int    `json:"ahead,omitempty"`
+	Behind        int    `json:"behind,omitempty"`
+	RemoteURL     string `json:"remote_url,omitempty"` // origin, without credentials
+}
+
+// gitCommands is the allowlist used for the analyzer's own git calls
+var gitCommands = []string{"git"}
+
+// analyzeGit fills in the git state when the root is a git working tree.
+// Trees without git, or machines without the git command, get no Git
+// section.
+func (pa *ProjectAnalyzer) analyzeGit(ctx context.Context, info *ProjectInfo) {
+	if !pa.fileExists(".git") || ctx.Err() != nil {
+		return
+	}
+
+	branch, err := pa.git("symbolic-ref --short HEAD")
+	if err != nil {
+		// Detached HEAD, or git isn't usable here
+		if _, err := pa.git("rev-parse --verify HEAD"); err != nil {
+			return
+		}
+		branch = "HEAD"
+	}
+	gitInfo := &GitInfo{Branch: branch}
+
+	if status, err := pa.git("status --porcelain"); err == nil && status != "" {
+		gitInfo.Dirty = true
+		gitInfo.ChangedFiles = len(strings.Split(status, "\n"))
+	}
+	if upstream, err := pa.git("rev-parse --abbrev-ref --symbolic-full-name @{upstream}"); err == nil {
+		gitInfo.Upstream = upstream
+		if counts, err := pa.git("rev-list --left-right --count HEAD...@{upstream}"); err == nil {
+			if fields := strings.Fields(counts); len(fields) == 2 {
+				gitInfo.Ahead, _ = strconv.Atoi(fields[0])
+				gitInfo.Behind, _ = strconv.Atoi(fields[1])
+			}
+		}
+	}
+	if remote, err := pa.git("remote get-url origin"); err == nil {
+		gitInfo.RemoteURL = redactRemoteURL(remote)
+	}
+	if head, err := pa.git("symbolic-ref --short refs/remotes/origin/HEAD"); err == nil {
+		gitInfo.DefaultBranch = strings.TrimPrefix(head, "origin/")
+	}
+
+	info.Git = gitInfo
+}
+
+// git runs a git subcommand in the project root and returns its trimmed
+// standard output
+func (pa *ProjectAnalyzer) git(args string) (string, error) {
+	output, err := commander.ExecuteCommandInDir("git "+args, gitCommands, pa.rootPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output.Stdout), nil
+}
+
+// redactRemoteURL removes user names and tokens from an https remote, which
+// would otherwise end up in the prompt and the history file
+func redactRemoteURL(remote string) string {
+	parsed, err := url.Parse(remote)
+	if err != nil || parsed.Scheme == "" || parsed.User == nil {
+		return remote
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// summary describes the git state in one line
+func (g *GitInfo) summary() string {
+	parts := []string{fmt.Sprintf("branch %s", g.Branch)}
+	if g.DefaultBranch != "" && g.DefaultBranch != g.Branch {
+		parts[0] += fmt.Sprintf(" (default %s)", g.DefaultBranch)
+	}
+	if g.Dirty {
+		parts = append(parts, fmt.Sprintf("%d uncommitted changes", g.ChangedFiles))
+	} else {
+		parts = append(parts, "clean")
+	}
+	if g.Upstream != "" {
+		parts = append(parts, fmt.Sprintf("%d ahead, %d behind %s", g.Ahead, g.Behind, g.Upstream))
+	}
+	if g.RemoteURL != "" {
+		parts = append(parts, "remote "+g.RemoteURL)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"strings"
+)
+
+// gitignoreMergeHeader marks the block of entries appended to an existing file.
+const gitignoreMergeHeader = "# Added by Console Buddy"
+
+// MergeGitignore appends the entries of generated that are missing from
+// existing, keeping the user's file and its custom entries untouched. It
+// returns the merged content and the entries that were added.
+func MergeGitignore(existing, generated string) (string, []string) {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(existing, "\n") {
+		if entry := gitignoreEntry(line); entry != "" {
+			seen[entry] = true
+		}
+	}
+
+	var added []string
+	for _, line := range strings.Split(generated, "\n") {
+		entry := gitignoreEntry(line)
+		if entry == "" || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		added = append(added, entry)
+	}
+	if len(added) == 0 {
+		return existing, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(existing)
+	if existing != "" {
+		if !strings.HasSuffix(existing, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(gitignoreMergeHeader + "\n")
+	for _, entry := range added {
+		b.WriteString(entry + "\n")
+	}
+	return b.String(), added
+}
+
+// gitignoreEntry returns the pattern on a gitignore line, or "" for blank
+// lines and comments.
+func gitignoreEntry(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	return line
+}
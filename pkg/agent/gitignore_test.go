@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeGitignore(t *testing.T) {
+	existing := "# my entries\n.env\nbin/\nscratch/\n"
+	generated := "# Binaries\nbin/\n*.exe\n\n# Env\n.env\n*.test\n*.exe\n"
+
+	merged, added := MergeGitignore(existing, generated)
+
+	if want := []string{"*.exe", "*.test"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %q, want %q", added, want)
+	}
+	if !strings.HasPrefix(merged, existing) {
+		t.Errorf("merged file does not keep the existing entries:\n%s", merged)
+	}
+	if want := existing + "\n" + gitignoreMergeHeader + "\n*.exe\n*.test\n"; merged != want {
+		t.Errorf("merged = %q, want %q", merged, want)
+	}
+}
+
+func TestMergeGitignoreNothingMissing(t *testing.T) {
+	existing := "node_modules/\n  dist/  \n"
+	merged, added := MergeGitignore(existing, "node_modules/\ndist/\n")
+	if merged != existing || len(added) != 0 {
+		t.Errorf("merge changed a complete file: %q, added %q", merged, added)
+	}
+}
+
+func TestMergeGitignoreWithoutTrailingNewline(t *testing.T) {
+	merged, _ := MergeGitignore("custom.log", "*.log\n")
+	if want := "custom.log\n\n" + gitignoreMergeHeader + "\n*.log\n"; merged != want {
+		t.Errorf("merged = %q, want %q", merged, want)
+	}
+}
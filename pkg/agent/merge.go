@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Merge returns a copy of info combined with the analysis of another path,
+// typically a subdirectory. Dependencies are de-duplicated, files of other
+// are listed relative to info's root, and fields info leaves empty are taken
+// from other. Neither input is modified.
+func (info *ProjectInfo) Merge(other *ProjectInfo) *ProjectInfo {
+	merged := *info
+	merged.Dependencies = unionStrings(info.Dependencies, other.Dependencies)
+
+	prefix := ""
+	if rel, err := filepath.Rel(info.RootPath, other.RootPath); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		prefix = rel
+	}
+	otherFiles := make([]string, 0, len(other.Files))
+	for _, file := range other.Files {
+		otherFiles = append(otherFiles, filepath.Join(prefix, file))
+	}
+	merged.Files = unionStrings(info.Files, otherFiles)
+
+	merged.Scripts = make(map[string]string, len(info.Scripts)+len(other.Scripts))
+	for name, script := range other.Scripts {
+		merged.Scripts[name] = script
+	}
+	for name, script := range info.Scripts {
+		merged.Scripts[name] = script
+	}
+
+	if merged.Language == "" || merged.Language == "Unknown" {
+		merged.Language = other.Language
+	}
+	if merged.Framework == "" {
+		merged.Framework = other.Framework
+	}
+	if merged.PackageManager == "" {
+		merged.PackageManager = other.PackageManager
+	}
+	if merged.BuildTool == "" {
+		merged.BuildTool = other.BuildTool
+	}
+	if merged.TestFramework == "" {
+		merged.TestFramework = other.TestFramework
+	}
+	if merged.Git == nil {
+		merged.Git = other.Git
+	}
+	merged.Truncated = info.Truncated || other.Truncated
+	merged.SubProjects = append(append([]*ProjectInfo(nil), info.SubProjects...), other.SubProjects...)
+
+	return &merged
+}
+
+// unionStrings returns the items of a followed by those of b not in a,
+// without duplicates
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var union []string
+	for _, list := range [][]string{a, b} {
+		for _, item := range list {
+			if !seen[item] {
+				seen[item] = true
+				union = append(union, item)
+			}
+		}
+	}
+	return union
+}
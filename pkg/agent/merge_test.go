@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeUnionsDependencies(t *testing.T) {
+	root := filepath.FromSlash("/src/app")
+	info := &ProjectInfo{
+		RootPath:     root,
+		Language:     "Go",
+		Dependencies: []string{"github.com/gin-gonic/gin", "github.com/stretchr/testify"},
+		Files:        []string{"main.go"},
+		Scripts:      map[string]string{"build": "go build"},
+	}
+	web := &ProjectInfo{
+		RootPath:       filepath.Join(root, "web"),
+		Language:       "JavaScript",
+		Framework:      "React",
+		PackageManager: "npm",
+		Dependencies:   []string{"react", "github.com/gin-gonic/gin", "react"},
+		Files:          []string{"package.json", filepath.Join("src", "App.jsx")},
+		Scripts:        map[string]string{"build": "vite build", "test": "jest"},
+	}
+
+	merged := info.Merge(web)
+	if want := []string{"github.com/gin-gonic/gin", "github.com/stretchr/testify", "react"}; !reflect.DeepEqual(merged.Dependencies, want) {
+		t.Errorf("Dependencies = %q, want %q", merged.Dependencies, want)
+	}
+	if want := []string{"main.go", filepath.Join("web", "package.json"), filepath.Join("web", "src", "App.jsx")}; !reflect.DeepEqual(merged.Files, want) {
+		t.Errorf("Files = %q, want %q", merged.Files, want)
+	}
+	if merged.Language != "Go" || merged.Framework != "React" || merged.PackageManager != "npm" {
+		t.Errorf("merged = %+v, want Go kept and empty fields filled in", merged)
+	}
+	if merged.Scripts["build"] != "go build" || merged.Scripts["test"] != "jest" {
+		t.Errorf("Scripts = %v", merged.Scripts)
+	}
+
+	if len(info.Dependencies) != 2 || len(info.Files) != 1 || info.Framework != "" || len(info.Scripts) != 1 {
+		t.Error("Merge modified its receiver")
+	}
+}
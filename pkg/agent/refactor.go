@@ -0,0 +1,611 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MoveResult describes the outcome of moving a Go file between packages
+type MoveResult struct {
+	Source       string   `json:"source"`
+	Destination  string   `json:"destination"`
+	OldPackage   string   `json:"old_package"`
+	NewPackage   string   `json:"new_package"`
+	UpdatedFiles []string `json:"updated_files,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// sourceEdit replaces the byte range [start, end) of a file with text
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// MoveGoFile moves a Go source file into another package directory of the module
+// rooted at rootPath. The package clause of the moved file is rewritten and files
+// referencing its exported symbols are updated to import the new package.
+// dst may be a directory or a full file path ending in .go.
+func MoveGoFile(rootPath, src, dst string) (*MoveResult, error) {
+	modulePath, err := readModulePath(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	srcPath := absPath(rootPath, src)
+	dstPath := absPath(rootPath, dst)
+	if !strings.HasSuffix(dstPath, ".go") {
+		dstPath = filepath.Join(dstPath, filepath.Base(srcPath))
+	}
+	if _, err := os.Stat(dstPath); err == nil {
+		return nil, fmt.Errorf("destination %s already exists", dstPath)
+	}
+
+	oldDir := filepath.Dir(srcPath)
+	newDir := filepath.Dir(dstPath)
+
+	fset := token.NewFileSet()
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parser.ParseFile(fset, srcPath, content, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", src, err)
+	}
+
+	oldPkg := file.Name.Name
+	newPkg, err := packageNameForDir(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveResult{
+		Source:      relPath(rootPath, srcPath),
+		Destination: relPath(rootPath, dstPath),
+		OldPackage:  oldPkg,
+		NewPackage:  newPkg,
+	}
+
+	// Moving within the same directory is a plain rename
+	if oldDir == newDir {
+		if err := os.Rename(srcPath, dstPath); err != nil {
+			return nil, err
+		}
+		result.NewPackage = oldPkg
+		return result, nil
+	}
+
+	oldImport, err := importPathForDir(modulePath, rootPath, oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newImport, err := importPathForDir(modulePath, rootPath, newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := topLevelNames(file)
+	siblings, remaining := packageSiblings(oldDir, oldPkg, srcPath)
+
+	// Rewrite the moved file itself
+	movedEdits := []sourceEdit{{
+		start: fset.Position(file.Name.Pos()).Offset,
+		end:   fset.Position(file.Name.End()).Offset,
+		text:  newPkg,
+	}}
+	needsOldImport := false
+	for _, ident := range unresolvedIdents(file) {
+		if !remaining[ident.Name] {
+			continue
+		}
+		if !ast.IsExported(ident.Name) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s references unexported %s from package %s", result.Destination, ident.Name, oldPkg))
+			continue
+		}
+		offset := fset.Position(ident.Pos()).Offset
+		movedEdits = append(movedEdits, sourceEdit{start: offset, end: offset, text: oldPkg + "."})
+		needsOldImport = true
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
+			if recv := receiverTypeName(fn); remaining[recv] {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("method %s is declared on %s.%s and cannot leave its package", fn.Name.Name, oldPkg, recv))
+			}
+		}
+	}
+
+	newContent, err := rewriteSource(content, movedEdits, func(f *ast.File) {
+		if needsOldImport {
+			addImport(f, oldImport, oldPkg)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewrite %s: %w", src, err)
+	}
+
+	if err := os.MkdirAll(newDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(dstPath, newContent, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(srcPath); err != nil {
+		return nil, err
+	}
+
+	// Qualify references from files left behind in the old package
+	oldPackageImportsNew := false
+	for _, sibling := range siblings {
+		updated, warnings, err := qualifySiblingReferences(sibling, moved, newPkg, newImport)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to update %s: %v", relPath(rootPath, sibling), err))
+			continue
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+		if updated {
+			oldPackageImportsNew = true
+			result.UpdatedFiles = append(result.UpdatedFiles, relPath(rootPath, sibling))
+		}
+	}
+	if needsOldImport && oldPackageImportsNew {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("packages %s and %s now import each other, creating an import cycle", oldPkg, newPkg))
+	}
+
+	// Update importers of the old package across the module
+	isSibling := make(map[string]bool)
+	for _, sibling := range siblings {
+		isSibling[sibling] = true
+	}
+	err = filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != rootPath && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || path == dstPath || isSibling[path] {
+			return nil
+		}
+
+		updated, err := updateImporter(path, oldImport, oldPkg, newImport, newPkg, newDir, moved)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to update %s: %v", relPath(rootPath, path), err))
+			return nil
+		}
+		if updated {
+			result.UpdatedFiles = append(result.UpdatedFiles, relPath(rootPath, path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// updateImporter rewrites selector references like oldpkg.Symbol in a file that
+// imports the old package so they point at the new package.
+func updateImporter(path, oldImport, oldPkg, newImport, newPkg, newDir string, moved map[string]bool) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return false, err
+	}
+
+	alias := importAlias(file, oldImport, oldPkg)
+	if alias == "" || alias == "_" || alias == "." {
+		return false, nil
+	}
+
+	samePackage := filepath.Dir(path) == newDir && file.Name.Name == newPkg
+
+	var edits []sourceEdit
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok || x.Name != alias || x.Obj != nil || !moved[sel.Sel.Name] {
+			return true
+		}
+		edit := sourceEdit{start: fset.Position(x.Pos()).Offset}
+		if samePackage {
+			// The symbol now lives in this package and needs no qualifier
+			edit.end = fset.Position(sel.Sel.Pos()).Offset
+		} else {
+			edit.end = fset.Position(x.End()).Offset
+			edit.text = newPkg
+		}
+		edits = append(edits, edit)
+		return true
+	})
+
+	if len(edits) == 0 {
+		return false, nil
+	}
+
+	newContent, err := rewriteSource(content, edits, func(f *ast.File) {
+		if !samePackage {
+			addImport(f, newImport, newPkg)
+		}
+		if !usesIdent(f, alias) {
+			removeImport(f, oldImport)
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, os.WriteFile(path, newContent, 0644)
+}
+
+// qualifySiblingReferences prefixes unqualified uses of moved exported symbols in a
+// file that stayed in the old package.
+func qualifySiblingReferences(path string, moved map[string]bool, newPkg, newImport string) (bool, []string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var edits []sourceEdit
+	var warnings []string
+	for _, ident := range unresolvedIdents(file) {
+		if !moved[ident.Name] {
+			continue
+		}
+		if !ast.IsExported(ident.Name) {
+			warnings = append(warnings, fmt.Sprintf("%s references unexported %s which moved to package %s", filepath.Base(path), ident.Name, newPkg))
+			continue
+		}
+		offset := fset.Position(ident.Pos()).Offset
+		edits = append(edits, sourceEdit{start: offset, end: offset, text: newPkg + "."})
+	}
+
+	if len(edits) == 0 {
+		return false, warnings, nil
+	}
+
+	newContent, err := rewriteSource(content, edits, func(f *ast.File) {
+		addImport(f, newImport, newPkg)
+	})
+	if err != nil {
+		return false, warnings, err
+	}
+
+	return true, warnings, os.WriteFile(path, newContent, 0644)
+}
+
+// rewriteSource applies text edits, then lets fixImports adjust the import
+// declarations of the re-parsed file before formatting it.
+func rewriteSource(content []byte, edits []sourceEdit, fixImports func(*ast.File)) ([]byte, error) {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+
+	out := append([]byte(nil), content...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", out, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	fixImports(file)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addImport adds an import of path to the file unless it is already present
+func addImport(file *ast.File, path, name string) {
+	for _, spec := range file.Imports {
+		if importPathOf(spec) == path {
+			return
+		}
+	}
+
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+	if name != filepath.Base(path) {
+		spec.Name = ast.NewIdent(name)
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if !gen.Lparen.IsValid() {
+			// Turn a single-line import into a grouped one
+			gen.Lparen = gen.TokPos
+			gen.Rparen = gen.End()
+		}
+		gen.Specs = append(gen.Specs, spec)
+		file.Imports = append(file.Imports, spec)
+		return
+	}
+
+	// Position the new declaration right after the package clause so that
+	// comments attached to the first declaration stay in place
+	spec.Path.ValuePos = file.Name.End()
+	gen := &ast.GenDecl{TokPos: file.Name.End(), Tok: token.IMPORT, Specs: []ast.Spec{spec}}
+	file.Decls = append([]ast.Decl{gen}, file.Decls...)
+	file.Imports = append(file.Imports, spec)
+}
+
+// removeImport deletes the import of path from the file
+func removeImport(file *ast.File, path string) {
+	for i := 0; i < len(file.Decls); i++ {
+		gen, ok := file.Decls[i].(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var specs []ast.Spec
+		for _, spec := range gen.Specs {
+			if importPathOf(spec.(*ast.ImportSpec)) != path {
+				specs = append(specs, spec)
+			}
+		}
+		gen.Specs = specs
+		if len(gen.Specs) == 0 {
+			file.Decls = append(file.Decls[:i], file.Decls[i+1:]...)
+			i--
+		}
+	}
+
+	var imports []*ast.ImportSpec
+	for _, spec := range file.Imports {
+		if importPathOf(spec) != path {
+			imports = append(imports, spec)
+		}
+	}
+	file.Imports = imports
+}
+
+// usesIdent reports whether name is used as a package qualifier in the file
+func usesIdent(file *ast.File, name string) bool {
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == name && x.Obj == nil {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// unresolvedIdents returns identifiers that refer to package-level declarations
+// outside the file, excluding selectors, field names, and composite literal keys.
+func unresolvedIdents(file *ast.File) []*ast.Ident {
+	skip := map[*ast.Ident]bool{file.Name: true}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			skip[node.Sel] = true
+		case *ast.KeyValueExpr:
+			if key, ok := node.Key.(*ast.Ident); ok {
+				skip[key] = true
+			}
+		case *ast.FuncDecl:
+			skip[node.Name] = true
+		case *ast.Field:
+			for _, name := range node.Names {
+				skip[name] = true
+			}
+		case *ast.ImportSpec:
+			if node.Name != nil {
+				skip[node.Name] = true
+			}
+		case *ast.BranchStmt:
+			if node.Label != nil {
+				skip[node.Label] = true
+			}
+		case *ast.LabeledStmt:
+			skip[node.Label] = true
+		}
+		return true
+	})
+
+	var idents []*ast.Ident
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Obj == nil && !skip[ident] {
+			idents = append(idents, ident)
+		}
+		return true
+	})
+	return idents
+}
+
+// topLevelNames returns the names declared at package level in the file
+func topLevelNames(file *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				names[d.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names[s.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						names[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// packageSiblings returns the other files of a package and the names they declare
+func packageSiblings(dir, pkg, exclude string) ([]string, map[string]bool) {
+	names := make(map[string]bool)
+	var files []string
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, names
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || path == exclude {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil || file.Name.Name != pkg {
+			continue
+		}
+		files = append(files, path)
+		for name := range topLevelNames(file) {
+			names[name] = true
+		}
+	}
+	return files, names
+}
+
+// packageNameForDir returns the package name used by existing Go files in dir,
+// or a name derived from the directory when it has none.
+func packageNameForDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, entry.Name()), nil, parser.PackageClauseOnly)
+		if err == nil {
+			return file.Name.Name, nil
+		}
+	}
+
+	name := strings.ToLower(filepath.Base(dir))
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+	if name == "" {
+		return "", fmt.Errorf("cannot derive a package name from %s", dir)
+	}
+	return name, nil
+}
+
+// readModulePath reads the module path from go.mod in rootPath
+func readModulePath(rootPath string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(rootPath, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("no module directive found in go.mod")
+}
+
+// importPathForDir returns the import path of a directory inside the module
+func importPathForDir(modulePath, rootPath, dir string) (string, error) {
+	rel, err := filepath.Rel(rootPath, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the module root", dir)
+	}
+	if rel == "." {
+		return modulePath, nil
+	}
+	return modulePath + "/" + filepath.ToSlash(rel), nil
+}
+
+// importAlias returns the name a file uses for the given import, or "" if not imported
+func importAlias(file *ast.File, path, pkgName string) string {
+	for _, spec := range file.Imports {
+		if importPathOf(spec) != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return pkgName
+	}
+	return ""
+}
+
+func importPathOf(spec *ast.ImportSpec) string {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+func receiverTypeName(fn *ast.FuncDecl) string {
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			return ident.Name
+		}
+	case *ast.IndexListExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+func absPath(rootPath, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(rootPath, path)
+	}
+	return filepath.Clean(path)
+}
+
+func relPath(rootPath, path string) string {
+	if rel, err := filepath.Rel(rootPath, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestMoveGoFileUpdatesReferences(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"util/strings.go": `package util
+
+import "strings"
+
+// Shout upper-cases s
+func Shout(s string) string {
+	return strings.ToUpper(s) + Suffix
+}
+`,
+		"util/suffix.go": `package util
+
+const Suffix = "!"
+
+func Greeting() string {
+	return Shout("hello")
+}
+`,
+		"text/text.go": "package text\n",
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/app/util"
+)
+
+func main() {
+	fmt.Println(util.Shout("hi"), util.Greeting())
+}
+`,
+	})
+
+	result, err := MoveGoFile(root, "util/strings.go", "text")
+	if err != nil {
+		t.Fatalf("MoveGoFile: %v", err)
+	}
+	if result.OldPackage != "util" || result.NewPackage != "text" {
+		t.Errorf("packages = %s -> %s, want util -> text", result.OldPackage, result.NewPackage)
+	}
+	if _, err := os.Stat(filepath.Join(root, "util", "strings.go")); !os.IsNotExist(err) {
+		t.Error("source file still exists")
+	}
+
+	moved := readFile(t, filepath.Join(root, "text", "strings.go"))
+	for _, want := range []string{"package text", `"example.com/app/util"`, "util.Suffix"} {
+		if !strings.Contains(moved, want) {
+			t.Errorf("moved file is missing %q:\n%s", want, moved)
+		}
+	}
+
+	sibling := readFile(t, filepath.Join(root, "util", "suffix.go"))
+	for _, want := range []string{`"example.com/app/text"`, `text.Shout("hello")`} {
+		if !strings.Contains(sibling, want) {
+			t.Errorf("sibling file is missing %q:\n%s", want, sibling)
+		}
+	}
+
+	main := readFile(t, filepath.Join(root, "main.go"))
+	for _, want := range []string{`"example.com/app/text"`, `"example.com/app/util"`, `text.Shout("hi")`, "util.Greeting()"} {
+		if !strings.Contains(main, want) {
+			t.Errorf("main.go is missing %q:\n%s", want, main)
+		}
+	}
+	if strings.Contains(main, "util.Shout") {
+		t.Errorf("main.go still references util.Shout:\n%s", main)
+	}
+}
+
+func TestMoveGoFileRemovesUnusedImport(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.21\n",
+		"old/add.go":  "package old\n\nfunc Add(a, b int) int { return a + b }\n",
+		"old/keep.go": "package old\n",
+		"new/new.go":  "package new\n",
+		"cmd/main.go": "package main\n\nimport \"example.com/app/old\"\n\nfunc main() { _ = old.Add(1, 2) }\n",
+	})
+
+	if _, err := MoveGoFile(root, "old/add.go", "new/add.go"); err != nil {
+		t.Fatalf("MoveGoFile: %v", err)
+	}
+
+	main := readFile(t, filepath.Join(root, "cmd", "main.go"))
+	if strings.Contains(main, `"example.com/app/old"`) {
+		t.Errorf("unused import of the old package kept:\n%s", main)
+	}
+	if !strings.Contains(main, "new.Add(1, 2)") || !strings.Contains(main, `"example.com/app/new"`) {
+		t.Errorf("main.go not updated:\n%s", main)
+	}
+}
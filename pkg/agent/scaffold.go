@@ -0,0 +1,321 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// GeneratedFile is a file produced by the generator, with a path relative
+// to the project directory
+type GeneratedFile struct {
+	Path    string
+	Content string
+}
+
+// ScaffoldOptions describes a new project to scaffold
+type ScaffoldOptions struct {
+	Language    string // go, javascript, typescript, python or rust
+	ProjectType string // cli, library or web
+	Name        string // project name, also used for binaries
+	Module      string // Go module path, defaults to Name
+	Dockerfile  bool   // also generate a Dockerfile
+}
+
+// scaffoldLanguages maps the accepted language names to ProjectInfo settings
+var scaffoldLanguages = map[string]ProjectInfo{
+	"go":         {Language: "Go", PackageManager: "go", BuildTool: "go"},
+	"javascript": {Language: "JavaScript", PackageManager: "npm"},
+	"typescript": {Language: "TypeScript", PackageManager: "npm"},
+	"python":     {Language: "Python", PackageManager: "pip", TestFramework: "pytest"},
+	"rust":       {Language: "Rust", PackageManager: "cargo", BuildTool: "cargo"},
+}
+
+// Scaffold generates the standard file set for a new project: the language's
+// manifest and entry point plus a Makefile, .gitignore, README and optionally
+// a Dockerfile from the config templates.
+func Scaffold(opts ScaffoldOptions) ([]GeneratedFile, error) {
+	language := strings.ToLower(opts.Language)
+	base, ok := scaffoldLanguages[language]
+	if !ok {
+		names := make([]string, 0, len(scaffoldLanguages))
+		for name := range scaffoldLanguages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("scaffolding not supported for language '%s'; supported: %s", opts.Language, strings.Join(names, ", "))
+	}
+
+	opts.ProjectType = strings.ToLower(opts.ProjectType)
+	if opts.ProjectType == "" {
+		opts.ProjectType = "cli"
+	}
+	if opts.ProjectType != "cli" && opts.ProjectType != "library" && opts.ProjectType != "web" {
+		return nil, fmt.Errorf("unknown project type '%s'; use cli, library or web", opts.ProjectType)
+	}
+	if opts.Name == "" {
+		opts.Name = "app"
+	}
+	if opts.Module == "" {
+		opts.Module = opts.Name
+	}
+
+	opts.Language = language
+
+	info := base
+	generator := NewCodeGenerator(&info)
+
+	data := map[string]string{
+		"Name":        opts.Name,
+		"Module":      opts.Module,
+		"Language":    opts.Language,
+		"ProjectType": opts.ProjectType,
+		"Package":     identifier(opts.Name),
+	}
+
+	var files []GeneratedFile
+	for _, file := range scaffoldSources[language] {
+		if !file.forType(opts.ProjectType) {
+			continue
+		}
+		filePath, err := renderScaffold(file.path, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render path %s: %w", file.path, err)
+		}
+		content, err := renderScaffold(file.template, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", filePath, err)
+		}
+		files = append(files, GeneratedFile{Path: filePath, Content: content})
+	}
+
+	configs := []string{"makefile", "gitignore", "readme"}
+	if opts.Dockerfile {
+		configs = append(configs, "dockerfile")
+	}
+	for _, configType := range configs {
+		content, err := generator.GenerateConfigFile(configType, scaffoldConfigOptions(language, opts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", generator.GetSuggestedConfigFilename(configType), err)
+		}
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		files = append(files, GeneratedFile{Path: generator.GetSuggestedConfigFilename(configType), Content: content})
+	}
+
+	return files, nil
+}
+
+// scaffoldConfigOptions fills the config template options for a new project
+func scaffoldConfigOptions(language string, opts ScaffoldOptions) map[string]interface{} {
+	options := map[string]interface{}{"name": opts.Name}
+	switch language {
+	case "go":
+		options["baseImage"] = "golang:1.22-alpine"
+		options["buildCommand"] = "go build -o /app/" + opts.Name + " ."
+		options["startCommand"] = "/app/" + opts.Name
+	case "javascript", "typescript":
+		options["baseImage"] = "node:20-alpine"
+		options["installCommands"] = []string{"npm install"}
+		options["startCommand"] = "npm start"
+		if language == "typescript" {
+			options["buildCommand"] = "npm run build"
+		}
+	case "python":
+		options["baseImage"] = "python:3.12-slim"
+		options["installCommands"] = []string{"pip install -r requirements.txt"}
+		options["startCommand"] = "python main.py"
+	case "rust":
+		options["baseImage"] = "rust:1-slim"
+		options["buildCommand"] = "cargo build --release"
+		options["startCommand"] = "/app/target/release/" + opts.Name
+	}
+	if opts.ProjectType == "web" {
+		options["port"] = "8080"
+	}
+	return options
+}
+
+// identifier turns a project name into a Go or Python package name
+func identifier(name string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9' && builder.Len() > 0:
+			builder.WriteRune(r)
+		case r == '-' || r == '_' || r == ' ' || r == '.':
+			if builder.Len() > 0 {
+				builder.WriteRune('_')
+			}
+		}
+	}
+	if builder.Len() == 0 {
+		return "app"
+	}
+	return builder.String()
+}
+
+// renderScaffold executes a scaffold template with the project data
+func renderScaffold(tmpl string, data map[string]string) (string, error) {
+	t, err := template.New("scaffold").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	if err := t.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// scaffoldSource is a source file template for one or more project types
+type scaffoldSource struct {
+	path     string
+	types    []string // project types the file belongs to; empty means all
+	template string
+}
+
+// forType reports whether the file is part of the given project type
+func (s scaffoldSource) forType(projectType string) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	for _, t := range s.types {
+		if t == projectType {
+			return true
+		}
+	}
+	return false
+}
+
+// scaffoldSources lists the source files generated for each language
+var scaffoldSources = map[string][]scaffoldSource{
+	"go": {
+		{path: "go.mod", template: "module {{.Module}}\n\ngo 1.22\n"},
+		{path: "main.go", types: []string{"cli"}, template: goCLIMain},
+		{path: "main.go", types: []string{"web"}, template: goWebMain},
+		{path: "{{.Package}}.go", types: []string{"library"}, template: goLibrary},
+	},
+	"javascript": {
+		{path: "package.json", template: jsPackageJSON},
+		{path: "index.js", template: "{{if eq .ProjectType \"web\"}}const http = require('http');\n\nconst port = process.env.PORT || 8080;\n\nhttp.createServer((req, res) => {\n  res.end('Hello from {{.Name}}\\n');\n}).listen(port, () => console.log(`Listening on :${port}`));\n{{else if eq .ProjectType \"library\"}}function hello(name) {\n  return `Hello, ${name}!`;\n}\n\nmodule.exports = { hello };\n{{else}}console.log('Hello from {{.Name}}');\n{{end}}"},
+	},
+	"typescript": {
+		{path: "package.json", template: jsPackageJSON},
+		{path: "tsconfig.json", template: "{\n  \"compilerOptions\": {\n    \"target\": \"ES2020\",\n    \"module\": \"commonjs\",\n    \"outDir\": \"dist\",\n    \"strict\": true,\n    \"esModuleInterop\": true\n  },\n  \"include\": [\"src\"]\n}\n"},
+		{path: "src/index.ts", template: "{{if eq .ProjectType \"library\"}}export function hello(name: string): string {\n  return `Hello, ${name}!`;\n}\n{{else if eq .ProjectType \"web\"}}import http from 'http';\n\nconst port = Number(process.env.PORT) || 8080;\n\nhttp.createServer((req, res) => {\n  res.end('Hello from {{.Name}}\\n');\n}).listen(port, () => console.log(`Listening on :${port}`));\n{{else}}console.log('Hello from {{.Name}}');\n{{end}}"},
+	},
+	"python": {
+		{path: "requirements.txt", template: "{{if eq .ProjectType \"web\"}}flask\n{{end}}pytest\n"},
+		{path: "main.py", types: []string{"cli", "web"}, template: pythonMain},
+		{path: "{{.Package}}/__init__.py", types: []string{"library"}, template: "def hello(name):\n    return f\"Hello, {name}!\"\n"},
+	},
+	"rust": {
+		{path: "Cargo.toml", template: "[package]\nname = \"{{.Name}}\"\nversion = \"0.1.0\"\nedition = \"2021\"\n\n[dependencies]\n"},
+		{path: "src/main.rs", types: []string{"cli", "web"}, template: "fn main() {\n    println!(\"Hello from {{.Name}}\");\n}\n"},
+		{path: "src/lib.rs", types: []string{"library"}, template: "pub fn hello(name: &str) -> String {\n    format!(\"Hello, {name}!\")\n}\n"},
+	},
+}
+
+const goCLIMain = `package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+func main() {
+	name := flag.String("name", "world", "Who to greet")
+	flag.Parse()
+
+	fmt.Printf("Hello, %s!\n", *name)
+}
+`
+
+const goWebMain = `package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+)
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Hello from {{.Name}}")
+	})
+
+	log.Printf("Listening on :%s", port)
+	log.Fatal(http.ListenAndServe(":"+port, nil))
+}
+`
+
+const goLibrary = `// Package {{.Package}} is a new Go library.
+package {{.Package}}
+
+// Hello returns a greeting for name.
+func Hello(name string) string {
+	return "Hello, " + name + "!"
+}
+`
+
+const jsPackageJSON = `{
+  "name": "{{.Name}}",
+  "version": "0.1.0",
+  "private": true,
+  "main": "{{if eq .Language "typescript"}}dist/index.js{{else}}index.js{{end}}",
+  "scripts": {
+{{- if eq .Language "typescript"}}
+    "build": "tsc",
+    "start": "node dist/index.js",
+    "dev": "tsc --watch",
+{{- else}}
+    "build": "echo \"Nothing to build\"",
+    "start": "node index.js",
+    "dev": "node index.js",
+{{- end}}
+    "test": "jest"
+  },
+  "devDependencies": {
+{{- if eq .Language "typescript"}}
+    "typescript": "^5.0.0",
+    "@types/node": "^20.0.0",
+{{- end}}
+    "jest": "^29.0.0"
+  }
+}
+`
+
+const pythonMain = `{{if eq .ProjectType "web"}}from flask import Flask
+
+app = Flask(__name__)
+
+
+@app.route("/")
+def index():
+    return "Hello from {{.Name}}"
+
+
+if __name__ == "__main__":
+    app.run(port=8080)
+{{else}}import argparse
+
+
+def main():
+    parser = argparse.ArgumentParser(description="{{.Name}}")
+    parser.add_argument("--name", default="world", help="Who to greet")
+    args = parser.parse_args()
+    print(f"Hello, {args.name}!")
+
+
+if __name__ == "__main__":
+    main()
+{{end}}`
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+// scaffoldPaths returns the generated files keyed by path
+func scaffoldPaths(t *testing.T, opts ScaffoldOptions) map[string]string {
+	t.Helper()
+	files, err := Scaffold(opts)
+	if err != nil {
+		t.Fatalf("Scaffold: %v", err)
+	}
+	paths := make(map[string]string, len(files))
+	for _, file := range files {
+		paths[file.Path] = file.Content
+	}
+	return paths
+}
+
+func TestScaffoldGoCLI(t *testing.T) {
+	files := scaffoldPaths(t, ScaffoldOptions{Language: "go", ProjectType: "cli", Name: "hello", Module: "example.com/hello"})
+
+	for _, path := range []string{"go.mod", "main.go", "Makefile", ".gitignore", "README.md"} {
+		if strings.TrimSpace(files[path]) == "" {
+			t.Errorf("%s is missing or empty", path)
+		}
+	}
+	if _, ok := files["Dockerfile"]; ok {
+		t.Error("Dockerfile generated without being requested")
+	}
+	if !strings.HasPrefix(files["go.mod"], "module example.com/hello\n") {
+		t.Errorf("go.mod = %q", files["go.mod"])
+	}
+	if !strings.Contains(files["main.go"], "package main") {
+		t.Errorf("main.go is not a main package:\n%s", files["main.go"])
+	}
+	if !strings.Contains(files["Makefile"], "go build") {
+		t.Errorf("Makefile has no go build:\n%s", files["Makefile"])
+	}
+}
+
+func TestScaffoldGoLibraryWithDockerfile(t *testing.T) {
+	files := scaffoldPaths(t, ScaffoldOptions{Language: "Go", ProjectType: "library", Name: "my-lib", Dockerfile: true})
+
+	if _, ok := files["main.go"]; ok {
+		t.Error("library has a main.go")
+	}
+	if !strings.Contains(files["my_lib.go"], "package my_lib") {
+		t.Errorf("library source = %q", files["my_lib.go"])
+	}
+	if !strings.Contains(files["Dockerfile"], "golang:") {
+		t.Errorf("Dockerfile = %q", files["Dockerfile"])
+	}
+}
+
+func TestScaffoldRejectsUnknownInput(t *testing.T) {
+	if _, err := Scaffold(ScaffoldOptions{Language: "cobol"}); err == nil {
+		t.Error("scaffolding an unsupported language succeeded")
+	}
+	if _, err := Scaffold(ScaffoldOptions{Language: "go", ProjectType: "plugin"}); err == nil {
+		t.Error("scaffolding an unknown project type succeeded")
+	}
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSummaryItems bounds the dependencies and scripts listed in a summary
+	maxSummaryItems = 20
+
+	// maxSummaryFileTypes bounds the file extensions listed in a summary
+	maxSummaryFileTypes = 8
+)
+
+// Summary describes the project in a few lines: the detected toolchain,
+// dependencies, scripts and the kinds of files it contains. Unlike the JSON
+// form it stays small however many files the project has.
+func (info *ProjectInfo) Summary() string {
+	var builder strings.Builder
+	if info.RootPath != "" {
+		builder.WriteString(fmt.Sprintf("- Root: %s\n", info.RootPath))
+	}
+	language := info.Language
+	if language == "" {
+		language = "unknown"
+	}
+	builder.WriteString(fmt.Sprintf("- Language: %s\n", language))
+	if info.Framework != "" {
+		builder.WriteString(fmt.Sprintf("- Framework: %s\n", info.Framework))
+	}
+	if info.PackageManager != "" {
+		builder.WriteString(fmt.Sprintf("- Package manager: %s\n", info.PackageManager))
+	}
+	if info.BuildTool != "" {
+		builder.WriteString(fmt.Sprintf("- Build tool: %s\n", info.BuildTool))
+	}
+	if info.TestFramework != "" {
+		builder.WriteString(fmt.Sprintf("- Test framework: %s\n", info.TestFramework))
+	}
+	if info.Git != nil {
+		builder.WriteString(fmt.Sprintf("- Git: %s\n", info.Git.summary()))
+	}
+	if len(info.Dependencies) > 0 {
+		builder.WriteString(fmt.Sprintf("- Dependencies (%d): %s\n", len(info.Dependencies), joinLimited(info.Dependencies, maxSummaryItems)))
+	}
+	if len(info.Scripts) > 0 {
+		names := make([]string, 0, len(info.Scripts))
+		for name := range info.Scripts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		builder.WriteString(fmt.Sprintf("- Scripts: %s\n", joinLimited(names, maxSummaryItems)))
+	}
+	if len(info.SubProjects) > 0 {
+		members := make([]string, 0, len(info.SubProjects))
+		for _, sub := range info.SubProjects {
+			members = append(members, fmt.Sprintf("%s (%d dependencies)", sub.RootPath, len(sub.Dependencies)))
+		}
+		builder.WriteString(fmt.Sprintf("- Workspace members: %s\n", joinLimited(members, maxSummaryItems)))
+	}
+	if len(info.Files) > 0 {
+		builder.WriteString(fmt.Sprintf("- Files (%d): %s\n", len(info.Files), fileTypes(info.Files)))
+	}
+	if info.Truncated {
+		builder.WriteString("- The file scan was stopped early; the file list is incomplete\n")
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// fileTypes counts files by extension, most common first
+func fileTypes(files []string) string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file))
+		if ext == "" {
+			ext = filepath.Base(file)
+		}
+		counts[ext]++
+	}
+
+	types := make([]string, 0, len(counts))
+	for ext := range counts {
+		types = append(types, ext)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	parts := make([]string, 0, len(types))
+	for _, ext := range types {
+		parts = append(parts, fmt.Sprintf("%s %d", ext, counts[ext]))
+	}
+	return joinLimited(parts, maxSummaryFileTypes)
+}
+
+// joinLimited joins at most max items, noting how many were left out
+func joinLimited(items []string, max int) string {
+	if len(items) <= max {
+		return strings.Join(items, ", ")
+	}
+	return strings.Join(items[:max], ", ") + fmt.Sprintf(", ... (%d more)", len(items)-max)
+}
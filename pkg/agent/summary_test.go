@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSummaryFormat(t *testing.T) {
+	info := &ProjectInfo{
+		RootPath:       "/src/shop",
+		Language:       "JavaScript",
+		Framework:      "React",
+		PackageManager: "npm",
+		TestFramework:  "Jest",
+		Dependencies:   []string{"react", "react-dom", "jest"},
+		Scripts:        map[string]string{"test": "jest", "build": "vite build"},
+		Files:          []string{"src/App.jsx", "src/main.jsx", "src/util.js", "package.json", "Dockerfile"},
+	}
+
+	want := strings.Join([]string{
+		"- Root: /src/shop",
+		"- Language: JavaScript",
+		"- Framework: React",
+		"- Package manager: npm",
+		"- Test framework: Jest",
+		"- Dependencies (3): react, react-dom, jest",
+		"- Scripts: build, test",
+		"- Files (5): .jsx 2, .js 1, .json 1, Dockerfile 1",
+	}, "\n")
+	if got := info.Summary(); got != want {
+		t.Errorf("Summary() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSummaryStaysSmall(t *testing.T) {
+	info := &ProjectInfo{Truncated: true}
+	for i := 0; i < 5000; i++ {
+		info.Files = append(info.Files, fmt.Sprintf("pkg/file%d.go", i))
+		info.Dependencies = append(info.Dependencies, fmt.Sprintf("dep%d", i))
+	}
+
+	summary := info.Summary()
+	if len(summary) > 1000 {
+		t.Errorf("summary of a huge project is %d bytes", len(summary))
+	}
+	for _, want := range []string{"- Language: unknown", "- Dependencies (5000): dep0,", "... (4980 more)", "- Files (5000): .go 5000", "file list is incomplete"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary is missing %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "file42.go") {
+		t.Error("summary lists file names")
+	}
+}
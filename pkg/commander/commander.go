@@ -1,17 +1,154 @@
 package commander
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 )
 
+// ErrNotAllowed is returned for commands missing from the allowlist
+var ErrNotAllowed = errors.New("command is not allowed")
+
+// Output holds the separate output streams and the exit status of a command.
+type Output struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int    // -1 when the command was killed by a signal or couldn't be started
+	Signal   string // the signal that ended the command, if any
+}
+
+// String renders the streams as labelled sections, leaving out empty ones,
+// followed by the exit status.
+func (o Output) String() string {
+	var sections []string
+	if strings.TrimSpace(o.Stdout) != "" {
+		sections = append(sections, "STDOUT:\n"+strings.TrimRight(o.Stdout, "\n"))
+	}
+	if strings.TrimSpace(o.Stderr) != "" {
+		sections = append(sections, "STDERR:\n"+strings.TrimRight(o.Stderr, "\n"))
+	}
+	if o.Signal != "" {
+		sections = append(sections, "TERMINATED BY SIGNAL: "+o.Signal)
+	} else {
+		sections = append(sections, fmt.Sprintf("EXIT CODE: %d", o.ExitCode))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
 // ExecuteCommand runs a shell command after validating it against an allowlist.
 func ExecuteCommand(command string, allowedCommands []string) (string, error) {
+	return ExecuteCommandWithEnv(command, allowedCommands, nil)
+}
+
+// ExecuteCommandWithEnv is like ExecuteCommand but sets the given environment
+// variables for the command on top of the current environment.
+func ExecuteCommandWithEnv(command string, allowedCommands []string, env map[string]string) (string, error) {
+	cmd, err := prepareCommand(command, allowedCommands, env)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("command execution failed: %w\nOutput: %s", err, string(output))
+	}
+	return string(output), nil
+}
+
+// ExecuteCommandSeparate is like ExecuteCommandWithEnv but keeps stdout and
+// stderr apart, so warnings can be told from regular output.
+func ExecuteCommandSeparate(command string, allowedCommands []string, env map[string]string) (Output, error) {
+	return runSeparate(command, allowedCommands, "", env)
+}
+
+// ExecuteCommandInDir is like ExecuteCommandSeparate but runs the command
+// in dir instead of the current directory.
+func ExecuteCommandInDir(command string, allowedCommands []string, dir string) (Output, error) {
+	return runSeparate(command, allowedCommands, dir, nil)
+}
+
+// runSeparate runs an allowlisted command in dir, or the current directory
+// when dir is empty, with env set and its output streams kept apart.
+func runSeparate(command string, allowedCommands []string, dir string, env map[string]string) (Output, error) {
+	cmd, err := prepareCommand(command, allowedCommands, env)
+	if err != nil {
+		return Output{}, err
+	}
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	output := Output{Stdout: stdout.String(), Stderr: stderr.String()}
+	output.ExitCode, output.Signal = ExitStatus(err)
+	if err != nil {
+		return output, fmt.Errorf("command execution failed: %w\n%s", err, output)
+	}
+	return output, nil
+}
+
+// ExitStatus returns the exit code of a finished command from the error of
+// cmd.Run or cmd.Wait, and the signal that ended it, if any. Commands that
+// couldn't be started report -1.
+func ExitStatus(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return -1, ""
+	}
+	code := exitErr.ExitCode()
+	if code == -1 {
+		return code, strings.TrimPrefix(exitErr.ProcessState.String(), "signal: ")
+	}
+	return code, ""
+}
+
+// StartCommand validates a command against the allowlist like
+// ExecuteCommandWithEnv and starts it without waiting for it to finish,
+// writing its stdout and stderr to output. The command gets its own process
+// group so StopCommand also ends the processes it spawns.
+func StartCommand(command string, allowedCommands []string, env map[string]string, output io.Writer) (*exec.Cmd, error) {
+	cmd, err := prepareCommand(command, allowedCommands, env)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Don't let grandchildren holding the output pipe open block Wait forever
+	cmd.WaitDelay = time.Second
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	return cmd, nil
+}
+
+// StopCommand asks a command started with StartCommand, and the processes it
+// spawned, to terminate. With force they are killed instead.
+func StopCommand(cmd *exec.Cmd, force bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return stopProcessGroup(cmd, force)
+}
+
+// prepareCommand validates a command against the allowlist and builds the
+// shell invocation for it.
+func prepareCommand(command string, allowedCommands []string, env map[string]string) (*exec.Cmd, error) {
 	command = strings.TrimSpace(command)
 	if command == "" {
-		return "", fmt.Errorf("empty command")
+		return nil, fmt.Errorf("empty command")
 	}
 
 	parts := strings.Fields(command)
@@ -26,7 +163,7 @@ func ExecuteCommand(command string, allowedCommands []string) (string, error) {
 	}
 
 	if !isAllowed {
-		return "", fmt.Errorf("command '%s' is not allowed", baseCmd)
+		return nil, fmt.Errorf("%w: %s", ErrNotAllowed, baseCmd)
 	}
 
 	var cmd *exec.Cmd
@@ -36,9 +173,14 @@ func ExecuteCommand(command string, allowedCommands []string) (string, error) {
 		cmd = exec.Command("sh", "-c", command)
 	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return string(output), fmt.Errorf("command execution failed: %w\nOutput: %s", err, string(output))
+	if len(env) > 0 {
+		cmd.Env = os.Environ()
+		for key, value := range env {
+			if key == "" || strings.ContainsAny(key, "= ") {
+				return nil, fmt.Errorf("invalid environment variable name '%s'", key)
+			}
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
 	}
-	return string(output), nil
+	return cmd, nil
 }
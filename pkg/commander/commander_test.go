@@ -0,0 +1,159 @@
+package commander
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// skipOnWindows skips tests that rely on a POSIX shell
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+}
+
+func TestEnvIsVisibleToChild(t *testing.T) {
+	skipOnWindows(t)
+	t.Setenv("CONSOLE_AI_INHERITED", "from-parent")
+
+	out, err := ExecuteCommandWithEnv(`printenv BUILD_MODE CONSOLE_AI_INHERITED`, []string{"printenv"}, map[string]string{"BUILD_MODE": "production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "production\nfrom-parent\n" {
+		t.Errorf("output = %q, want the passed and the inherited variable", out)
+	}
+}
+
+func TestEnvOverridesInherited(t *testing.T) {
+	skipOnWindows(t)
+	t.Setenv("CGO_ENABLED", "1")
+
+	out, err := ExecuteCommandWithEnv("printenv CGO_ENABLED", []string{"printenv"}, map[string]string{"CGO_ENABLED": "0"})
+	if err != nil || out != "0\n" {
+		t.Errorf("output = %q, %v; want the passed value", out, err)
+	}
+}
+
+func TestCommandNotAllowed(t *testing.T) {
+	if _, err := ExecuteCommand("rm -rf /tmp/nothing", []string{"ls"}); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("ExecuteCommand = %v, want ErrNotAllowed", err)
+	}
+	if _, err := ExecuteCommand("  ", []string{"ls"}); err == nil || !strings.Contains(err.Error(), "empty command") {
+		t.Errorf("empty command = %v", err)
+	}
+}
+
+func TestExecuteCommandSeparate(t *testing.T) {
+	skipOnWindows(t)
+
+	out, err := ExecuteCommandSeparate(`echo built; echo "warning: unused" >&2`, []string{"echo"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Stdout != "built\n" || out.Stderr != "warning: unused\n" || out.ExitCode != 0 {
+		t.Errorf("output = %+v, want the streams apart", out)
+	}
+	if want := "STDOUT:\nbuilt\n\nSTDERR:\nwarning: unused\n\nEXIT CODE: 0"; out.String() != want {
+		t.Errorf("String() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestExecuteCommandSeparateFailure(t *testing.T) {
+	skipOnWindows(t)
+
+	out, err := ExecuteCommandSeparate(`echo partial; echo "error: boom" >&2; exit 3`, []string{"echo"}, nil)
+	if err == nil {
+		t.Fatal("a failing command returned no error")
+	}
+	if out.Stdout != "partial\n" || out.Stderr != "error: boom\n" || out.ExitCode != 3 {
+		t.Errorf("output = %+v", out)
+	}
+	if !strings.Contains(err.Error(), "STDERR:\nerror: boom") {
+		t.Errorf("error = %v, want the labelled streams", err)
+	}
+}
+
+func TestExecuteCommandCombinesStreams(t *testing.T) {
+	skipOnWindows(t)
+
+	out, err := ExecuteCommand(`echo out; echo err >&2`, []string{"echo"})
+	if err != nil || !strings.Contains(out, "out\n") || !strings.Contains(out, "err\n") {
+		t.Errorf("combined output = %q, %v", out, err)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	skipOnWindows(t)
+
+	tests := []struct {
+		command string
+		allowed string
+		code    int
+	}{
+		{"true", "true", 0},
+		{"false", "false", 1},
+		{"exit 2", "exit", 2},
+		// The shell starts but can't find the command
+		{"console-ai-no-such-command", "console-ai-no-such-command", 127},
+	}
+	for _, tt := range tests {
+		out, err := ExecuteCommandSeparate(tt.command, []string{tt.allowed}, nil)
+		if out.ExitCode != tt.code || out.Signal != "" {
+			t.Errorf("%s: exit code %d, signal %q; want %d", tt.command, out.ExitCode, out.Signal, tt.code)
+		}
+		if (err == nil) != (tt.code == 0) {
+			t.Errorf("%s: error = %v", tt.command, err)
+		}
+		if want := fmt.Sprintf("EXIT CODE: %d", tt.code); !strings.HasSuffix(out.String(), want) {
+			t.Errorf("%s: String() = %q, want it to end in %q", tt.command, out.String(), want)
+		}
+	}
+}
+
+func TestExitStatusOfSignal(t *testing.T) {
+	skipOnWindows(t)
+
+	out, err := ExecuteCommandSeparate("kill -TERM $$", []string{"kill"}, nil)
+	if err == nil {
+		t.Fatal("a killed command returned no error")
+	}
+	if out.ExitCode != -1 || out.Signal != "terminated" {
+		t.Errorf("exit code %d, signal %q; want -1 and terminated", out.ExitCode, out.Signal)
+	}
+	if !strings.HasSuffix(out.String(), "TERMINATED BY SIGNAL: terminated") {
+		t.Errorf("String() = %q, want the signal", out.String())
+	}
+}
+
+func TestExitStatusOfUnstartedCommand(t *testing.T) {
+	if code, signal := ExitStatus(exec.ErrNotFound); code != -1 || signal != "" {
+		t.Errorf("ExitStatus = %d, %q; want -1 without a signal", code, signal)
+	}
+	if code, signal := ExitStatus(nil); code != 0 || signal != "" {
+		t.Errorf("ExitStatus(nil) = %d, %q", code, signal)
+	}
+}
+
+func TestExecuteCommandInDir(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+
+	out, err := ExecuteCommandInDir("pwd -P", []string{"pwd"}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.Stdout) != want || out.ExitCode != 0 {
+		t.Errorf("output = %+v, want the command run in %s", out, want)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package commander
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup runs the command in a new process group, so it can be
+// stopped together with the processes it spawns
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// stopProcessGroup signals the process group of cmd, asking it to terminate
+// or, with force, killing it
+func stopProcessGroup(cmd *exec.Cmd, force bool) error {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	// A negative pid signals the whole group
+	if err := syscall.Kill(-cmd.Process.Pid, sig); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
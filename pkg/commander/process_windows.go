@@ -0,0 +1,20 @@
+//go:build windows
+
+package commander
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// setProcessGroup is a no-op on Windows; stopProcessGroup ends the process tree instead
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// stopProcessGroup ends cmd and the processes it spawned. Windows has no
+// polite termination signal for console programs, so force is implied.
+func stopProcessGroup(cmd *exec.Cmd, force bool) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// clearKeys removes API keys set in the environment running the tests
+func clearKeys(t *testing.T) {
+	t.Helper()
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	clearKeys(t)
+	cfg, err := LoadConfig("")
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatalf("LoadConfig without a key = %v, want ErrMissingAPIKey", err)
+	}
+	if cfg == nil || cfg.GeminiAPIKey != "" {
+		t.Errorf("config = %+v, want the defaults without a key", cfg)
+	}
+}
+
+func TestAPIKeyFromEnvironment(t *testing.T) {
+	clearKeys(t)
+	t.Setenv("GEMINI_API_KEY", "gemini-key")
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GeminiAPIKey != "gemini-key" {
+		t.Errorf("GeminiAPIKey = %q, want gemini-key", cfg.GeminiAPIKey)
+	}
+
+	clearKeys(t)
+	t.Setenv("GOOGLE_API_KEY", "google-key")
+	cfg, err = LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GeminiAPIKey != "google-key" {
+		t.Errorf("GeminiAPIKey = %q, want google-key", cfg.GeminiAPIKey)
+	}
+}
+
+// writeConfig writes a JSON config file and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "console-ai.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const fileConfig = `{
+	"api_key": "file-key",
+	"model": "gemini-2.5-pro",
+	"humor_level": 30,
+	"allowed_commands": ["go", "git"],
+	"logging": {"level": "DEBUG"},
+	"agent": {"dry_run": true}
+}`
+
+func TestConfigFileOnly(t *testing.T) {
+	clearKeys(t)
+	cfg, err := LoadConfig(writeConfig(t, fileConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GeminiAPIKey != "file-key" || cfg.ModelName != "gemini-2.5-pro" || cfg.HumorLevel != 30 {
+		t.Errorf("config = key %q, model %q, humor %d", cfg.GeminiAPIKey, cfg.ModelName, cfg.HumorLevel)
+	}
+	if len(cfg.AllowedCommands) != 2 || cfg.Logging.Level != "DEBUG" || !cfg.Agent.DryRun {
+		t.Errorf("config = commands %v, log level %s, dry run %v", cfg.AllowedCommands, cfg.Logging.Level, cfg.Agent.DryRun)
+	}
+	// Keys missing from the file keep their defaults
+	if cfg.Logging.Format != "text" || !cfg.Agent.AutoAnalyze || cfg.MaxHistoryTurns != 50 {
+		t.Errorf("defaults lost: format %q, auto analyze %v, max turns %d", cfg.Logging.Format, cfg.Agent.AutoAnalyze, cfg.MaxHistoryTurns)
+	}
+}
+
+func TestConfigEnvOnly(t *testing.T) {
+	clearKeys(t)
+	t.Setenv("GEMINI_API_KEY", "env-key")
+	t.Setenv("CONSOLE_AI_MODEL", "gemini-2.0-flash")
+	t.Setenv("CONSOLE_AI_HUMOR_LEVEL", "70")
+
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GeminiAPIKey != "env-key" || cfg.ModelName != "gemini-2.0-flash" || cfg.HumorLevel != 70 {
+		t.Errorf("config = key %q, model %q, humor %d", cfg.GeminiAPIKey, cfg.ModelName, cfg.HumorLevel)
+	}
+}
+
+func TestConfigEnvOverridesFile(t *testing.T) {
+	clearKeys(t)
+	t.Setenv("CONSOLE_AI_MODEL", "gemini-2.0-flash")
+	t.Setenv("CONSOLE_AI_ALLOWED_COMMANDS", "npm, node")
+
+	cfg, err := LoadConfig(writeConfig(t, fileConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ModelName != "gemini-2.0-flash" {
+		t.Errorf("ModelName = %q, want the env value", cfg.ModelName)
+	}
+	if len(cfg.AllowedCommands) != 2 || cfg.AllowedCommands[0] != "npm" || cfg.AllowedCommands[1] != "node" {
+		t.Errorf("AllowedCommands = %q, want the env value", cfg.AllowedCommands)
+	}
+	if cfg.HumorLevel != 30 || cfg.GeminiAPIKey != "file-key" {
+		t.Errorf("file values not set by env were lost: humor %d, key %q", cfg.HumorLevel, cfg.GeminiAPIKey)
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	clearKeys(t)
+	for _, content := range []string{`{"model": `, `{"modle": "typo"}`} {
+		if _, err := LoadConfig(writeConfig(t, content)); err == nil || errors.Is(err, ErrMissingAPIKey) {
+			t.Errorf("LoadConfig(%s) = %v, want a parse error", content, err)
+		}
+	}
+}
+
+func TestGetConfigReadsConfigEnv(t *testing.T) {
+	clearKeys(t)
+	path := filepath.Join(t.TempDir(), "custom.json")
+	if err := os.WriteFile(path, []byte(`{"api_key": "file-key"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONSOLE_AI_CONFIG", path)
+
+	cfg, err := GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GeminiAPIKey != "file-key" {
+		t.Errorf("GeminiAPIKey = %q, want file-key", cfg.GeminiAPIKey)
+	}
+}
+
+func TestClampHumorLevel(t *testing.T) {
+	tests := []struct {
+		level, want int
+	}{
+		{-20, 0},
+		{0, 0},
+		{42, 42},
+		{100, 100},
+		{9000, 100},
+	}
+	for _, tt := range tests {
+		if got := ClampHumorLevel(tt.level); got != tt.want {
+			t.Errorf("ClampHumorLevel(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestHumorLevelClampedOnLoad(t *testing.T) {
+	clearKeys(t)
+	t.Setenv("CONSOLE_AI_HUMOR_LEVEL", "9000")
+	cfg, err := LoadConfig(writeConfig(t, `{"humor_level": -5}`))
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.HumorLevel != MaxHumorLevel {
+		t.Errorf("HumorLevel from env = %d, want %d", cfg.HumorLevel, MaxHumorLevel)
+	}
+
+	t.Setenv("CONSOLE_AI_HUMOR_LEVEL", "")
+	cfg, err = LoadConfig(writeConfig(t, `{"humor_level": -5}`))
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.HumorLevel != MinHumorLevel {
+		t.Errorf("HumorLevel from file = %d, want %d", cfg.HumorLevel, MinHumorLevel)
+	}
+}
+
+func TestReplyTransformerSettings(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if !cfg.Agent.StripEmoji || cfg.Agent.MaxReplyChars != 0 {
+		t.Errorf("defaults: strip emoji %v, max reply %d; want true and 0", cfg.Agent.StripEmoji, cfg.Agent.MaxReplyChars)
+	}
+
+	t.Setenv("CONSOLE_AI_STRIP_EMOJI", "false")
+	t.Setenv("CONSOLE_AI_MAX_REPLY_CHARS", "500")
+	cfg, err = LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.Agent.StripEmoji || cfg.Agent.MaxReplyChars != 500 {
+		t.Errorf("from env: strip emoji %v, max reply %d; want false and 500", cfg.Agent.StripEmoji, cfg.Agent.MaxReplyChars)
+	}
+}
+
+func TestSafetyThresholds(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `{"safety": {"dangerous_content": "only_high"}}`))
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.Safety.DangerousContent != "only_high" || cfg.Safety.Harassment != DefaultSafetyThreshold {
+		t.Errorf("safety from file = %+v", cfg.Safety)
+	}
+
+	t.Setenv("CONSOLE_AI_SAFETY_THRESHOLD", "NONE")
+	t.Setenv("CONSOLE_AI_SAFETY_HATE_SPEECH", "low_and_above")
+	cfg, err = LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	want := SafetyConfig{Harassment: "none", HateSpeech: "low_and_above", SexuallyExplicit: "none", DangerousContent: "none"}
+	if cfg.Safety != want {
+		t.Errorf("safety from env = %+v, want %+v", cfg.Safety, want)
+	}
+}
+
+func TestInvalidSafetyThreshold(t *testing.T) {
+	t.Setenv("CONSOLE_AI_SAFETY_HARASSMENT", "sometimes")
+	if _, err := LoadConfig(""); err == nil || errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("LoadConfig = %v, want an invalid threshold error", err)
+	}
+}
+
+func TestProjectRootFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CONSOLE_AI_PROJECT_ROOT", dir)
+	cfg, err := LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.ProjectRoot != dir {
+		t.Errorf("ProjectRoot = %q, want %q", cfg.ProjectRoot, dir)
+	}
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `{"agent": {"ignore_patterns": ["bazel-*", ".next"]}}`))
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if want := []string{"bazel-*", ".next"}; !reflect.DeepEqual(cfg.Agent.IgnorePatterns, want) {
+		t.Errorf("IgnorePatterns from file = %q, want %q", cfg.Agent.IgnorePatterns, want)
+	}
+
+	t.Setenv("CONSOLE_AI_IGNORE_PATTERNS", "dist, bazel-*")
+	cfg, err = LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if want := []string{"dist", "bazel-*"}; !reflect.DeepEqual(cfg.Agent.IgnorePatterns, want) {
+		t.Errorf("IgnorePatterns from env = %q, want %q", cfg.Agent.IgnorePatterns, want)
+	}
+}
+
+func TestSystemPromptExtraFromFile(t *testing.T) {
+	promptFile := filepath.Join(t.TempDir(), "standards.md")
+	if err := os.WriteFile(promptFile, []byte("\nWrap errors with %w.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONSOLE_AI_SYSTEM_PROMPT_EXTRA", "You are a Go reviewer.")
+	t.Setenv("CONSOLE_AI_SYSTEM_PROMPT_FILE", promptFile)
+
+	cfg, err := LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if want := "You are a Go reviewer.\n\nWrap errors with %w."; cfg.SystemPromptExtra != want {
+		t.Errorf("SystemPromptExtra = %q, want %q", cfg.SystemPromptExtra, want)
+	}
+}
+
+func TestMissingSystemPromptFile(t *testing.T) {
+	t.Setenv("CONSOLE_AI_SYSTEM_PROMPT_FILE", filepath.Join(t.TempDir(), "missing.md"))
+	if _, err := LoadConfig(""); err == nil || errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("LoadConfig = %v, want an error for the missing prompt file", err)
+	}
+}
+
+func TestHistoryFileName(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.HistoryFileName != "CB.hist" {
+		t.Errorf("default HistoryFileName = %q, want CB.hist", cfg.HistoryFileName)
+	}
+
+	cfg, err = LoadConfig(writeConfig(t, `{"history_file": "team.history"}`))
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.HistoryFileName != "team.history" {
+		t.Errorf("HistoryFileName from file = %q", cfg.HistoryFileName)
+	}
+
+	t.Setenv("CONSOLE_AI_HISTORY_FILE", "env.hist")
+	cfg, err = LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.HistoryFileName != "env.hist" {
+		t.Errorf("HistoryFileName from env = %q", cfg.HistoryFileName)
+	}
+}
+
+func TestTone(t *testing.T) {
+	t.Setenv("CONSOLE_AI_TONE", " Mentor ")
+	cfg, err := LoadConfig("")
+	if err != nil && !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	if cfg.Tone != "mentor" {
+		t.Errorf("Tone = %q, want mentor", cfg.Tone)
+	}
+
+	t.Setenv("CONSOLE_AI_TONE", "grumpy")
+	if _, err := LoadConfig(""); err == nil || errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("LoadConfig = %v, want an invalid tone error", err)
+	}
+}
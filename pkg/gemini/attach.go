@@ -0,0 +1,103 @@
+package gemini
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"console-ai/pkg/config"
+)
+
+// maxAttachmentBytes bounds the content attached for each @path reference
+const maxAttachmentBytes = 32 * 1024
+
+// attachmentPattern matches @path tokens at the start of the input or after
+// whitespace, so e-mail addresses are left alone
+var attachmentPattern = regexp.MustCompile(`(^|\s)@([^\s@]+)`)
+
+// ExpandAttachments prepends the contents of the files referenced as @path
+// in input, so the model doesn't need a read_file call for them. Paths are
+// confined to the project root like the file tools. The notes describe each
+// reference for the user, including those that couldn't be attached, which
+// are left in the prompt as they are.
+func ExpandAttachments(cfg *config.Config, input string) (string, []string) {
+	matches := attachmentPattern.FindAllStringSubmatch(input, -1)
+	if len(matches) == 0 {
+		return input, nil
+	}
+
+	executor := NewToolExecutor(cfg)
+	seen := make(map[string]bool)
+	var attached strings.Builder
+	var notes []string
+	for _, match := range matches {
+		path := strings.TrimRight(match[2], ".,;:!?)\"'")
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		content, truncated, err := executor.readAttachment(path)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("Could not attach @%s: %v", path, err))
+			continue
+		}
+
+		fence := "```"
+		if strings.Contains(content, fence) {
+			fence = "~~~~"
+		}
+		attached.WriteString(fmt.Sprintf("File %s:\n%s\n%s\n%s\n\n", path, fence, strings.TrimRight(content, "\n"), fence))
+		if truncated {
+			notes = append(notes, fmt.Sprintf("Attached @%s (first %d bytes)", path, maxAttachmentBytes))
+		} else {
+			notes = append(notes, fmt.Sprintf("Attached @%s", path))
+		}
+	}
+
+	if attached.Len() == 0 {
+		return input, notes
+	}
+	return "Attached files:\n\n" + attached.String() + input, notes
+}
+
+// readAttachment reads at most maxAttachmentBytes of a text file inside the
+// project. truncated reports whether the file was longer.
+func (e *ToolExecutor) readAttachment(path string) (content string, truncated bool, err error) {
+	resolved, err := e.confinePath(path)
+	if err != nil {
+		return "", false, err
+	}
+	info, err := os.Stat(resolved)
+	if os.IsNotExist(err) {
+		return "", false, fmt.Errorf("file not found")
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if info.IsDir() {
+		return "", false, fmt.Errorf("is a directory")
+	}
+	if binary, err := isBinaryFile(resolved); err != nil {
+		return "", false, err
+	} else if binary {
+		return "", false, fmt.Errorf("is a binary file")
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxAttachmentBytes+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) > maxAttachmentBytes {
+		return strings.ToValidUTF8(string(data[:maxAttachmentBytes]), ""), true, nil
+	}
+	return string(data), false, nil
+}
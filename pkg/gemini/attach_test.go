@@ -0,0 +1,86 @@
+package gemini
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/config"
+)
+
+// attachProject returns a configuration rooted in a temporary working
+// directory that holds the given files
+func attachProject(t *testing.T, files map[string]string) *config.Config {
+	t.Helper()
+	e, _ := newTestExecutor(t)
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e.config
+}
+
+func TestExpandValidAttachment(t *testing.T) {
+	cfg := attachProject(t, map[string]string{"main.go": "package main\n"})
+
+	prompt, notes := ExpandAttachments(cfg, "explain @main.go.")
+
+	want := "Attached files:\n\nFile main.go:\n```\npackage main\n```\n\nexplain @main.go."
+	if prompt != want {
+		t.Errorf("prompt = %q, want %q", prompt, want)
+	}
+	if !reflect.DeepEqual(notes, []string{"Attached @main.go"}) {
+		t.Errorf("notes = %q", notes)
+	}
+}
+
+func TestExpandMissingAttachment(t *testing.T) {
+	cfg := attachProject(t, nil)
+
+	input := "explain @missing.go"
+	prompt, notes := ExpandAttachments(cfg, input)
+
+	if prompt != input {
+		t.Errorf("prompt = %q, want the input unchanged", prompt)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "Could not attach @missing.go: file not found") {
+		t.Errorf("notes = %q, want a missing file note", notes)
+	}
+}
+
+func TestExpandMixedAttachments(t *testing.T) {
+	cfg := attachProject(t, map[string]string{"a.txt": "alpha"})
+
+	prompt, notes := ExpandAttachments(cfg, "compare @a.txt and @b.txt, mail me@example.com")
+
+	if !strings.HasPrefix(prompt, "Attached files:\n\nFile a.txt:\n```\nalpha\n```") || strings.Contains(prompt, "File b.txt") {
+		t.Errorf("prompt = %q, want only a.txt attached", prompt)
+	}
+	if len(notes) != 2 {
+		t.Errorf("notes = %q, want one per @path and none for the address", notes)
+	}
+}
+
+func TestAttachmentIsConfined(t *testing.T) {
+	cfg := attachProject(t, nil)
+
+	_, notes := ExpandAttachments(cfg, "show @../../etc/passwd")
+	if len(notes) != 1 || !strings.HasPrefix(notes[0], "Could not attach") {
+		t.Errorf("notes = %q, want the path outside the project refused", notes)
+	}
+}
+
+func TestLargeAttachmentIsTruncated(t *testing.T) {
+	cfg := attachProject(t, map[string]string{"big.txt": strings.Repeat("x", maxAttachmentBytes+100)})
+
+	prompt, notes := ExpandAttachments(cfg, "@big.txt")
+
+	if got := strings.Count(prompt, strings.Repeat("x", 64)); got != maxAttachmentBytes/64 {
+		t.Errorf("attached %d runs of 64 bytes, want %d", got, maxAttachmentBytes/64)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "first") {
+		t.Errorf("notes = %q, want a truncation note", notes)
+	}
+}
@@ -0,0 +1,257 @@
+package gemini
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"console-ai/pkg/commander"
+	"console-ai/pkg/logger"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+const (
+	// maxBackgroundCommands bounds how many background commands run at once
+	maxBackgroundCommands = 5
+
+	// maxBackgroundOutputBytes is how much of the latest output is kept per command
+	maxBackgroundOutputBytes = 64 * 1024
+
+	// defaultTailLines is the number of output lines tail_background_output returns by default
+	defaultTailLines = 40
+
+	// backgroundStopGrace is how long a stopped command may take to exit before it is killed
+	backgroundStopGrace = 3 * time.Second
+)
+
+// tailBuffer keeps the most recent maxBackgroundOutputBytes written to it
+type tailBuffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if over := len(b.data) - maxBackgroundOutputBytes; over > 0 {
+		b.data = append([]byte(nil), b.data[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.ToValidUTF8(string(b.data), "")
+}
+
+// backgroundCommand is a command started with run_background_command
+type backgroundCommand struct {
+	id      int
+	command string
+	cmd     *exec.Cmd
+	output  *tailBuffer
+	started time.Time
+	done    chan struct{} // closed once the command has exited
+	err     error         // result of cmd.Wait, set before done is closed
+}
+
+// status describes whether the command is still running or how it ended
+func (b *backgroundCommand) status() string {
+	select {
+	case <-b.done:
+		code, signal := commander.ExitStatus(b.err)
+		if signal != "" {
+			return "ended by signal: " + signal
+		}
+		return fmt.Sprintf("exited with code %d", code)
+	default:
+		return fmt.Sprintf("running for %s", time.Since(b.started).Round(time.Second))
+	}
+}
+
+// The background commands live for the whole process so servers started in
+// one turn can be checked and stopped in later ones; a new ToolExecutor is
+// created for every turn.
+var (
+	backgroundMu       sync.Mutex
+	backgroundCommands = make(map[int]*backgroundCommand)
+	nextBackgroundID   = 1
+)
+
+// runBackgroundCommand starts a long-running command such as a dev server
+// and returns right away with a handle for the other background tools.
+func (e *ToolExecutor) runBackgroundCommand(fc genai.FunctionCall) (string, error) {
+	command, ok := fc.Args["command"].(string)
+	if !ok || strings.TrimSpace(command) == "" {
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'command' argument")
+	}
+	env, err := parseEnvArg(fc.Args["env"])
+	if err != nil {
+		return "", err
+	}
+	if e.config.Agent.DryRun {
+		return dryRunResult("would start '%s' in the background", command), nil
+	}
+
+	backgroundMu.Lock()
+	defer backgroundMu.Unlock()
+	running := 0
+	for _, b := range backgroundCommands {
+		if !isDone(b) {
+			running++
+		}
+	}
+	if running >= maxBackgroundCommands {
+		return "", toolErrorf(ToolErrorBlocked, "%d background commands are already running; stop one with stop_background_command first", running)
+	}
+
+	output := &tailBuffer{}
+	cmd, err := commander.StartCommand(command, e.config.AllowedCommands, env, output)
+	if err != nil {
+		return "", err
+	}
+	b := &backgroundCommand{
+		id:      nextBackgroundID,
+		command: command,
+		cmd:     cmd,
+		output:  output,
+		started: time.Now(),
+		done:    make(chan struct{}),
+	}
+	nextBackgroundID++
+	backgroundCommands[b.id] = b
+	go func() {
+		b.err = cmd.Wait()
+		close(b.done)
+	}()
+
+	e.log.Info("Started background command %d (pid %d): %s", b.id, cmd.Process.Pid, command)
+	return fmt.Sprintf("Started background command %d (pid %d): %s\nUse tail_background_output with id %d to see its output and stop_background_command to stop it.", b.id, cmd.Process.Pid, command, b.id), nil
+}
+
+// tailBackgroundOutput returns the latest output lines of a background command
+func (e *ToolExecutor) tailBackgroundOutput(fc genai.FunctionCall) (string, error) {
+	b, err := backgroundCommandArg(fc)
+	if err != nil {
+		return "", err
+	}
+	lines := defaultTailLines
+	if n, ok := intArg(fc.Args, "lines"); ok && n > 0 {
+		lines = n
+	}
+	return fmt.Sprintf("Background command %d (%s): %s.\n%s", b.id, b.command, b.status(), tailLines(b.output.String(), lines)), nil
+}
+
+// stopBackgroundCommand terminates a background command and the processes it
+// spawned, killing them when they don't exit within backgroundStopGrace.
+func (e *ToolExecutor) stopBackgroundCommand(fc genai.FunctionCall) (string, error) {
+	b, err := backgroundCommandArg(fc)
+	if err != nil {
+		return "", err
+	}
+	if e.config.Agent.DryRun {
+		return dryRunResult("would stop background command %d (%s)", b.id, b.command), nil
+	}
+	if err := stopBackground(b); err != nil {
+		return "", fmt.Errorf("failed to stop background command %d: %w", b.id, err)
+	}
+
+	backgroundMu.Lock()
+	delete(backgroundCommands, b.id)
+	backgroundMu.Unlock()
+
+	e.log.Info("Stopped background command %d: %s", b.id, b.command)
+	return fmt.Sprintf("Stopped background command %d (%s): %s.\n%s", b.id, b.command, b.status(), tailLines(b.output.String(), defaultTailLines)), nil
+}
+
+// StopBackgroundCommands stops all background commands, so servers started
+// by the AI don't outlive the program
+func StopBackgroundCommands() {
+	backgroundMu.Lock()
+	commands := backgroundCommands
+	backgroundCommands = make(map[int]*backgroundCommand)
+	backgroundMu.Unlock()
+
+	for _, b := range commands {
+		if err := stopBackground(b); err != nil {
+			logger.Warn("Failed to stop background command %d: %v", b.id, err)
+		}
+	}
+}
+
+// stopBackground terminates b, escalating to a kill after backgroundStopGrace
+func stopBackground(b *backgroundCommand) error {
+	if isDone(b) {
+		return nil
+	}
+	if err := commander.StopCommand(b.cmd, false); err != nil {
+		return err
+	}
+	select {
+	case <-b.done:
+		return nil
+	case <-time.After(backgroundStopGrace):
+	}
+	if err := commander.StopCommand(b.cmd, true); err != nil {
+		return err
+	}
+	<-b.done
+	return nil
+}
+
+// isDone reports whether a background command has exited
+func isDone(b *backgroundCommand) bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// backgroundCommandArg looks up the background command named by the id argument
+func backgroundCommandArg(fc genai.FunctionCall) (*backgroundCommand, error) {
+	backgroundMu.Lock()
+	defer backgroundMu.Unlock()
+
+	id, ok := intArg(fc.Args, "id")
+	if b, found := backgroundCommands[id]; ok && found {
+		return b, nil
+	}
+
+	ids := make([]int, 0, len(backgroundCommands))
+	for id := range backgroundCommands {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	if len(ids) == 0 {
+		return nil, toolErrorf(ToolErrorNotFound, "no background commands are running")
+	}
+	var list []string
+	for _, id := range ids {
+		list = append(list, fmt.Sprintf("%d (%s)", id, backgroundCommands[id].command))
+	}
+	if !ok {
+		return nil, toolErrorf(ToolErrorValidation, "invalid or missing 'id' argument; background commands: %s", strings.Join(list, ", "))
+	}
+	return nil, toolErrorf(ToolErrorNotFound, "no background command with id %d; background commands: %s", id, strings.Join(list, ", "))
+}
+
+// tailLines returns the last n lines of output, or a note when there is none
+func tailLines(output string, n int) string {
+	output = strings.TrimRight(output, "\n")
+	if strings.TrimSpace(output) == "" {
+		return "(no output yet)"
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,129 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// backgroundExecutor returns an executor allowed to run sleep and echo, and
+// stops the background commands left running when the test ends
+func backgroundExecutor(t *testing.T) *ToolExecutor {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	e, _ := newTestExecutor(t)
+	e.config.AllowedCommands = []string{"sleep", "echo"}
+	t.Cleanup(StopBackgroundCommands)
+	return e
+}
+
+var startedPattern = regexp.MustCompile(`Started background command (\d+) \(pid \d+\)`)
+
+// startBackground starts command in the background and returns its id
+func startBackground(t *testing.T, e *ToolExecutor, command string) float64 {
+	t.Helper()
+	output, err := call(e, "run_background_command", map[string]any{"command": command})
+	if err != nil {
+		t.Fatalf("run_background_command: %v", err)
+	}
+	match := startedPattern.FindStringSubmatch(output)
+	if match == nil {
+		t.Fatalf("output = %q, want the id and pid", output)
+	}
+	id, _ := strconv.Atoi(match[1])
+	// ids arrive from the model as JSON numbers
+	return float64(id)
+}
+
+func TestBackgroundSleepStartTailStop(t *testing.T) {
+	e := backgroundExecutor(t)
+
+	start := time.Now()
+	id := startBackground(t, e, "sleep 30")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("run_background_command took %s, want it to return right away", elapsed)
+	}
+
+	output, err := call(e, "tail_background_output", map[string]any{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "running for") || !strings.Contains(output, "(no output yet)") {
+		t.Errorf("tail = %q, want a running command without output", output)
+	}
+
+	output, err = call(e, "stop_background_command", map[string]any{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, fmt.Sprintf("Stopped background command %d (sleep 30): ended by signal", int(id))) {
+		t.Errorf("stop = %q, want the command ended by a signal", output)
+	}
+
+	_, err = call(e, "tail_background_output", map[string]any{"id": id})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorNotFound {
+		t.Errorf("tail after stop = %v, want a not found error", err)
+	}
+}
+
+func TestBackgroundOutputIsTailed(t *testing.T) {
+	e := backgroundExecutor(t)
+	id := startBackground(t, e, "echo one; echo two; echo three; sleep 30")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		output, err := call(e, "tail_background_output", map[string]any{"id": id, "lines": 2.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(output, "\ntwo\nthree") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("tail = %q, want the last two lines", output)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestBackgroundCommandLimit(t *testing.T) {
+	e := backgroundExecutor(t)
+	for i := 0; i < maxBackgroundCommands; i++ {
+		startBackground(t, e, "sleep 30")
+	}
+
+	_, err := call(e, "run_background_command", map[string]any{"command": "sleep 30"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorBlocked {
+		t.Errorf("starting one more command = %v, want a blocked error", err)
+	}
+}
+
+func TestBackgroundCommandNotAllowed(t *testing.T) {
+	e := backgroundExecutor(t)
+
+	if _, err := call(e, "run_background_command", map[string]any{"command": "curl example.com"}); err == nil {
+		t.Error("a command outside the allowlist was started")
+	}
+}
+
+func TestStopBackgroundCommands(t *testing.T) {
+	e := backgroundExecutor(t)
+	first := startBackground(t, e, "sleep 30")
+	startBackground(t, e, "sleep 30")
+
+	StopBackgroundCommands()
+
+	_, err := call(e, "tail_background_output", map[string]any{"id": first})
+	if err == nil || !strings.Contains(err.Error(), "no background commands are running") {
+		t.Errorf("tail after stopping all = %v, want no commands running", err)
+	}
+}
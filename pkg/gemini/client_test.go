@@ -0,0 +1,69 @@
+package gemini
+
+import (
+	"testing"
+
+	"console-ai/pkg/config"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestHarmBlockThreshold(t *testing.T) {
+	tests := map[string]genai.HarmBlockThreshold{
+		"none":             genai.HarmBlockNone,
+		"only_high":        genai.HarmBlockOnlyHigh,
+		"medium_and_above": genai.HarmBlockMediumAndAbove,
+		"low_and_above":    genai.HarmBlockLowAndAbove,
+		" Only_High ":      genai.HarmBlockOnlyHigh,
+		"":                 genai.HarmBlockMediumAndAbove,
+		"bogus":            genai.HarmBlockMediumAndAbove,
+	}
+	for name, want := range tests {
+		if got := harmBlockThreshold(name); got != want {
+			t.Errorf("harmBlockThreshold(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestEveryConfigThresholdIsMapped(t *testing.T) {
+	seen := make(map[genai.HarmBlockThreshold]string)
+	for _, name := range config.SafetyThresholds {
+		threshold := harmBlockThreshold(name)
+		if other, ok := seen[threshold]; ok {
+			t.Errorf("%q and %q map to the same threshold %v", name, other, threshold)
+		}
+		seen[threshold] = name
+	}
+}
+
+func TestSafetySettingsPerCategory(t *testing.T) {
+	settings := safetySettings(config.SafetyConfig{
+		Harassment:       "low_and_above",
+		HateSpeech:       "medium_and_above",
+		SexuallyExplicit: "none",
+		DangerousContent: "only_high",
+	})
+
+	want := map[genai.HarmCategory]genai.HarmBlockThreshold{
+		genai.HarmCategoryHarassment:       genai.HarmBlockLowAndAbove,
+		genai.HarmCategoryHateSpeech:       genai.HarmBlockMediumAndAbove,
+		genai.HarmCategorySexuallyExplicit: genai.HarmBlockNone,
+		genai.HarmCategoryDangerousContent: genai.HarmBlockOnlyHigh,
+	}
+	if len(settings) != len(want) {
+		t.Fatalf("got %d settings, want %d", len(settings), len(want))
+	}
+	for _, setting := range settings {
+		if setting.Threshold != want[setting.Category] {
+			t.Errorf("%v threshold = %v, want %v", setting.Category, setting.Threshold, want[setting.Category])
+		}
+	}
+}
+
+func TestDefaultSafetySettingsKeepMediumAndAbove(t *testing.T) {
+	for _, setting := range safetySettings(testConfig(t).Safety) {
+		if setting.Threshold != genai.HarmBlockMediumAndAbove {
+			t.Errorf("default %v threshold = %v, want medium and above", setting.Category, setting.Threshold)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// DebugTurn captures the raw request and response of a single conversation turn
+// so it can be inspected when the model misbehaves.
+type DebugTurn struct {
+	Timestamp         time.Time
+	SystemInstruction string
+	History           []string
+	Tools             []string
+	Requests          []string
+	Responses         []string
+	Usage             TokenUsage // tokens used by the requests completed so far
+
+	requestUsage TokenUsage // latest usage reported for the request in flight
+}
+
+// TokenUsage counts the tokens used by the requests of a turn
+type TokenUsage struct {
+	PromptTokens   int `json:"prompt_tokens"`
+	ResponseTokens int `json:"response_tokens"`
+	TotalTokens    int `json:"total_tokens"`
+}
+
+// add returns the sum of two usages
+func (u TokenUsage) add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:   u.PromptTokens + other.PromptTokens,
+		ResponseTokens: u.ResponseTokens + other.ResponseTokens,
+		TotalTokens:    u.TotalTokens + other.TotalTokens,
+	}
+}
+
+var (
+	lastTurnMu sync.Mutex
+	lastTurn   *DebugTurn
+)
+
+// newDebugTurn snapshots the assembled request for the current turn.
+func newDebugTurn(model *genai.GenerativeModel, history []*genai.Content) *DebugTurn {
+	turn := &DebugTurn{Timestamp: time.Now()}
+
+	if model.SystemInstruction != nil {
+		var parts []string
+		for _, part := range model.SystemInstruction.Parts {
+			parts = append(parts, formatPart(part))
+		}
+		turn.SystemInstruction = strings.Join(parts, "\n")
+	}
+
+	for _, content := range history {
+		for _, part := range content.Parts {
+			turn.History = append(turn.History, fmt.Sprintf("[%s] %s", content.Role, formatPart(part)))
+		}
+	}
+
+	for _, tool := range model.Tools {
+		for _, decl := range tool.FunctionDeclarations {
+			turn.Tools = append(turn.Tools, decl.Name)
+		}
+	}
+
+	return turn
+}
+
+// recordRequest records a message sent to the model during the turn.
+func (t *DebugTurn) recordRequest(part genai.Part) {
+	t.Usage = t.Usage.add(t.requestUsage)
+	t.requestUsage = TokenUsage{}
+	t.Requests = append(t.Requests, formatPart(part))
+}
+
+// recordResponse records the raw parts of a streamed response chunk.
+func (t *DebugTurn) recordResponse(resp *genai.GenerateContentResponse) {
+	if resp == nil {
+		return
+	}
+	// Streamed chunks report the running usage of the whole request
+	if usage := resp.UsageMetadata; usage != nil {
+		t.requestUsage = TokenUsage{
+			PromptTokens:   int(usage.PromptTokenCount),
+			ResponseTokens: int(usage.CandidatesTokenCount),
+			TotalTokens:    int(usage.TotalTokenCount),
+		}
+	}
+	for _, candidate := range resp.Candidates {
+		if candidate.Content == nil {
+			t.Responses = append(t.Responses, fmt.Sprintf("(no content, finish reason: %s)", candidate.FinishReason))
+			continue
+		}
+		for _, part := range candidate.Content.Parts {
+			t.Responses = append(t.Responses, formatPart(part))
+		}
+	}
+}
+
+// TotalUsage returns the tokens used by all requests of the turn
+func (t *DebugTurn) TotalUsage() TokenUsage {
+	return t.Usage.add(t.requestUsage)
+}
+
+// setLastTurn stores the turn as the most recent one.
+func setLastTurn(turn *DebugTurn) {
+	lastTurnMu.Lock()
+	defer lastTurnMu.Unlock()
+	lastTurn = turn
+}
+
+// LastTurn returns the most recently captured turn, or nil if none was recorded.
+func LastTurn() *DebugTurn {
+	lastTurnMu.Lock()
+	defer lastTurnMu.Unlock()
+	return lastTurn
+}
+
+// LastTurnDebug renders the most recent turn for display, redacting the API key.
+func LastTurnDebug(apiKey string) string {
+	turn := LastTurn()
+	if turn == nil {
+		return "No request has been sent yet."
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Last turn at %s\n\n", turn.Timestamp.Format("2006-01-02 15:04:05")))
+
+	builder.WriteString("== System Instruction ==\n")
+	builder.WriteString(turn.SystemInstruction)
+	builder.WriteString("\n\n== Tools ==\n")
+	builder.WriteString(strings.Join(turn.Tools, ", "))
+	builder.WriteString("\n\n== History ==\n")
+	for _, line := range turn.History {
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\n== Requests ==\n")
+	for _, line := range turn.Requests {
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\n== Responses ==\n")
+	for _, line := range turn.Responses {
+		builder.WriteString(line + "\n")
+	}
+
+	return redact(builder.String(), apiKey)
+}
+
+// formatPart renders a single content part in a readable raw form.
+func formatPart(part genai.Part) string {
+	switch p := part.(type) {
+	case genai.Text:
+		return fmt.Sprintf("text: %q", string(p))
+	case genai.FunctionCall:
+		args, _ := json.Marshal(p.Args)
+		return fmt.Sprintf("function_call: %s %s", p.Name, string(args))
+	case genai.FunctionResponse:
+		resp, _ := json.Marshal(p.Response)
+		return fmt.Sprintf("function_response: %s %s", p.Name, string(resp))
+	default:
+		return fmt.Sprintf("%T", part)
+	}
+}
+
+// redact removes the API key from debug output.
+func redact(text, apiKey string) string {
+	if apiKey == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, apiKey, "[REDACTED]")
+}
@@ -0,0 +1,61 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestDebugTurnCapturesLastTurn(t *testing.T) {
+	model := &genai.GenerativeModel{
+		SystemInstruction: &genai.Content{Parts: []genai.Part{genai.Text("be helpful")}},
+		Tools:             []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "read_file"}}}},
+	}
+	history := buildHistory([]string{"earlier question", "earlier answer"})
+
+	turn := newDebugTurn(model, history)
+	turn.recordRequest(genai.Text("what is in main.go? key=secret-key"))
+	turn.recordResponse(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{
+			genai.FunctionCall{Name: "read_file", Args: map[string]any{"path": "main.go"}},
+		}}}},
+	})
+	turn.recordRequest(genai.FunctionResponse{Name: "read_file", Response: map[string]any{"output": "package main"}})
+	turn.recordResponse(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("It declares package main.")}}}},
+	})
+	setLastTurn(turn)
+	defer setLastTurn(nil)
+
+	if LastTurn() != turn {
+		t.Fatal("LastTurn() did not return the recorded turn")
+	}
+
+	out := LastTurnDebug("secret-key")
+	for _, want := range []string{
+		"be helpful",
+		"read_file",
+		`[user] text: "earlier question"`,
+		`[model] text: "earlier answer"`,
+		"what is in main.go?",
+		`function_call: read_file {"path":"main.go"}`,
+		`function_response: read_file {"output":"package main"}`,
+		"It declares package main.",
+		"[REDACTED]",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret-key") {
+		t.Errorf("debug output contains the API key:\n%s", out)
+	}
+}
+
+func TestLastTurnDebugWithoutTurn(t *testing.T) {
+	setLastTurn(nil)
+	if got := LastTurnDebug("key"); got != "No request has been sent yet." {
+		t.Errorf("LastTurnDebug() = %q", got)
+	}
+}
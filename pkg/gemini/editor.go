@@ -0,0 +1,77 @@
+package gemini
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// terminalEditors take over the terminal, which the TUI is drawing on, so
+// open_in_editor refuses them
+var terminalEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true, "pico": true, "emacs": true,
+	"micro": true, "hx": true, "helix": true, "kak": true, "joe": true, "ed": true, "mg": true,
+}
+
+// editorCommand returns the command line that opens path: $VISUAL, then
+// $EDITOR, then VS Code's 'code' when it is in the allowlist.
+func editorCommand(path string, getenv func(string) string, allowedCommands []string) ([]string, error) {
+	var editor []string
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(getenv(name)); len(fields) > 0 {
+			editor = fields
+			break
+		}
+	}
+	if editor == nil {
+		if !containsString(allowedCommands, "code") {
+			return nil, toolErrorf(ToolErrorBlocked, "no editor configured: set VISUAL or EDITOR, or allow the 'code' command")
+		}
+		editor = []string{"code"}
+	}
+
+	base := strings.ToLower(strings.TrimSuffix(filepath.Base(editor[0]), filepath.Ext(editor[0])))
+	if terminalEditors[base] {
+		return nil, toolErrorf(ToolErrorBlocked, "'%s' runs in the terminal Console AI is using; set VISUAL to a graphical editor such as 'code'", editor[0])
+	}
+	return append(editor, path), nil
+}
+
+// openInEditor starts the user's editor on a file without waiting for it to
+// close. Non-interactive runs have nobody to look at the editor and refuse.
+func (e *ToolExecutor) openInEditor(fc genai.FunctionCall) (string, error) {
+	path, ok := fc.Args["path"].(string)
+	if !ok || path == "" {
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'path' argument")
+	}
+	if e.config.Headless {
+		return "", toolErrorf(ToolErrorBlocked, "open_in_editor is not available without the interactive UI")
+	}
+	if _, err := e.confinePath(path); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+
+	args, err := editorCommand(path, os.Getenv, e.config.AllowedCommands)
+	if err != nil {
+		return "", err
+	}
+	if e.config.Agent.DryRun {
+		return dryRunResult("would open '%s' with %s", path, strings.Join(args, " ")), nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start editor '%s': %w", args[0], err)
+	}
+	// Reap the editor whenever it exits; the turn goes on without it
+	go cmd.Wait()
+
+	return fmt.Sprintf("Opened '%s' with %s.", path, args[0]), nil
+}
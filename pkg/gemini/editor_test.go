@@ -0,0 +1,94 @@
+package gemini
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// envLookup returns a getenv function reading vars instead of the environment
+func envLookup(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestEditorCommandFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		allowed []string
+		want    []string
+	}{
+		{"visual", map[string]string{"VISUAL": "code --wait", "EDITOR": "gedit"}, nil, []string{"code", "--wait", "main.go"}},
+		{"editor", map[string]string{"EDITOR": "/usr/bin/gedit"}, nil, []string{"/usr/bin/gedit", "main.go"}},
+		{"blank visual", map[string]string{"VISUAL": "  ", "EDITOR": "subl -n"}, nil, []string{"subl", "-n", "main.go"}},
+		{"code from allowlist", nil, []string{"go", "code"}, []string{"code", "main.go"}},
+	}
+	for _, tt := range tests {
+		got, err := editorCommand("main.go", envLookup(tt.env), tt.allowed)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: editorCommand = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEditorCommandRefusals(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"no editor", nil},
+		{"terminal editor", map[string]string{"EDITOR": "vim"}},
+		{"terminal editor with path", map[string]string{"VISUAL": "/usr/local/bin/nvim -p"}},
+	}
+	for _, tt := range tests {
+		_, err := editorCommand("main.go", envLookup(tt.env), []string{"go"})
+		var toolErr *ToolError
+		if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorBlocked {
+			t.Errorf("%s: error = %v, want a blocked error", tt.name, err)
+		}
+	}
+}
+
+func TestOpenInEditorHeadless(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.Headless = true
+	if err := os.WriteFile("main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := call(e, "open_in_editor", map[string]any{"path": "main.go"})
+	if err == nil || !strings.Contains(err.Error(), "not available without the interactive UI") {
+		t.Errorf("open_in_editor = %v, want a headless error", err)
+	}
+}
+
+func TestOpenInEditorDryRun(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.Agent.DryRun = true
+	t.Setenv("VISUAL", "code --wait")
+	if err := os.WriteFile("main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := call(e, "open_in_editor", map[string]any{"path": "main.go"})
+	if err != nil || !strings.Contains(output, "would open 'main.go' with code --wait main.go") {
+		t.Errorf("open_in_editor = %q, %v", output, err)
+	}
+}
+
+func TestOpenInEditorMissingFile(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	t.Setenv("VISUAL", "code")
+
+	_, err := call(e, "open_in_editor", map[string]any{"path": "missing.go"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorNotFound {
+		t.Errorf("open_in_editor = %v, want a not found error", err)
+	}
+}
@@ -0,0 +1,78 @@
+package gemini
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretEnvName matches environment variable names whose values are masked
+// in the transcript, the session history and the logs.
+var secretEnvName = regexp.MustCompile(`(?i)SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY|CREDENTIAL`)
+
+// parseEnvArg converts the "env" tool argument, a list of KEY=VALUE strings,
+// into a map.
+func parseEnvArg(arg interface{}) (map[string]string, error) {
+	if arg == nil {
+		return nil, nil
+	}
+	items, ok := arg.([]interface{})
+	if !ok {
+		return nil, toolErrorf(ToolErrorValidation, "invalid 'env' argument: expected a list of KEY=VALUE strings")
+	}
+
+	env := make(map[string]string, len(items))
+	for _, item := range items {
+		pair, ok := item.(string)
+		key, value, found := strings.Cut(pair, "=")
+		if !ok || !found || key == "" {
+			return nil, toolErrorf(ToolErrorValidation, "invalid 'env' entry %v: expected KEY=VALUE", item)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// maskEnvValue hides the value of secret-looking variables
+func maskEnvValue(key, value string) string {
+	if secretEnvName.MatchString(key) && value != "" {
+		return "****"
+	}
+	return value
+}
+
+// envNames lists the variable names in env for logging, without values
+func envNames(env map[string]string) string {
+	names := make([]string, 0, len(env))
+	for key := range env {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// displayArgs renders tool call arguments as JSON for the transcript and the
+// session history, masking secret environment variable values.
+func displayArgs(args map[string]interface{}) string {
+	if items, ok := args["env"].([]interface{}); ok {
+		masked := make([]interface{}, len(items))
+		for i, item := range items {
+			masked[i] = item
+			if pair, ok := item.(string); ok {
+				if key, value, found := strings.Cut(pair, "="); found {
+					masked[i] = key + "=" + maskEnvValue(key, value)
+				}
+			}
+		}
+		copied := make(map[string]interface{}, len(args))
+		for key, value := range args {
+			copied[key] = value
+		}
+		copied["env"] = masked
+		args = copied
+	}
+
+	argsJSON, _ := json.Marshal(args) // Safely marshal args to JSON
+	return string(argsJSON)
+}
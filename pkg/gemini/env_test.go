@@ -0,0 +1,49 @@
+package gemini
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestShellCommandEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	e, _ := newTestExecutor(t)
+	e.config.AllowedCommands = []string{"printenv"}
+
+	out, err := call(e, "execute_shell_command", map[string]any{
+		"command": "printenv NODE_ENV",
+		"env":     []interface{}{"NODE_ENV=production"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "STDOUT:\nproduction") {
+		t.Errorf("output = %q, want the variable visible to the command", out)
+	}
+
+	if _, err := call(e, "execute_shell_command", map[string]any{"command": "printenv", "env": []interface{}{"NOVALUE"}}); err == nil {
+		t.Error("an env entry without '=' was accepted")
+	}
+}
+
+func TestSecretEnvValuesAreMasked(t *testing.T) {
+	args := map[string]interface{}{
+		"command": "npm publish",
+		"env":     []interface{}{"NPM_TOKEN=abc123", "NODE_ENV=production"},
+	}
+	for name, out := range map[string]string{
+		"displayArgs": displayArgs(args),
+		"loggedArgs":  fmt.Sprint(loggedArgs(args)),
+	} {
+		if strings.Contains(out, "abc123") {
+			t.Errorf("%s shows the secret: %s", name, out)
+		}
+	}
+	if out := displayArgs(args); !strings.Contains(out, "NPM_TOKEN=****") || !strings.Contains(out, "NODE_ENV=production") {
+		t.Errorf("displayArgs = %s", out)
+	}
+}
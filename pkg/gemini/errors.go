@@ -0,0 +1,73 @@
+package gemini
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryableCodes are the gRPC status codes of API errors that may succeed
+// when retried, mapped to the HTTP status the REST transport reports for them.
+var RetryableCodes = map[codes.Code]int{
+	codes.Unavailable:       http.StatusServiceUnavailable,
+	codes.ResourceExhausted: http.StatusTooManyRequests,
+	codes.DeadlineExceeded:  http.StatusGatewayTimeout,
+	codes.Internal:          http.StatusInternalServerError,
+}
+
+// capabilityErrorMarkers are fragments of API errors that mean the model
+// couldn't serve the request right now, but another model might.
+var capabilityErrorMarkers = []string{
+	"overloaded",
+	"unavailable",
+	"resource_exhausted",
+	"resource has been exhausted",
+	"high demand",
+	"error 429",
+	"error 503",
+}
+
+// isRetryable reports whether err is an API error with one of the
+// RetryableCodes, as a gRPC status or as the matching HTTP status.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if s, ok := status.FromError(err); ok {
+		_, retryable := RetryableCodes[s.Code()]
+		return retryable
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		for _, code := range RetryableCodes {
+			if apiErr.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCapabilityError reports whether err is a safety block or a capacity
+// error worth retrying on a fallback model.
+func isCapabilityError(err error) bool {
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		return true
+	}
+	if isRetryable(err) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	for _, marker := range capabilityErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
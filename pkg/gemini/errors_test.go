@@ -0,0 +1,73 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"unavailable", status.Error(codes.Unavailable, "try later"), true},
+		{"resource exhausted", status.Error(codes.ResourceExhausted, "quota"), true},
+		{"deadline exceeded", status.Error(codes.DeadlineExceeded, "slow"), true},
+		{"internal", status.Error(codes.Internal, "oops"), true},
+		{"invalid argument", status.Error(codes.InvalidArgument, "bad request"), false},
+		{"permission denied", status.Error(codes.PermissionDenied, "bad key"), false},
+		{"not found", status.Error(codes.NotFound, "no model"), false},
+		{"http 503", &googleapi.Error{Code: http.StatusServiceUnavailable}, true},
+		{"http 429", &googleapi.Error{Code: http.StatusTooManyRequests}, true},
+		{"wrapped http 500", fmt.Errorf("stream error: %w", &googleapi.Error{Code: http.StatusInternalServerError}), true},
+		{"http 400", &googleapi.Error{Code: http.StatusBadRequest}, false},
+		{"http 403", &googleapi.Error{Code: http.StatusForbidden}, false},
+		{"cancelled", context.Canceled, false},
+		{"plain error", errors.New("something failed"), false},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryableCodesCoverTheTable(t *testing.T) {
+	for code, httpStatus := range RetryableCodes {
+		if !isRetryable(status.Error(code, "")) {
+			t.Errorf("gRPC code %v is not retryable", code)
+		}
+		if !isRetryable(&googleapi.Error{Code: httpStatus}) {
+			t.Errorf("HTTP status %d is not retryable", httpStatus)
+		}
+	}
+}
+
+func TestIsCapabilityError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"blocked", &genai.BlockedError{}, true},
+		{"retryable", status.Error(codes.Unavailable, ""), true},
+		{"overloaded message", errors.New("googleapi: Error 503: The model is overloaded"), true},
+		{"bad request", &googleapi.Error{Code: http.StatusBadRequest, Message: "invalid"}, false},
+		{"plain error", errors.New("something failed"), false},
+	}
+	for _, tt := range tests {
+		if got := isCapabilityError(tt.err); got != tt.want {
+			t.Errorf("isCapabilityError(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
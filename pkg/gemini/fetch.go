@@ -0,0 +1,132 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// Defaults for fetch_url when the config leaves the limits unset
+const (
+	defaultFetchMaxBytes = 1024 * 1024
+	defaultFetchTimeout  = 15 * time.Second
+)
+
+// fetchURL downloads a text document from an allowlisted domain. Responses
+// larger than the configured cap and binary content are rejected.
+func (e *ToolExecutor) fetchURL(fc genai.FunctionCall) (string, error) {
+	rawURL, ok := fc.Args["url"].(string)
+	if !ok || rawURL == "" {
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'url' argument")
+	}
+	target, err := e.checkFetchURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	maxBytes := e.config.Agent.FetchMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultFetchMaxBytes
+	}
+	timeout := defaultFetchTimeout
+	if e.config.Agent.FetchTimeoutSeconds > 0 {
+		timeout = time.Duration(e.config.Agent.FetchTimeoutSeconds) * time.Second
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		// Every hop of a redirect must stay on the allowlist
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("stopped after %d redirects", len(via))
+			}
+			_, err := e.checkFetchURL(req.URL.String())
+			return err
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "console-buddy")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("failed to fetch %s: %s", target, resp.Status)
+	}
+	if resp.ContentLength > int64(maxBytes) {
+		return "", fmt.Errorf("%s is %d bytes, over the %d byte limit", target, resp.ContentLength, maxBytes)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !isTextContentType(contentType) {
+		return "", fmt.Errorf("%s is %s, only text content can be fetched", target, contentType)
+	}
+
+	// Read one byte past the cap to tell a body of exactly maxBytes from a larger one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBytes)+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	if len(body) > maxBytes {
+		return "", fmt.Errorf("%s is larger than the %d byte limit", target, maxBytes)
+	}
+	if bytes.IndexByte(body[:min(len(body), binarySniffSize)], 0) >= 0 {
+		return "", fmt.Errorf("%s looks like binary content, only text can be fetched", target)
+	}
+
+	e.log.Info("Fetched %s (%d bytes)", target, len(body))
+	return fmt.Sprintf("[%s, %d bytes]\n%s", target, len(body), body), nil
+}
+
+// checkFetchURL parses rawURL and checks it against the scheme and domain
+// allowlist. An empty allowlist blocks every domain.
+func (e *ToolExecutor) checkFetchURL(rawURL string) (*url.URL, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, toolErrorf(ToolErrorValidation, "invalid URL %q: %w", rawURL, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, toolErrorf(ToolErrorValidation, "unsupported URL scheme %q, use http or https", target.Scheme)
+	}
+
+	host := strings.ToLower(target.Hostname())
+	for _, domain := range e.config.Agent.FetchAllowedDomains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return target, nil
+		}
+	}
+	return nil, toolErrorf(ToolErrorBlocked, "domain %q is not in the fetch allowlist; ask the user to add it to CONSOLE_AI_FETCH_ALLOWED_DOMAINS", host)
+}
+
+// isTextContentType reports whether a Content-Type header describes text
+func isTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"json", "xml", "yaml", "javascript", "toml"} {
+		if strings.HasSuffix(mediaType, "/"+suffix) || strings.HasSuffix(mediaType, "+"+suffix) || strings.HasSuffix(mediaType, "/x-"+suffix) {
+			return true
+		}
+	}
+	return false
+}
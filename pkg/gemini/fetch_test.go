@@ -0,0 +1,125 @@
+package gemini
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFetchServer serves the documents used by the fetch tests
+func newFetchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/spec.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte("# API spec\n"))
+	})
+	mux.HandleFunc("/big.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("x", 2048)))
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com/elsewhere", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newFetchExecutor returns an executor allowed to fetch from the test server
+func newFetchExecutor(t *testing.T) *ToolExecutor {
+	t.Helper()
+	e, _ := newTestExecutor(t)
+	e.config.Agent.FetchEnabled = true
+	e.config.Agent.FetchAllowedDomains = []string{"127.0.0.1"}
+	e.config.Agent.FetchMaxBytes = 1024
+	return e
+}
+
+func TestFetchURL(t *testing.T) {
+	server := newFetchServer(t)
+	e := newFetchExecutor(t)
+
+	out, err := call(e, "fetch_url", map[string]any{"url": server.URL + "/spec.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out, "11 bytes]\n# API spec\n") {
+		t.Errorf("fetch result = %q", out)
+	}
+}
+
+func TestFetchURLOversize(t *testing.T) {
+	server := newFetchServer(t)
+	e := newFetchExecutor(t)
+
+	_, err := call(e, "fetch_url", map[string]any{"url": server.URL + "/big.txt"})
+	if err == nil || !strings.Contains(err.Error(), "1024 byte limit") {
+		t.Errorf("oversize fetch = %v, want the size limit error", err)
+	}
+}
+
+func TestFetchURLBinary(t *testing.T) {
+	server := newFetchServer(t)
+	e := newFetchExecutor(t)
+
+	_, err := call(e, "fetch_url", map[string]any{"url": server.URL + "/image.png"})
+	if err == nil || !strings.Contains(err.Error(), "only text content") {
+		t.Errorf("binary fetch = %v, want it rejected", err)
+	}
+}
+
+func TestFetchURLBlockedDomain(t *testing.T) {
+	server := newFetchServer(t)
+	e := newFetchExecutor(t)
+	e.config.Agent.FetchAllowedDomains = []string{"raw.githubusercontent.com"}
+
+	_, err := call(e, "fetch_url", map[string]any{"url": server.URL + "/spec.md"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorBlocked {
+		t.Errorf("fetch from an unlisted domain = %v, want it blocked", err)
+	}
+}
+
+func TestFetchURLRedirectOffAllowlist(t *testing.T) {
+	server := newFetchServer(t)
+	e := newFetchExecutor(t)
+
+	_, err := call(e, "fetch_url", map[string]any{"url": server.URL + "/redirect"})
+	if err == nil || !strings.Contains(err.Error(), "not in the fetch allowlist") {
+		t.Errorf("redirect off the allowlist = %v, want it blocked", err)
+	}
+}
+
+func TestFetchURLDisabledByDefault(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if e.config.Agent.FetchEnabled {
+		t.Fatal("fetch_url is enabled by default")
+	}
+	if _, err := call(e, "fetch_url", map[string]any{"url": "https://example.com/"}); err == nil {
+		t.Error("fetch_url ran while disabled")
+	}
+	if containsString(toolNames(filterTools(defineTools(), e.config.Agent)), "fetch_url") {
+		t.Error("fetch_url is advertised while disabled")
+	}
+}
+
+func TestAllowedSubdomain(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.Agent.FetchAllowedDomains = []string{"githubusercontent.com"}
+	if _, err := e.checkFetchURL("https://raw.githubusercontent.com/a/b"); err != nil {
+		t.Errorf("subdomain of an allowed domain was blocked: %v", err)
+	}
+	if _, err := e.checkFetchURL("https://evilgithubusercontent.com/a"); err == nil {
+		t.Error("a lookalike domain was allowed")
+	}
+	if _, err := e.checkFetchURL("ftp://raw.githubusercontent.com/a"); err == nil {
+		t.Error("an ftp URL was allowed")
+	}
+}
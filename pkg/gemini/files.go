@@ -0,0 +1,183 @@
+package gemini
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// readFile returns a file's content, or only the lines between start_line
+// and end_line (1-based, inclusive) when either is given, so large files can
+// be paged through without filling the context window.
+func (e *ToolExecutor) readFile(fc genai.FunctionCall) (string, error) {
+	path, ok := fc.Args["path"].(string)
+	if !ok {
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'path' argument")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	start, hasStart := intArg(fc.Args, "start_line")
+	end, hasEnd := intArg(fc.Args, "end_line")
+	if !hasStart && !hasEnd {
+		return string(content), nil
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	total := len(lines)
+
+	if !hasStart || start < 1 {
+		start = 1
+	}
+	if !hasEnd || end > total {
+		end = total
+	}
+	if start > total {
+		return fmt.Sprintf("[%s has %d lines; start_line %d is past the end]", path, total, start), nil
+	}
+	if end < start {
+		return "", toolErrorf(ToolErrorValidation, "end_line %d is before start_line %d", end, start)
+	}
+
+	return fmt.Sprintf("[lines %d-%d of %d in %s]\n%s", start, end, total, path, strings.Join(lines[start-1:end], "")), nil
+}
+
+// intArg reads an integer tool argument. Function call arguments are decoded
+// from JSON, so numbers usually arrive as float64.
+func intArg(args map[string]interface{}, name string) (int, bool) {
+	switch v := args[name].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// binarySniffSize is how much of a file statFile inspects for null bytes
+const binarySniffSize = 8000
+
+// statFile describes a file's size, permissions, modification time and type
+// without reading its whole content.
+func statFile(path string) (string, error) {
+	linfo, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	isSymlink := linfo.Mode()&os.ModeSymlink != 0
+
+	info := linfo
+	if isSymlink {
+		if info, err = os.Stat(path); err != nil {
+			return "", fmt.Errorf("broken symlink '%s': %w", path, err)
+		}
+	}
+
+	binary := false
+	if info.Mode().IsRegular() {
+		if binary, err = isBinaryFile(path); err != nil {
+			return "", err
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Path: %s\n", path))
+	builder.WriteString(fmt.Sprintf("Size: %d bytes\n", info.Size()))
+	builder.WriteString(fmt.Sprintf("Permissions: %s\n", info.Mode().Perm()))
+	builder.WriteString(fmt.Sprintf("Modified: %s\n", info.ModTime().Format(time.RFC3339)))
+	builder.WriteString(fmt.Sprintf("Directory: %t\n", info.IsDir()))
+	builder.WriteString(fmt.Sprintf("Symlink: %t\n", isSymlink))
+	builder.WriteString(fmt.Sprintf("Binary: %t", binary))
+	return builder.String(), nil
+}
+
+// isBinaryFile reports whether the start of a file contains a null byte
+func isBinaryFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, binarySniffSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
+
+// copyFile copies source to destination byte for byte, keeping the source's
+// permissions. An existing destination is only replaced when overwrite is set.
+func (e *ToolExecutor) copyFile(fc genai.FunctionCall) (string, error) {
+	source, ok1 := fc.Args["source"].(string)
+	destination, ok2 := fc.Args["destination"].(string)
+	if !ok1 || !ok2 {
+		return "", toolErrorf(ToolErrorValidation, "missing required arguments for copy_file")
+	}
+	overwrite, _ := fc.Args["overwrite"].(bool)
+
+	target, err := e.confinePath(destination)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", toolErrorf(ToolErrorValidation, "'%s' is a directory; copy_file only copies files", source)
+	}
+	if dstInfo, err := os.Stat(target); err == nil {
+		// truncating the destination would empty the source before copying
+		if os.SameFile(info, dstInfo) {
+			return "", toolErrorf(ToolErrorValidation, "'%s' and '%s' are the same file", source, destination)
+		}
+		if !overwrite {
+			return "", toolErrorf(ToolErrorValidation, "'%s' already exists; pass overwrite to replace it", destination)
+		}
+	}
+
+	if e.config.Agent.DryRun {
+		return dryRunResult("would copy '%s' to '%s' (%d bytes)", source, destination, info.Size()), nil
+	}
+	e.snapshotFile(target)
+
+	in, err := os.Open(source)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+	written, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", source, err)
+	}
+	// OpenFile only applies the mode to new files
+	if err := os.Chmod(target, info.Mode().Perm()); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Copied '%s' to '%s' (%d bytes).", source, destination, written), nil
+}
@@ -0,0 +1,186 @@
+package gemini
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// writeLines writes a file with lines "line 1" to "line n"
+func writeLines(t *testing.T, path string, n int) {
+	t.Helper()
+	var builder strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&builder, "line %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(builder.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadFileWindow(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	writeLines(t, "app.log", 100)
+
+	out, err := call(e, "read_file", map[string]any{"path": "app.log", "start_line": float64(40), "end_line": float64(42)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[lines 40-42 of 100 in app.log]\nline 40\nline 41\nline 42\n"; out != want {
+		t.Errorf("middle window = %q, want %q", out, want)
+	}
+}
+
+func TestReadFileTail(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	writeLines(t, "app.log", 100)
+
+	out, err := call(e, "read_file", map[string]any{"path": "app.log", "start_line": float64(98)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[lines 98-100 of 100 in app.log]\nline 98\nline 99\nline 100\n"; out != want {
+		t.Errorf("tail = %q, want %q", out, want)
+	}
+
+	out, err = call(e, "read_file", map[string]any{"path": "app.log", "start_line": float64(150)})
+	if err != nil || !strings.Contains(out, "has 100 lines") {
+		t.Errorf("read past the end = %q, %v", out, err)
+	}
+	if _, err := call(e, "read_file", map[string]any{"path": "app.log", "start_line": float64(10), "end_line": float64(5)}); err == nil {
+		t.Error("an inverted window was accepted")
+	}
+}
+
+func TestReadFileWhole(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	writeLines(t, "short.txt", 2)
+
+	if out, err := call(e, "read_file", map[string]any{"path": "short.txt"}); err != nil || out != "line 1\nline 2\n" {
+		t.Errorf("read_file = %q, %v", out, err)
+	}
+}
+
+func TestStatFile(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("notes.txt", []byte("hello\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir("assets", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("assets/logo.png", []byte{0x89, 'P', 'N', 'G', 0, 0, 0, 0x0d}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("notes.txt", "link.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"notes.txt", []string{"Size: 6 bytes", "Permissions: -rw-r-----", "Directory: false", "Symlink: false", "Binary: false"}},
+		{"assets", []string{"Directory: true", "Binary: false"}},
+		{"assets/logo.png", []string{"Size: 8 bytes", "Directory: false", "Binary: true"}},
+		{"link.txt", []string{"Size: 6 bytes", "Symlink: true", "Binary: false"}},
+	}
+	for _, tt := range tests {
+		out, err := call(e, "stat_file", map[string]any{"path": tt.path})
+		if err != nil {
+			t.Errorf("stat_file(%s): %v", tt.path, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("stat_file(%s) is missing %q:\n%s", tt.path, want, out)
+			}
+		}
+		if !strings.Contains(out, "Modified: ") {
+			t.Errorf("stat_file(%s) has no modification time:\n%s", tt.path, out)
+		}
+	}
+
+	if _, err := call(e, "stat_file", map[string]any{"path": "missing.txt"}); err == nil {
+		t.Error("stat_file of a missing file succeeded")
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("src.txt", []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "copy_file", map[string]any{"source": "src.txt", "destination": "dst.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile("dst.txt"); err != nil || string(data) != "hello\n" {
+		t.Errorf("copy = %q, %v; want %q", data, err, "hello\n")
+	}
+}
+
+func TestCopyFileKeepsMode(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("run.sh", []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod("run.sh", 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "copy_file", map[string]any{"source": "run.sh", "destination": "copy.sh"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat("copy.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("copy mode = %v, want %v", info.Mode().Perm(), os.FileMode(0750))
+	}
+}
+
+func TestCopyFileOverwriteGuard(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("src.txt", []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("dst.txt", []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "copy_file", map[string]any{"source": "src.txt", "destination": "dst.txt"}); err == nil {
+		t.Error("copy replaced an existing file without overwrite")
+	}
+	if data, _ := os.ReadFile("dst.txt"); string(data) != "old" {
+		t.Errorf("destination = %q after a refused copy, want %q", data, "old")
+	}
+
+	if _, err := call(e, "copy_file", map[string]any{"source": "src.txt", "destination": "dst.txt", "overwrite": true}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile("dst.txt"); string(data) != "new" {
+		t.Errorf("destination = %q after overwrite, want %q", data, "new")
+	}
+}
+
+func TestCopyFileOntoItself(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("src.txt", []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link("src.txt", "alias.txt"); err != nil {
+		t.Skipf("hard links unsupported: %v", err)
+	}
+
+	for _, dst := range []string{"src.txt", "./src.txt", "alias.txt"} {
+		if _, err := call(e, "copy_file", map[string]any{"source": "src.txt", "destination": dst, "overwrite": true}); err == nil {
+			t.Errorf("copying onto %s succeeded", dst)
+		}
+	}
+	if data, _ := os.ReadFile("src.txt"); string(data) != "keep me" {
+		t.Errorf("source = %q after copying onto itself, want %q", data, "keep me")
+	}
+}
@@ -2,61 +2,194 @@ package gemini
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"console-ai/pkg/agent"
 	"console-ai/pkg/config"
+	"console-ai/pkg/history"
+	"console-ai/pkg/logger"
 
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/iterator"
 )
 
 const (
-	// maxLoopIterations sets a hard limit on the number of tool-call cycles
-	// to prevent infinite loops.
+	// maxLoopIterations is the default limit on the number of tool-call cycles
+	// in one turn, preventing infinite loops. Config.Agent.MaxToolIterations overrides it.
 	maxLoopIterations = 15
 
-	// conversationTimeout is the maximum duration for the entire conversation flow.
-	conversationTimeout = 2 * time.Minute
+	// defaultConversationTimeout is the maximum duration for the entire
+	// conversation flow. Config.Agent.ConversationTimeoutSeconds overrides it.
+	defaultConversationTimeout = 2 * time.Minute
+
+	// fallbackBackoff is the wait before the first fallback model is tried;
+	// it doubles for each further fallback.
+	fallbackBackoff = time.Second
+
+	// maxProjectContextChars bounds the project summary added to the system prompt.
+	maxProjectContextChars = 1500
+
+	// maxSystemPromptExtraChars bounds the team instructions added to the system prompt.
+	maxSystemPromptExtraChars = 8000
+)
+
+// Titles of the steps reported through the stepCallback of ContinueConversation
+const (
+	StepThinking     = "Thinking..."
+	StepResponse     = "Response"
+	StepToolCall     = "Tool Call"
+	StepToolError    = "Tool Error"
+	StepToolOutput   = "Tool Output"
+	StepLimitReached = "Limit Reached"
+	StepFallback     = "Model Fallback"
+	StepRateLimit    = "Rate Limit"
+	// StepTransformed carries the whole final reply when a ResponseTransformer
+	// changed it, so a UI that showed the streamed text can replace it
+	StepTransformed = "Transformed Reply"
 )
 
 // ContinueConversation handles the core logic of the AI's turn-based conversation.
 // It sends the user's input to the Gemini model, processes tool calls, and streams
-// the final text response back to the user interface.
-func ContinueConversation(model *genai.GenerativeModel, history []string, input string, humorLevel int, cfg *config.Config, stepCallback func(title, content string)) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), conversationTimeout)
+// the final text response back to the user interface, along with the tool
+// actions taken during the turn. Cancelling ctx aborts the request once the
+// current tool call has finished.
+//
+// When the model fails with a capability error before producing any output,
+// the turn is retried on each of cfg.FallbackModels in order.
+func ContinueConversation(ctx context.Context, model *genai.GenerativeModel, conversationHistory []string, projectInfo *agent.ProjectInfo, input string, humorLevel int, cfg *config.Config, stepCallback func(title, content string)) (string, []history.ToolAction, error) {
+	timeout := conversationTimeout(cfg)
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cs := model.StartChat()
-	cs.History = buildHistory(history)
+	sessionTranscript.write(cfg, "User", input)
+	stepCallback = sessionTranscript.wrap(cfg, stepCallback)
 
-	if len(history) == 0 {
-		toolDefinitions := generateToolDefinitions()
-		dynamicPrompt := fmt.Sprintf(systemPrompt, toolDefinitions)
-		dynamicPrompt += fmt.Sprintf("\n\nHumor Level: %d%%", humorLevel)
-		model.SystemInstruction = &genai.Content{Parts: []genai.Part{genai.Text(dynamicPrompt)}}
+	reply, actions, started, err := runTurn(ctx, model, conversationHistory, projectInfo, input, humorLevel, cfg, stepCallback)
+
+	current := cfg.ModelName
+	backoff := fallbackBackoff
+	for _, name := range cfg.FallbackModels {
+		if err == nil || started || !isCapabilityError(err) {
+			break
+		}
+		if name == current {
+			continue
+		}
+
+		logger.Warn("Model %s failed, falling back to %s: %v", current, name, err)
+		stepCallback(StepFallback, fmt.Sprintf("%s failed (%v); retrying with %s", current, err, name))
+
+		select {
+		case <-ctx.Done():
+			return "", actions, fmt.Errorf("stream error: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		fallback, client, clientErr := NewClient(cfg, name)
+		if clientErr != nil {
+			return "", actions, clientErr
+		}
+		current = name
+		reply, actions, started, err = runTurn(ctx, fallback, conversationHistory, projectInfo, input, humorLevel, cfg, stepCallback)
+		client.Close()
+		if err == nil {
+			stepCallback(StepFallback, fmt.Sprintf("Answered by %s", name))
+		}
+	}
+	// Only our own deadline gets the hint; a caller's cancellation is passed on as is
+	if errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil {
+		err = fmt.Errorf("the turn timed out after %s; raise the limit with CONSOLE_AI_CONVERSATION_TIMEOUT (seconds) for long-running builds or tests: %w", timeout, err)
 	}
+	if err == nil {
+		if transformed := transformResponse(reply, humorLevel); transformed != reply {
+			reply = transformed
+			stepCallback(StepTransformed, reply)
+		}
+		sessionTranscript.write(cfg, "Model", reply)
+	} else {
+		sessionTranscript.write(cfg, "Error", err.Error())
+	}
+	return reply, actions, err
+}
 
-	stepCallback("Thinking...", "")
+// responseStream yields the streamed responses to one message
+type responseStream interface {
+	Next() (*genai.GenerateContentResponse, error)
+}
+
+// sendMessageStream sends parts to model in the chat session cs. Tests
+// replace it to script the model's replies.
+var sendMessageStream = func(ctx context.Context, model *genai.GenerativeModel, cs *genai.ChatSession, parts ...genai.Part) responseStream {
+	return cs.SendMessageStream(ctx, parts...)
+}
 
-	iter := cs.SendMessageStream(ctx, genai.Text(input))
+// errNoTools answers tool calls made despite advisory mode
+var errNoTools error = &ToolError{Kind: ToolErrorBlocked, Err: errors.New("tools are disabled in advisory mode; answer from the conversation and project context instead")}
+
+// conversationTimeout returns the time limit for a whole turn
+func conversationTimeout(cfg *config.Config) time.Duration {
+	if cfg.Agent.ConversationTimeoutSeconds > 0 {
+		return time.Duration(cfg.Agent.ConversationTimeoutSeconds) * time.Second
+	}
+	return defaultConversationTimeout
+}
+
+// runTurn runs one conversation turn on model. started reports whether any
+// text was streamed or any tool was called before an error, in which case
+// the turn can't safely be retried on another model.
+func runTurn(ctx context.Context, model *genai.GenerativeModel, conversationHistory []string, projectInfo *agent.ProjectInfo, input string, humorLevel int, cfg *config.Config, stepCallback func(title, content string)) (reply string, actions []history.ToolAction, started bool, err error) {
+	cs := model.StartChat()
+	cs.History = buildHistory(conversationHistory)
+
+	// Refresh the system instruction every turn so humor or context changes
+	// made mid-session reach the model
+	ApplySystemInstruction(model, cfg, humorLevel, projectInfo)
+	sessionTranscript.systemInstruction(cfg, model)
+
+	turn := newDebugTurn(model, cs.History)
+	defer setLastTurn(turn)
+
+	stepCallback(StepThinking, "")
+
+	if err := waitForRateLimit(ctx, cfg.RequestsPerMinute, stepCallback); err != nil {
+		return "", nil, false, fmt.Errorf("stream error: %w", err)
+	}
+	turn.recordRequest(genai.Text(input))
+	iter := sendMessageStream(ctx, model, cs, genai.Text(input))
 
 	var responseBuilder strings.Builder
-	var lastTextChunk string
 	var hasResponded bool
 
-	toolExecutor := NewToolExecutor(cfg)
+	// In advisory mode the model has no tools, so no executor is needed
+	var toolExecutor *ToolExecutor
+	if !cfg.Agent.NoTools {
+		toolExecutor = NewToolExecutor(cfg)
+		toolExecutor.projectInfo = projectInfo
+	}
 
-	for i := 0; i < maxLoopIterations; i++ {
+	maxIterations := cfg.Agent.MaxToolIterations
+	if maxIterations <= 0 {
+		maxIterations = maxLoopIterations
+	}
+	toolCycles := 0
+	limitReached := false
+
+	// Only tool-call cycles count towards the limit; streamed text chunks don't
+stream:
+	for {
 		resp, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("stream error: %w", err)
+			return "", actions, hasResponded || len(actions) > 0, fmt.Errorf("stream error: %w", err)
 		}
+		turn.recordResponse(resp)
 
 		if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
 			continue
@@ -65,37 +198,130 @@ func ContinueConversation(model *genai.GenerativeModel, history []string, input
 		for _, part := range resp.Candidates[0].Content.Parts {
 			switch p := part.(type) {
 			case genai.Text:
+				// Stream exactly what is appended so the UI matches the final reply,
+				// even when the model legitimately repeats a chunk
 				textChunk := string(p)
 				responseBuilder.WriteString(textChunk)
-				if textChunk != lastTextChunk {
-					stepCallback("Response", textChunk)
-					lastTextChunk = textChunk
-				}
+				stepCallback(StepResponse, textChunk)
 				hasResponded = true
 
 			case genai.FunctionCall:
+				if toolCycles >= maxIterations {
+					limitReached = true
+					break stream
+				}
+				toolCycles++
+
 				// Construct a more detailed message including function name and arguments
-				argsJson, _ := json.Marshal(p.Args) // Safely marshal args to JSON
-				stepCallback("Tool Call", fmt.Sprintf("\nExecuting: %s with args: %s", p.Name, string(argsJson)))
-				output, err := toolExecutor.Execute(p)
+				argsJSON := displayArgs(p.Args)
+				stepCallback(StepToolCall, fmt.Sprintf("%s with args: %s", p.Name, argsJSON))
+				output, err := "", errNoTools
+				if toolExecutor != nil {
+					output, err = toolExecutor.Execute(p)
+				}
+				actions = append(actions, history.NewToolAction(p.Name, argsJSON, output, err))
 				if err != nil {
-					stepCallback("Tool Error", err.Error())
+					stepCallback(StepToolError, toolErrorStep(err))
 				}
-				stepCallback("Tool Output", output)
+				stepCallback(StepToolOutput, output)
 
-				iter = cs.SendMessageStream(ctx, genai.FunctionResponse{
+				response := map[string]interface{}{"output": output}
+				if err != nil {
+					// Tell the model why the call failed, not just what it printed
+					response["error"] = err.Error()
+					response["kind"] = toolErrorKind(err).String()
+				}
+				funcResponse := genai.FunctionResponse{
 					Name:     p.Name,
-					Response: map[string]interface{}{"output": output},
-				})
+					Response: response,
+				}
+				if err := waitForRateLimit(ctx, cfg.RequestsPerMinute, stepCallback); err != nil {
+					return "", actions, true, fmt.Errorf("stream error: %w", err)
+				}
+				turn.recordRequest(funcResponse)
+				iter = sendMessageStream(ctx, model, cs, funcResponse)
 			}
 		}
 	}
+	if limitReached {
+		note := fmt.Sprintf("Stopped after %d tool calls without a final answer. Ask me to continue, or raise the limit with CONSOLE_AI_MAX_TOOL_ITERATIONS.", maxIterations)
+		logger.Warn("Tool call limit of %d reached", maxIterations)
+		stepCallback(StepLimitReached, note)
+		if !hasResponded {
+			return note, actions, true, nil
+		}
+		return responseBuilder.String() + "\n\n" + note, actions, true, nil
+	}
+
 	// If the model finishes without generating a text response, provide a default message.
 	if !hasResponded {
-		return "The model finished its work without providing a direct response.", nil
+		return "The model finished its work without providing a direct response.", actions, true, nil
+	}
+
+	return responseBuilder.String(), actions, true, nil
+}
+
+// systemInstruction builds the system prompt for the current session state,
+// listing the tools the model has been given followed by the team
+// instructions from cfg.SystemPromptExtra
+func systemInstruction(tools []*genai.Tool, cfg *config.Config, humorLevel int, projectInfo *agent.ProjectInfo) string {
+	dynamicPrompt := fmt.Sprintf(systemPrompt, generateToolDefinitions(tools))
+	if extra := systemPromptExtra(cfg); extra != "" {
+		dynamicPrompt += "\n\n**Team Instructions:**\n" + extra
+	}
+	if summary := projectContext(projectInfo); summary != "" {
+		dynamicPrompt += "\n\n" + summary
 	}
+	if tone := toneInstruction(cfg); tone != "" {
+		dynamicPrompt += "\n\n**Tone:** " + tone
+	}
+	dynamicPrompt += fmt.Sprintf("\n\nHumor Level: %d%%", humorLevel)
+	return dynamicPrompt
+}
+
+// systemPromptExtra returns the configured team instructions, truncated to
+// maxSystemPromptExtraChars
+func systemPromptExtra(cfg *config.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	extra := strings.TrimSpace(cfg.SystemPromptExtra)
+	if len(extra) > maxSystemPromptExtraChars {
+		extra = strings.ToValidUTF8(extra[:maxSystemPromptExtraChars], "") + "..."
+	}
+	return extra
+}
 
-	return responseBuilder.String(), nil
+// projectContext summarizes the known project so the model doesn't need to
+// call analyze_project to rediscover it. The file list is left out.
+func projectContext(info *agent.ProjectInfo) string {
+	if info == nil || info.Language == "" {
+		return ""
+	}
+
+	summary := "**Current Project (already analyzed):**\n" + info.Summary()
+	if len(summary) > maxProjectContextChars {
+		summary = strings.ToValidUTF8(summary[:maxProjectContextChars], "") + "..."
+	}
+	return summary
+}
+
+// ApplySystemInstruction sets the system prompt, including the tool
+// definitions, team instructions, project summary and humor level, on the
+// model. The model is left untouched when the prompt has not changed.
+func ApplySystemInstruction(model *genai.GenerativeModel, cfg *config.Config, humorLevel int, projectInfo *agent.ProjectInfo) {
+	prompt := genai.Text(systemInstruction(model.Tools, cfg, humorLevel, projectInfo))
+	if current := model.SystemInstruction; current != nil && len(current.Parts) == 1 && current.Parts[0] == prompt {
+		return
+	}
+	model.SystemInstruction = &genai.Content{Parts: []genai.Part{prompt}}
+	logger.Debug("System instruction updated (humor level %d%%)", humorLevel)
+	if extra := systemPromptExtra(cfg); extra != "" {
+		if len(strings.TrimSpace(cfg.SystemPromptExtra)) > maxSystemPromptExtraChars {
+			logger.Warn("Extra system prompt truncated to %d characters", maxSystemPromptExtraChars)
+		}
+		logger.Info("Applied %d characters of extra system prompt", len(extra))
+	}
 }
 
 // buildHistory reconstructs the conversation history from a simple string slice.
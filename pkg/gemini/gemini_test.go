@@ -0,0 +1,426 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/config"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+)
+
+// fakeReply is a scripted answer to one message: each part is streamed as
+// its own chunk, followed by err if set. A set delay holds back the first
+// chunk until it passes or the request is cancelled.
+type fakeReply struct {
+	parts []genai.Part
+	err   error
+	delay time.Duration
+}
+
+// reply returns a fakeReply streaming parts
+func reply(parts ...genai.Part) fakeReply {
+	return fakeReply{parts: parts}
+}
+
+// fakeRequest is a message sent to the fake model
+type fakeRequest struct {
+	model  *genai.GenerativeModel
+	system string
+	parts  []genai.Part
+}
+
+// fakeGemini answers each message with the next scripted reply, repeating
+// the last one when they run out
+type fakeGemini struct {
+	mu       sync.Mutex
+	replies  []fakeReply
+	requests []fakeRequest
+}
+
+// fakeStream streams a fakeReply
+type fakeStream struct {
+	ctx   context.Context
+	reply fakeReply
+}
+
+func (s *fakeStream) Next() (*genai.GenerateContentResponse, error) {
+	if s.reply.delay > 0 {
+		select {
+		case <-s.ctx.Done():
+		case <-time.After(s.reply.delay):
+		}
+		s.reply.delay = 0
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.reply.parts) == 0 {
+		if s.reply.err != nil {
+			return nil, s.reply.err
+		}
+		return nil, iterator.Done
+	}
+	part := s.reply.parts[0]
+	s.reply.parts = s.reply.parts[1:]
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Role: "model", Parts: []genai.Part{part}}}},
+	}, nil
+}
+
+// useFakeGemini routes messages sent during the test to a fake model
+// answering with replies
+func useFakeGemini(t *testing.T, replies ...fakeReply) *fakeGemini {
+	t.Helper()
+	fake := &fakeGemini{replies: replies}
+	previous := sendMessageStream
+	sendMessageStream = fake.send
+	t.Cleanup(func() { sendMessageStream = previous })
+	return fake
+}
+
+func (f *fakeGemini) send(ctx context.Context, model *genai.GenerativeModel, cs *genai.ChatSession, parts ...genai.Part) responseStream {
+	var system string
+	if model.SystemInstruction != nil {
+		for _, part := range model.SystemInstruction.Parts {
+			if text, ok := part.(genai.Text); ok {
+				system += string(text)
+			}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, fakeRequest{model: model, system: system, parts: parts})
+
+	r := fakeReply{}
+	if len(f.replies) > 0 {
+		r = f.replies[len(f.replies)-1]
+		if n := len(f.requests); n <= len(f.replies) {
+			r = f.replies[n-1]
+		}
+	}
+	return &fakeStream{ctx: ctx, reply: r}
+}
+
+// turnConfig returns a configuration for conversation tests rooted in a
+// temporary directory
+func turnConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg := testConfig(t)
+	cfg.ProjectRoot = t.TempDir()
+	t.Chdir(cfg.ProjectRoot)
+	return cfg
+}
+
+// newTestModel returns a model with the tools enabled by cfg
+func newTestModel(cfg *config.Config) *genai.GenerativeModel {
+	return &genai.GenerativeModel{Tools: filterTools(defineTools(), cfg.Agent)}
+}
+
+// noSteps ignores step callbacks
+func noSteps(title, content string) {}
+
+func TestHumorChangeUpdatesSystemInstruction(t *testing.T) {
+	fake := useFakeGemini(t, reply(genai.Text("Hello.")))
+	cfg := turnConfig(t)
+	model := newTestModel(cfg)
+
+	var conversation []string
+	for _, humor := range []int{10, 90} {
+		answer, _, err := ContinueConversation(context.Background(), model, conversation, nil, "hi", humor, cfg, noSteps)
+		if err != nil {
+			t.Fatalf("ContinueConversation: %v", err)
+		}
+		conversation = append(conversation, "hi", answer)
+	}
+
+	if len(fake.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(fake.requests))
+	}
+	first, second := fake.requests[0].system, fake.requests[1].system
+	if !strings.Contains(first, "Humor Level: 10%") {
+		t.Errorf("first instruction has no humor level 10%%:\n%s", first)
+	}
+	if !strings.Contains(second, "Humor Level: 90%") {
+		t.Errorf("second instruction has no humor level 90%%:\n%s", second)
+	}
+	if strings.Count(second, "read_file") != strings.Count(first, "read_file") {
+		t.Error("tool definitions were duplicated in the refreshed instruction")
+	}
+}
+
+func TestSystemInstructionIncludesProjectContext(t *testing.T) {
+	cfg := turnConfig(t)
+	model := newTestModel(cfg)
+	info := &agent.ProjectInfo{
+		Language:     "Go",
+		Framework:    "Gin",
+		Dependencies: []string{"github.com/gin-gonic/gin"},
+		Files:        []string{"main.go", "secret_internal_file.go"},
+	}
+
+	ApplySystemInstruction(model, cfg, 0, info)
+	prompt := string(model.SystemInstruction.Parts[0].(genai.Text))
+	for _, want := range []string{"Current Project (already analyzed)", "- Language: Go", "- Framework: Gin", "github.com/gin-gonic/gin"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("instruction is missing %q", want)
+		}
+	}
+	if strings.Contains(prompt, "secret_internal_file.go") {
+		t.Error("instruction lists the project files")
+	}
+
+	ApplySystemInstruction(model, cfg, 0, nil)
+	if prompt := string(model.SystemInstruction.Parts[0].(genai.Text)); strings.Contains(prompt, "Current Project") {
+		t.Error("instruction has a project summary without project info")
+	}
+}
+
+func TestProjectContextIsBounded(t *testing.T) {
+	info := &agent.ProjectInfo{Language: "Go"}
+	for i := 0; i < 500; i++ {
+		info.Dependencies = append(info.Dependencies, strings.Repeat("d", 40))
+	}
+	if summary := projectContext(info); len(summary) > maxProjectContextChars+len("...") {
+		t.Errorf("project context is %d characters, want at most %d", len(summary), maxProjectContextChars)
+	}
+}
+
+func TestSystemPromptExtraIsApplied(t *testing.T) {
+	fake := useFakeGemini(t, reply(genai.Text("Hello.")))
+	cfg := turnConfig(t)
+	cfg.SystemPromptExtra = "Always use tabs for indentation."
+
+	if _, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "hi", 0, cfg, noSteps); err != nil {
+		t.Fatalf("ContinueConversation: %v", err)
+	}
+
+	system := fake.requests[0].system
+	extra := strings.Index(system, "**Team Instructions:**\nAlways use tabs for indentation.")
+	if extra < 0 {
+		t.Fatalf("instruction does not contain the team instructions:\n%s", system)
+	}
+	if tools := strings.LastIndex(system, "read_file"); tools > extra {
+		t.Error("team instructions come before the tool definitions")
+	}
+}
+
+func TestSystemPromptExtraIsBounded(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.SystemPromptExtra = strings.Repeat("x", 2*maxSystemPromptExtraChars)
+	if extra := systemPromptExtra(cfg); len(extra) != maxSystemPromptExtraChars+len("...") {
+		t.Errorf("team instructions are %d characters, want %d", len(extra), maxSystemPromptExtraChars)
+	}
+
+	cfg.SystemPromptExtra = "  "
+	if prompt := systemInstruction(nil, cfg, 0, nil); strings.Contains(prompt, "Team Instructions") {
+		t.Error("instruction has a team instructions section without any")
+	}
+}
+
+func TestRepeatedChunksAreStreamed(t *testing.T) {
+	useFakeGemini(t, reply(genai.Text("ha"), genai.Text("ha"), genai.Text("ha"), genai.Text("!")))
+	cfg := turnConfig(t)
+
+	var streamed strings.Builder
+	answer, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "laugh", 0, cfg, func(title, content string) {
+		if title == StepResponse {
+			streamed.WriteString(content)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ContinueConversation: %v", err)
+	}
+	if answer != "hahaha!" {
+		t.Errorf("reply = %q, want %q", answer, "hahaha!")
+	}
+	if streamed.String() != answer {
+		t.Errorf("streamed %q, but the reply is %q", streamed.String(), answer)
+	}
+}
+
+func TestToolLoopLimit(t *testing.T) {
+	fake := useFakeGemini(t, reply(genai.FunctionCall{Name: "list_files", Args: map[string]interface{}{"path": "."}}))
+	cfg := turnConfig(t)
+	cfg.Agent.MaxToolIterations = 3
+
+	var notes []string
+	answer, actions, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "loop", 0, cfg, func(title, content string) {
+		if title == StepLimitReached {
+			notes = append(notes, content)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ContinueConversation: %v", err)
+	}
+	if len(actions) != 3 || len(fake.requests) != 4 {
+		t.Errorf("got %d tool calls and %d requests, want 3 and 4", len(actions), len(fake.requests))
+	}
+	if !strings.Contains(answer, "Stopped after 3 tool calls") {
+		t.Errorf("reply = %q, want the limit message", answer)
+	}
+	if len(notes) != 1 || notes[0] != answer {
+		t.Errorf("limit steps = %q, want one matching the reply", notes)
+	}
+}
+
+func TestToolActionsAreRecorded(t *testing.T) {
+	useFakeGemini(t,
+		reply(genai.FunctionCall{Name: "create_file", Args: map[string]interface{}{"path": "notes.txt", "content": "hi"}}),
+		reply(genai.FunctionCall{Name: "read_file", Args: map[string]interface{}{"path": "missing.txt"}}),
+		reply(genai.Text("Done.")),
+	)
+	cfg := turnConfig(t)
+
+	_, actions, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "write notes", 0, cfg, noSteps)
+	if err != nil {
+		t.Fatalf("ContinueConversation: %v", err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("got %d actions, want 2: %+v", len(actions), actions)
+	}
+	if actions[0].Name != "create_file" || !strings.Contains(actions[0].Args, "notes.txt") || actions[0].Error != "" {
+		t.Errorf("first action = %+v", actions[0])
+	}
+	if actions[1].Name != "read_file" || actions[1].Error == "" {
+		t.Errorf("second action = %+v, want a recorded error", actions[1])
+	}
+}
+
+func TestFallbackModelAnswers(t *testing.T) {
+	fake := useFakeGemini(t,
+		fakeReply{err: errors.New("googleapi: Error 503: The model is overloaded. Please try again later.")},
+		reply(genai.Text("Answer from pro.")),
+	)
+	cfg := turnConfig(t)
+	cfg.GeminiAPIKey = "test-key"
+	cfg.FallbackModels = []string{cfg.ModelName, "gemini-2.5-pro"}
+	model := newTestModel(cfg)
+
+	var fallbackSteps []string
+	answer, _, err := ContinueConversation(context.Background(), model, nil, nil, "hi", 0, cfg, func(title, content string) {
+		if title == StepFallback {
+			fallbackSteps = append(fallbackSteps, content)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ContinueConversation: %v", err)
+	}
+	if answer != "Answer from pro." {
+		t.Errorf("reply = %q, want the fallback model's answer", answer)
+	}
+	if len(fake.requests) != 2 || fake.requests[0].model != model || fake.requests[1].model == model {
+		t.Errorf("got %d requests, want the second one on another model", len(fake.requests))
+	}
+	if len(fallbackSteps) != 2 || !strings.Contains(fallbackSteps[0], "retrying with gemini-2.5-pro") || fallbackSteps[1] != "Answered by gemini-2.5-pro" {
+		t.Errorf("fallback steps = %q", fallbackSteps)
+	}
+}
+
+func TestNoFallbackForOtherErrors(t *testing.T) {
+	fake := useFakeGemini(t, fakeReply{err: errors.New("googleapi: Error 400: API key not valid")})
+	cfg := turnConfig(t)
+	cfg.GeminiAPIKey = "test-key"
+	cfg.FallbackModels = []string{"gemini-2.5-pro"}
+
+	if _, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "hi", 0, cfg, noSteps); err == nil {
+		t.Error("a bad request succeeded")
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("got %d requests, want no fallback", len(fake.requests))
+	}
+}
+
+func TestConversationTimeout(t *testing.T) {
+	useFakeGemini(t, fakeReply{parts: []genai.Part{genai.Text("too late")}, delay: time.Minute})
+	cfg := turnConfig(t)
+	cfg.Agent.ConversationTimeoutSeconds = 1
+
+	start := time.Now()
+	_, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "build everything", 0, cfg, noSteps)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("turn took %s with a 1s timeout", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want a deadline error", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "timed out after 1s") || !strings.Contains(msg, "CONSOLE_AI_CONVERSATION_TIMEOUT") {
+		t.Errorf("error %q does not name the timeout and how to raise it", msg)
+	}
+}
+
+func TestCancelledTurnHasNoTimeoutHint(t *testing.T) {
+	useFakeGemini(t, fakeReply{parts: []genai.Part{genai.Text("too late")}, delay: time.Minute})
+	cfg := turnConfig(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err := ContinueConversation(ctx, newTestModel(cfg), nil, nil, "hi", 0, cfg, noSteps)
+	if err == nil || strings.Contains(err.Error(), "CONSOLE_AI_CONVERSATION_TIMEOUT") {
+		t.Errorf("err = %v, want the caller's deadline without the hint", err)
+	}
+}
+
+func TestConversationTimeoutDefault(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Agent.ConversationTimeoutSeconds = 0
+	if got := conversationTimeout(cfg); got != defaultConversationTimeout {
+		t.Errorf("timeout = %s, want the default %s", got, defaultConversationTimeout)
+	}
+	cfg.Agent.ConversationTimeoutSeconds = 600
+	if got := conversationTimeout(cfg); got != 10*time.Minute {
+		t.Errorf("timeout = %s, want 10m", got)
+	}
+}
+
+func TestNoToolsMode(t *testing.T) {
+	fake := useFakeGemini(t, reply(genai.Text("Use "), genai.Text("`go test ./...`.")))
+	cfg := turnConfig(t)
+	cfg.Agent.NoTools = true
+	model := newTestModel(cfg)
+
+	answer, actions, err := ContinueConversation(context.Background(), model, nil, nil, "how do I run the tests?", 0, cfg, noSteps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Use `go test ./...`." || len(actions) != 0 {
+		t.Errorf("reply = %q with %d actions, want the model's text and no actions", answer, len(actions))
+	}
+	if len(fake.requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(fake.requests))
+	}
+	if tools := fake.requests[0].model.Tools; len(tools) != 0 {
+		t.Errorf("advisory mode advertises %d tools", len(tools))
+	}
+	if system := fake.requests[0].system; !strings.Contains(system, "No tools are available") || strings.Contains(system, "**read_file**") {
+		t.Errorf("system instruction does not describe advisory mode:\n%s", system)
+	}
+}
+
+func TestNoToolsModeRefusesToolCalls(t *testing.T) {
+	useFakeGemini(t,
+		reply(genai.FunctionCall{Name: "create_file", Args: map[string]interface{}{"path": "notes.txt", "content": "hi"}}),
+		reply(genai.Text("Create notes.txt yourself.")),
+	)
+	cfg := turnConfig(t)
+	cfg.Agent.NoTools = true
+
+	_, actions, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "write notes", 0, cfg, noSteps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || !strings.Contains(actions[0].Error, "advisory mode") {
+		t.Errorf("actions = %+v, want the call refused", actions)
+	}
+	if _, err := os.Stat("notes.txt"); !os.IsNotExist(err) {
+		t.Errorf("a tool ran in advisory mode: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"console-ai/pkg/commander"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// gitDiff returns the unified diff of uncommitted changes, or of staged
+// changes when "staged" is set. Diffing only reads the repository, so it
+// also runs in dry-run mode.
+func (e *ToolExecutor) gitDiff(fc genai.FunctionCall) (string, error) {
+	if _, err := commander.ExecuteCommand("git rev-parse --is-inside-work-tree", e.config.AllowedCommands); err != nil {
+		if errors.Is(err, commander.ErrNotAllowed) {
+			return "", err
+		}
+		// git exits with 128 outside a repository; anything else is a real failure
+		if code, _ := commander.ExitStatus(err); code == 128 {
+			return "This directory is not inside a git repository, so there is no diff to show.", nil
+		}
+		return "", fmt.Errorf("failed to check for a git repository: %w", err)
+	}
+
+	command := "git --no-pager diff"
+	if staged, _ := fc.Args["staged"].(bool); staged {
+		command += " --staged"
+	}
+	if path, ok := fc.Args["path"].(string); ok && path != "" {
+		command += " -- " + shellQuote(path)
+	}
+
+	output, err := commander.ExecuteCommand(command, e.config.AllowedCommands)
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	if strings.TrimSpace(output) == "" {
+		return "No changes.", nil
+	}
+	return output, nil
+}
+
+// shellQuote quotes s as a single word for the shell commander runs
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
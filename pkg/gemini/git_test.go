@@ -0,0 +1,83 @@
+package gemini
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/commander"
+)
+
+// gitRepo turns the working directory into a git repository with main.go
+// committed
+func gitRepo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	if err := os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "main.go"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+func TestGitDiffShowsChanges(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	gitRepo(t)
+
+	if out, err := call(e, "git_diff", nil); err != nil || out != "No changes." {
+		t.Errorf("git_diff of a clean tree = %q, %v", out, err)
+	}
+
+	if err := os.WriteFile("main.go", []byte("package main\n\nfunc main() { println(\"hi\") }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := call(e, "git_diff", nil)
+	if err != nil {
+		t.Fatalf("git_diff: %v", err)
+	}
+	for _, want := range []string{"diff --git a/main.go b/main.go", "-func main() {}", `+func main() { println("hi") }`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff is missing %q:\n%s", want, out)
+		}
+	}
+
+	if out, err := call(e, "git_diff", map[string]any{"staged": true}); err != nil || out != "No changes." {
+		t.Errorf("staged diff before staging = %q, %v", out, err)
+	}
+	if err := exec.Command("git", "add", "main.go").Run(); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := call(e, "git_diff", map[string]any{"staged": true}); err != nil || !strings.Contains(out, `+func main() { println("hi") }`) {
+		t.Errorf("staged diff = %q, %v", out, err)
+	}
+}
+
+func TestGitDiffOutsideRepository(t *testing.T) {
+	e, root := newTestExecutor(t)
+	t.Setenv("GIT_CEILING_DIRECTORIES", root)
+
+	out, err := call(e, "git_diff", nil)
+	if err != nil || !strings.Contains(out, "not inside a git repository") {
+		t.Errorf("git_diff outside a repository = %q, %v", out, err)
+	}
+}
+
+func TestGitDiffNotAllowed(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.AllowedCommands = []string{"go"}
+
+	if _, err := call(e, "git_diff", nil); !errors.Is(err, commander.ErrNotAllowed) {
+		t.Errorf("git_diff without git allowed = %v, want ErrNotAllowed", err)
+	}
+}
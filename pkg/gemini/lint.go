@@ -0,0 +1,136 @@
+package gemini
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"console-ai/pkg/agent"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// linterCommands maps the linters lint_project knows to the command it runs
+var linterCommands = map[string]string{
+	"golangci-lint": "golangci-lint run ./...",
+	"golint":        "golint ./...",
+	"go vet":        "go vet ./...",
+	"eslint":        "npx eslint .",
+	"pylint":        "pylint .",
+	"flake8":        "flake8 .",
+	"clippy":        "cargo clippy",
+	"rustfmt":       "cargo fmt -- --check",
+}
+
+// lintProject runs the linter that fits the project, or the one named in
+// the "linter" argument, and returns its findings.
+func (e *ToolExecutor) lintProject(fc genai.FunctionCall) (string, error) {
+	// Ensure we have project context
+	if e.projectInfo == nil {
+		if _, err := e.analyzeProject(".", false, false); err != nil {
+			return "", fmt.Errorf("failed to analyze project context: %w", err)
+		}
+	}
+
+	linter, _ := fc.Args["linter"].(string)
+	command, err := lintCommand(e.projectInfo, linter)
+	if err != nil {
+		return "", err
+	}
+
+	e.log.Info("Linting project with command: %s", command)
+	output, err := e.runCommand(command)
+	if err != nil {
+		if output == "" {
+			return "", err
+		}
+		// Linters exit non-zero when they report findings
+		return fmt.Sprintf("Linter reported problems:\n%s", output), nil
+	}
+	if strings.TrimSpace(output) == "" {
+		return "No lint findings.", nil
+	}
+	return output, nil
+}
+
+// lintCommand picks the lint command for a project. An explicit linter name
+// wins; otherwise linter config files in the project root are preferred,
+// then linters available on PATH.
+func lintCommand(info *agent.ProjectInfo, linter string) (string, error) {
+	if linter != "" {
+		command, ok := linterCommands[strings.ToLower(linter)]
+		if !ok {
+			return "", toolErrorf(ToolErrorValidation, "unknown linter '%s'; supported linters: %s", linter, supportedLinters())
+		}
+		return command, nil
+	}
+
+	hasFile := func(patterns ...string) bool {
+		for _, pattern := range patterns {
+			if matches, _ := filepath.Glob(filepath.Join(info.RootPath, pattern)); len(matches) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	onPath := func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	}
+
+	switch info.Language {
+	case "Go":
+		switch {
+		case hasFile(".golangci.*") || onPath("golangci-lint"):
+			return linterCommands["golangci-lint"], nil
+		case onPath("golint"):
+			return linterCommands["golint"], nil
+		default:
+			return linterCommands["go vet"], nil
+		}
+	case "JavaScript", "TypeScript":
+		if hasFile(".eslintrc*", "eslint.config.*") || hasDependency(info, "eslint") {
+			return linterCommands["eslint"], nil
+		}
+		return "", fmt.Errorf("no ESLint configuration found; add one or pass the linter argument")
+	case "Python":
+		switch {
+		case hasFile(".flake8"):
+			return linterCommands["flake8"], nil
+		case hasFile(".pylintrc", "pylintrc"):
+			return linterCommands["pylint"], nil
+		case onPath("flake8"):
+			return linterCommands["flake8"], nil
+		case onPath("pylint"):
+			return linterCommands["pylint"], nil
+		default:
+			return "", fmt.Errorf("neither flake8 nor pylint is installed")
+		}
+	case "Rust":
+		return linterCommands["clippy"], nil
+	default:
+		return "", fmt.Errorf("linting not supported for language: %s", info.Language)
+	}
+}
+
+// hasDependency reports whether the project lists name as a dependency
+func hasDependency(info *agent.ProjectInfo, name string) bool {
+	for _, dep := range info.Dependencies {
+		if dep == name {
+			return true
+		}
+	}
+	return false
+}
+
+// supportedLinters lists the linter names accepted by lint_project
+func supportedLinters() string {
+	names := make([]string, 0, len(linterCommands))
+	for name := range linterCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,81 @@
+package gemini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"console-ai/pkg/agent"
+)
+
+// fakePath replaces PATH with a directory holding executables named tools
+func fakePath(t *testing.T, tools ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, tool := range tools {
+		if err := os.WriteFile(filepath.Join(dir, tool), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestLintCommandPerLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		files    []string
+		deps     []string
+		tools    []string
+		want     string
+	}{
+		{"go without linters", "Go", nil, nil, nil, "go vet ./..."},
+		{"go with golangci config", "Go", []string{".golangci.yml"}, nil, nil, "golangci-lint run ./..."},
+		{"go with golint installed", "Go", nil, nil, []string{"golint"}, "golint ./..."},
+		{"javascript with eslint config", "JavaScript", []string{"eslint.config.js"}, nil, nil, "npx eslint ."},
+		{"typescript with eslint dependency", "TypeScript", nil, []string{"eslint"}, nil, "npx eslint ."},
+		{"python with flake8 config", "Python", []string{".flake8"}, nil, []string{"pylint"}, "flake8 ."},
+		{"python with pylintrc", "Python", []string{".pylintrc"}, nil, nil, "pylint ."},
+		{"python with pylint installed", "Python", nil, nil, []string{"pylint"}, "pylint ."},
+		{"rust", "Rust", nil, nil, nil, "cargo clippy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			fakePath(t, tt.tools...)
+			info := &agent.ProjectInfo{RootPath: root, Language: tt.language, Dependencies: tt.deps}
+
+			got, err := lintCommand(info, "")
+			if err != nil {
+				t.Fatalf("lintCommand: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("lintCommand = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintCommandErrors(t *testing.T) {
+	fakePath(t)
+	for _, language := range []string{"JavaScript", "Python", "COBOL"} {
+		if got, err := lintCommand(&agent.ProjectInfo{RootPath: t.TempDir(), Language: language}, ""); err == nil {
+			t.Errorf("lintCommand for %s = %q, want an error", language, got)
+		}
+	}
+}
+
+func TestLintCommandOverride(t *testing.T) {
+	info := &agent.ProjectInfo{RootPath: t.TempDir(), Language: "Go"}
+	if got, err := lintCommand(info, "Clippy"); err != nil || got != "cargo clippy" {
+		t.Errorf("lintCommand with linter Clippy = %q, %v", got, err)
+	}
+	if _, err := lintCommand(info, "jslint"); err == nil {
+		t.Error("an unknown linter was accepted")
+	}
+}
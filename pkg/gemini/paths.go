@@ -0,0 +1,99 @@
+package gemini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// confinePath resolves path against the project root and, when
+// RestrictToProjectRoot is enabled, rejects paths that escape the root
+// through "..", absolute paths or symlinks.
+func (e *ToolExecutor) confinePath(path string) (string, error) {
+	if !e.config.Agent.RestrictToProjectRoot {
+		if filepath.IsAbs(path) {
+			return path, nil
+		}
+		return filepath.Join(e.rootPath, path), nil
+	}
+	return ConfinePath(e.rootPath, path)
+}
+
+// ConfinePath resolves path against root and rejects paths that escape it
+// through "..", absolute paths or symlinks. Paths that don't exist yet are
+// checked through their closest existing parent.
+func ConfinePath(root, path string) (string, error) {
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(root, abs)
+	}
+	abs = filepath.Clean(abs)
+
+	resolvedRoot, err := resolveSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project root: %w", err)
+	}
+	resolved, err := resolveSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path '%s': %w", path, err)
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", toolErrorf(ToolErrorPermission, "path '%s' is outside the project root %s; only files inside the project can be changed", path, root)
+	}
+	return abs, nil
+}
+
+// maxSymlinks bounds the dangling symlinks followed while resolving a path
+const maxSymlinks = 255
+
+// resolveSymlinks evaluates symlinks in the longest existing prefix of path,
+// so paths of files that don't exist yet can be checked too. A dangling
+// symlink is followed to its target, since creating the file would write
+// there.
+func resolveSymlinks(path string) (string, error) {
+	path = filepath.Clean(path)
+	var missing []string
+	links := 0
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			for i := len(missing) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, missing[i])
+			}
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if info, lerr := os.Lstat(path); lerr == nil && info.Mode()&os.ModeSymlink != 0 {
+			if links++; links > maxSymlinks {
+				return "", fmt.Errorf("too many symlinks in %s", path)
+			}
+			target, err := os.Readlink(path)
+			if err != nil {
+				return "", err
+			}
+			if !filepath.IsAbs(target) {
+				// The link exists, so its directory does and can be resolved
+				dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+				if err != nil {
+					return "", err
+				}
+				target = filepath.Join(dir, target)
+			}
+			path = filepath.Clean(target)
+			continue
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		missing = append(missing, filepath.Base(path))
+		path = parent
+	}
+}
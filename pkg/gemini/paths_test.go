@@ -0,0 +1,159 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfinePathRejectsTraversal(t *testing.T) {
+	e, root := newTestExecutor(t)
+	e.config.Agent.RestrictToProjectRoot = true
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"../outside.txt",
+		"nested/../../outside.txt",
+		filepath.Join(outside, "abs.txt"),
+		"escape/through-link.txt",
+	} {
+		_, err := call(e, "create_file", map[string]any{"path": path, "content": "x"})
+		var toolErr *ToolError
+		if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorPermission {
+			t.Errorf("create_file(%q) = %v, want a permission error", path, err)
+		}
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("files were written outside the project: %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "outside.txt")); !os.IsNotExist(err) {
+		t.Error("traversal wrote next to the project root")
+	}
+}
+
+func TestConfinePathRejectsDanglingLinkOutside(t *testing.T) {
+	e, root := newTestExecutor(t)
+	e.config.Agent.RestrictToProjectRoot = true
+	outside := t.TempDir()
+	target := filepath.Join(outside, "planted.txt")
+	if err := os.Symlink(target, filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	relative, err := filepath.Rel(root, filepath.Join(outside, "relative.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(relative, filepath.Join(root, "relative")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("dangling", filepath.Join(root, "chained")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"dangling", "relative", "chained"} {
+		_, err := call(e, "create_file", map[string]any{"path": path, "content": "x"})
+		var toolErr *ToolError
+		if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorPermission {
+			t.Errorf("create_file(%q) = %v, want a permission error", path, err)
+		}
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("files were written outside the project: %v", entries)
+	}
+}
+
+func TestConfinePathAllowsDanglingLinkInside(t *testing.T) {
+	e, root := newTestExecutor(t)
+	e.config.Agent.RestrictToProjectRoot = true
+	if err := os.Symlink("generated/out.txt", filepath.Join(root, "latest")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "generated"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "create_file", map[string]any{"path": "latest", "content": "x"}); err != nil {
+		t.Fatalf("create_file through a link inside the root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "generated", "out.txt")); err != nil {
+		t.Errorf("the link target was not created: %v", err)
+	}
+}
+
+func TestConfinePathSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Symlink("b", filepath.Join(root, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a", filepath.Join(root, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConfinePath(root, "a"); err == nil {
+		t.Error("a symlink loop was accepted")
+	}
+}
+
+func TestConfinePathAllowsNestedPaths(t *testing.T) {
+	e, root := newTestExecutor(t)
+	e.config.Agent.RestrictToProjectRoot = true
+
+	for _, path := range []string{"top.txt", "a/b/c/deep.txt", "a/../sibling.txt", filepath.Join(root, "abs.txt")} {
+		if _, err := call(e, "create_file", map[string]any{"path": path, "content": "x"}); err != nil {
+			t.Errorf("create_file(%q): %v", path, err)
+		}
+	}
+	for _, name := range []string{"top.txt", "a/b/c/deep.txt", "sibling.txt", "abs.txt"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+		}
+	}
+}
+
+func TestConfinePathDisabled(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.Agent.RestrictToProjectRoot = false
+	outside := filepath.Join(t.TempDir(), "free.txt")
+	if got, err := e.confinePath(outside); err != nil || got != outside {
+		t.Errorf("confinePath(%q) = %q, %v; want it unchanged", outside, got, err)
+	}
+}
+
+func TestFileToolsWorkInProjectRoot(t *testing.T) {
+	for _, restrict := range []bool{true, false} {
+		t.Run(fmt.Sprintf("restrict=%v", restrict), func(t *testing.T) {
+			e, root := newTestExecutor(t)
+			e.config.Agent.RestrictToProjectRoot = restrict
+			elsewhere := t.TempDir()
+			t.Chdir(elsewhere)
+
+			steps := []struct {
+				tool string
+				args map[string]any
+			}{
+				{"create_file", map[string]any{"path": "notes/a.txt", "content": "one"}},
+				{"update_file", map[string]any{"path": "notes/a.txt", "content": "two"}},
+				{"create_file", map[string]any{"path": "gone.txt", "content": "x"}},
+				{"delete_file", map[string]any{"path": "gone.txt"}},
+			}
+			for _, step := range steps {
+				if _, err := call(e, step.tool, step.args); err != nil {
+					t.Fatalf("%s: %v", step.tool, err)
+				}
+			}
+			if got, err := os.ReadFile(filepath.Join(root, "notes", "a.txt")); err != nil || string(got) != "two" {
+				t.Errorf("notes/a.txt in the project root = %q, %v; want %q", got, err, "two")
+			}
+			if _, err := os.Stat(filepath.Join(root, "gone.txt")); !os.IsNotExist(err) {
+				t.Errorf("gone.txt was not deleted from the project root: %v", err)
+			}
+			if entries, _ := os.ReadDir(elsewhere); len(entries) != 0 {
+				t.Errorf("tools wrote into the working directory instead of the project root: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket holding up to rpm requests that refills at
+// rpm per minute. Reservations may drive the bucket negative, which queues
+// callers behind each other instead of letting them race for the next token.
+type rateLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// apiLimiter is shared by every conversation in the process, so concurrent
+// turns draw from the same per-minute quota.
+var apiLimiter = &rateLimiter{now: time.Now}
+
+// reserve takes a token for one request and returns how long the caller must
+// wait before sending it. A non-positive rpm disables limiting.
+func (l *rateLimiter) reserve(rpm int) time.Duration {
+	if rpm <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(rpm)
+	if l.last.IsZero() {
+		l.tokens = capacity
+	} else {
+		l.tokens += now.Sub(l.last).Minutes() * capacity
+		if l.tokens > capacity {
+			l.tokens = capacity
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / capacity * float64(time.Minute))
+}
+
+// waitForRateLimit blocks until the next Gemini request fits within rpm
+// requests per minute, reporting any wait through stepCallback.
+func waitForRateLimit(ctx context.Context, rpm int, stepCallback func(title, content string)) error {
+	wait := apiLimiter.reserve(rpm)
+	if wait <= 0 {
+		return nil
+	}
+
+	stepCallback(StepRateLimit, fmt.Sprintf("Waiting %s to stay under %d requests per minute", wait.Round(100*time.Millisecond), rpm))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for the rate limiter
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestRequestsBeyondLimitAreDelayed(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	limiter := &rateLimiter{now: clock.now}
+
+	for i := 0; i < 60; i++ {
+		if wait := limiter.reserve(60); wait != 0 {
+			t.Fatalf("request %d waited %s within the limit", i+1, wait)
+		}
+	}
+	if wait := limiter.reserve(60); wait != time.Second {
+		t.Errorf("61st request waits %s, want 1s", wait)
+	}
+	if wait := limiter.reserve(60); wait != 2*time.Second {
+		t.Errorf("62nd request waits %s, want 2s queued behind the 61st", wait)
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	if wait := limiter.reserve(60); wait != 0 {
+		t.Errorf("request after the bucket refilled waited %s", wait)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	limiter := &rateLimiter{now: time.Now}
+	for i := 0; i < 1000; i++ {
+		if wait := limiter.reserve(0); wait != 0 {
+			t.Fatalf("unlimited request waited %s", wait)
+		}
+	}
+}
+
+// useLimiter replaces the shared limiter for the duration of the test
+func useLimiter(t *testing.T, limiter *rateLimiter) {
+	t.Helper()
+	previous := apiLimiter
+	apiLimiter = limiter
+	t.Cleanup(func() { apiLimiter = previous })
+}
+
+func TestWaitForRateLimitReportsWait(t *testing.T) {
+	useLimiter(t, &rateLimiter{now: time.Now})
+
+	var steps []string
+	record := func(title, content string) { steps = append(steps, title+": "+content) }
+
+	start := time.Now()
+	for i := 0; i < 601; i++ {
+		if err := waitForRateLimit(context.Background(), 600, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("request beyond the limit returned after %s, want about 100ms", elapsed)
+	}
+	if len(steps) != 1 || !strings.HasPrefix(steps[0], StepRateLimit+": Waiting") || !strings.Contains(steps[0], "600 requests per minute") {
+		t.Errorf("steps = %q, want one rate limit step", steps)
+	}
+}
+
+func TestWaitForRateLimitCancelled(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	useLimiter(t, &rateLimiter{now: clock.now})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := waitForRateLimit(ctx, 1, noSteps); err != nil {
+		t.Fatalf("first request = %v, want no wait", err)
+	}
+	if err := waitForRateLimit(ctx, 1, noSteps); !errors.Is(err, context.Canceled) {
+		t.Errorf("waiting request = %v, want it cancelled", err)
+	}
+}
@@ -0,0 +1,103 @@
+package gemini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"console-ai/pkg/agent"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// scaffoldProject writes the standard file set for a new project. Existing
+// files are left alone and reported as skipped, except .gitignore, which gets
+// the missing entries merged in.
+func (e *ToolExecutor) scaffoldProject(fc genai.FunctionCall) (string, error) {
+	language, ok := fc.Args["language"].(string)
+	if !ok || language == "" {
+		return "", toolErrorf(ToolErrorValidation, "missing required argument 'language' for scaffold_project")
+	}
+	opts := agent.ScaffoldOptions{Language: language}
+	opts.ProjectType, _ = fc.Args["project_type"].(string)
+	opts.Name, _ = fc.Args["name"].(string)
+	opts.Module, _ = fc.Args["module"].(string)
+	opts.Dockerfile, _ = fc.Args["dockerfile"].(bool)
+	dir, _ := fc.Args["path"].(string)
+	if dir == "" {
+		dir = "."
+	}
+
+	files, err := agent.Scaffold(opts)
+	if err != nil {
+		return "", err
+	}
+
+	var created, skipped []string
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if _, err := e.confinePath(path); err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); err == nil {
+			if file.Path != ".gitignore" {
+				skipped = append(skipped, path)
+				continue
+			}
+			merged, added, err := e.mergeGitignore(path, file.Content)
+			if err != nil {
+				return "", err
+			}
+			if len(added) == 0 {
+				skipped = append(skipped, path)
+				continue
+			}
+			file.Content = merged
+		}
+		if err := e.writeNewFile(path, file.Content); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		created = append(created, path)
+	}
+
+	if e.config.Agent.DryRun {
+		return dryRunResult("would create %s", strings.Join(created, ", ")), nil
+	}
+	e.log.Info("Scaffolded %s project with %d files", language, len(created))
+
+	result := fmt.Sprintf("Scaffolded a %s project. Created: %s", language, strings.Join(created, ", "))
+	if len(skipped) > 0 {
+		result += fmt.Sprintf("\nSkipped existing files: %s", strings.Join(skipped, ", "))
+	}
+	return result, nil
+}
+
+// mergeGitignore returns the existing gitignore at path with the missing
+// entries of generated appended, along with those entries. A missing file
+// yields generated unchanged.
+func (e *ToolExecutor) mergeGitignore(path, generated string) (string, []string, error) {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return generated, nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	merged, added := agent.MergeGitignore(string(existing), generated)
+	e.log.Info("Merged %d new entries into %s", len(added), path)
+	return merged, added, nil
+}
+
+// writeNewFile writes a generated file, creating parent directories. In
+// dry-run mode nothing is written.
+func (e *ToolExecutor) writeNewFile(path, content string) error {
+	if e.config.Agent.DryRun {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	e.snapshotFile(path)
+	return os.WriteFile(path, []byte(content), 0644)
+}
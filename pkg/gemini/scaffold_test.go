@@ -0,0 +1,71 @@
+package gemini
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffoldGoProject(t *testing.T) {
+	e, root := newTestExecutor(t)
+
+	out, err := call(e, "scaffold_project", map[string]any{"language": "go", "name": "hello", "path": "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"go.mod", "main.go", "Makefile", ".gitignore"} {
+		if _, err := os.Stat(filepath.Join(root, "hello", name)); err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+		}
+	}
+	if strings.Contains(out, "Skipped") {
+		t.Errorf("fresh scaffold skipped files: %s", out)
+	}
+}
+
+func TestScaffoldKeepsExistingFiles(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("main.go", []byte("package main // mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := call(e, "scaffold_project", map[string]any{"language": "go", "name": "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile("main.go"); string(data) != "package main // mine\n" {
+		t.Errorf("existing main.go was replaced: %q", data)
+	}
+	if !strings.Contains(out, "Skipped existing files: main.go") {
+		t.Errorf("result does not report the skipped file: %s", out)
+	}
+}
+
+func TestScaffoldStaysInProject(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if _, err := call(e, "scaffold_project", map[string]any{"language": "go", "path": "../outside"}); err == nil {
+		t.Error("scaffolding outside the project succeeded")
+	}
+}
+
+func TestScaffoldMergesGitignore(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile(".gitignore", []byte("secrets/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "scaffold_project", map[string]any{"language": "go", "name": "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(".gitignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "secrets/\n") || strings.Count(string(data), "secrets/") != 1 {
+		t.Errorf("custom entries were not kept:\n%s", data)
+	}
+	if !strings.Contains(string(data), "Added by Console Buddy") {
+		t.Errorf("no generated entries were merged in:\n%s", data)
+	}
+}
@@ -0,0 +1,76 @@
+package gemini
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// secretFilePatterns match file names that are conventionally gitignored
+// because they hold credentials.
+var secretFilePatterns = []string{
+	".env", ".env.*", "*.pem", "*.key", "*.p12", "*.pfx",
+	"id_rsa", "*_rsa", "*_dsa", "*_ecdsa", "*_ed25519",
+	"credentials", "credentials.*", "*.credentials", ".netrc", ".npmrc", ".pypirc",
+}
+
+// templateSuffixes mark files that document secrets without containing them.
+var templateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// secretValuePatterns match well-known credential formats.
+var secretValuePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	regexp.MustCompile(`gh[pousr]_[0-9A-Za-z]{36}`),
+	regexp.MustCompile(`xox[abprs]-[0-9A-Za-z\-]{10,}`),
+	regexp.MustCompile(`sk-[0-9A-Za-z]{20,}`),
+}
+
+// secretAssignment matches KEY=value style lines whose key names a secret.
+var secretAssignment = regexp.MustCompile(`(?im)^\s*(?:export\s+)?[A-Z0-9_]*(?:SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY)[A-Z0-9_]*\s*[=:]\s*["']?([^"'\s#]+)`)
+
+// placeholderValue matches values that are obviously not real secrets.
+var placeholderValue = regexp.MustCompile(`(?i)^(?:<.*>|\$\{.*\}|\$[A-Z_]+|x+|\*+|changeme|change_me|your[_-].*|replace[_-]?me|todo|example.*|dummy.*|test|none|null|placeholder.*)$`)
+
+// isSecretPath reports whether path looks like a credentials file.
+func isSecretPath(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, suffix := range templateSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	for _, pattern := range secretFilePatterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSecretValue reports whether content holds values that look like real secrets.
+func containsSecretValue(content string) bool {
+	for _, pattern := range secretValuePatterns {
+		if pattern.MatchString(content) {
+			return true
+		}
+	}
+	for _, match := range secretAssignment.FindAllStringSubmatch(content, -1) {
+		value := match[1]
+		if len(value) >= 8 && !placeholderValue.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectSecretWrite returns a warning when writing content to path would
+// store secret-like values in a file that is normally kept out of version control.
+func detectSecretWrite(path, content string) string {
+	if !isSecretPath(path) || !containsSecretValue(content) {
+		return ""
+	}
+	return fmt.Sprintf("'%s' looks like a credentials file and the content contains secret-like values", path)
+}
@@ -0,0 +1,151 @@
+package gemini
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxFailureTailLines is how much raw test output is kept when tests fail
+const maxFailureTailLines = 40
+
+// maxListedFailures limits the failing test names listed in a summary
+const maxListedFailures = 20
+
+// testSummary holds the counts extracted from a test run's output
+type testSummary struct {
+	Framework   string
+	Passed      int
+	Failed      int
+	Skipped     int
+	FailedTests []string
+}
+
+var (
+	goTestResultRe  = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+	jestTotalsRe    = regexp.MustCompile(`^Tests:\s+(.+)$`)
+	jestFailureRe   = regexp.MustCompile(`^\s*● (.+)$`)
+	pytestTotalsRe  = regexp.MustCompile(`^=+ (.*\d+ (?:passed|failed|skipped|error).*) in [\d.]+s`)
+	pytestFailureRe = regexp.MustCompile(`^(?:FAILED|ERROR) (\S+)`)
+	countRe         = regexp.MustCompile(`(\d+) (passed|failed|skipped|errors?)`)
+)
+
+// parseTestOutput extracts pass/fail/skip counts and failing test names from
+// go test -v, Jest or pytest output. ok is false when the format isn't recognised.
+func parseTestOutput(output string) (summary testSummary, ok bool) {
+	lines := strings.Split(output, "\n")
+
+	// go test -v reports every test on its own line
+	for _, line := range lines {
+		match := goTestResultRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		summary.Framework = "go test"
+		switch match[1] {
+		case "PASS":
+			summary.Passed++
+		case "FAIL":
+			summary.Failed++
+			summary.FailedTests = append(summary.FailedTests, match[2])
+		case "SKIP":
+			summary.Skipped++
+		}
+	}
+	if summary.Framework != "" {
+		return summary, true
+	}
+
+	for _, line := range lines {
+		if match := jestTotalsRe.FindStringSubmatch(line); match != nil {
+			summary.Framework = "jest"
+			addCounts(&summary, match[1])
+		} else if match := pytestTotalsRe.FindStringSubmatch(line); match != nil {
+			summary.Framework = "pytest"
+			addCounts(&summary, match[1])
+		}
+	}
+
+	switch summary.Framework {
+	case "jest":
+		for _, line := range lines {
+			if match := jestFailureRe.FindStringSubmatch(line); match != nil && !strings.HasPrefix(match[1], "Console") {
+				summary.FailedTests = appendUnique(summary.FailedTests, strings.TrimSpace(match[1]))
+			}
+		}
+	case "pytest":
+		for _, line := range lines {
+			if match := pytestFailureRe.FindStringSubmatch(line); match != nil {
+				summary.FailedTests = appendUnique(summary.FailedTests, match[1])
+			}
+		}
+	default:
+		return summary, false
+	}
+	return summary, true
+}
+
+// addCounts adds counts such as "2 failed, 10 passed" to summary
+func addCounts(summary *testSummary, text string) {
+	for _, match := range countRe.FindAllStringSubmatch(text, -1) {
+		n, _ := strconv.Atoi(match[1])
+		switch match[2] {
+		case "passed":
+			summary.Passed += n
+		case "failed", "error", "errors":
+			summary.Failed += n
+		case "skipped":
+			summary.Skipped += n
+		}
+	}
+}
+
+// appendUnique appends s to list unless it is already present
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// String renders the summary as a short report
+func (s testSummary) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Test summary (%s): %d passed, %d failed, %d skipped", s.Framework, s.Passed, s.Failed, s.Skipped))
+	if len(s.FailedTests) > 0 {
+		builder.WriteString("\nFailing tests:")
+		for i, name := range s.FailedTests {
+			if i == maxListedFailures {
+				builder.WriteString(fmt.Sprintf("\n  ... and %d more", len(s.FailedTests)-maxListedFailures))
+				break
+			}
+			builder.WriteString("\n  - " + name)
+		}
+	}
+	return builder.String()
+}
+
+// summarizeTestRun condenses a test run into a summary. When tests failed
+// the tail of the raw output is kept so the failure details are available.
+func summarizeTestRun(output string, runErr error) (string, error) {
+	summary, ok := parseTestOutput(output)
+	if !ok {
+		return output, runErr
+	}
+	if runErr == nil && summary.Failed == 0 {
+		return summary.String(), nil
+	}
+	return "", fmt.Errorf("tests failed\n%s\n\nLast lines of output:\n%s", summary, lastLines(output, maxFailureTailLines))
+}
+
+// lastLines returns at most n trailing lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
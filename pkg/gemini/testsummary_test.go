@@ -0,0 +1,103 @@
+package gemini
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const goTestOutput = `=== RUN   TestAdd
+--- PASS: TestAdd (0.00s)
+=== RUN   TestDivide
+    math_test.go:21: divide by zero did not error
+--- FAIL: TestDivide (0.00s)
+=== RUN   TestNetwork
+    net_test.go:9: needs network
+--- SKIP: TestNetwork (0.00s)
+=== RUN   TestTable
+=== RUN   TestTable/small
+    --- PASS: TestTable/small (0.00s)
+--- PASS: TestTable (0.00s)
+FAIL
+FAIL	example.com/math	0.004s
+`
+
+const jestOutput = ` FAIL  src/cart.test.js
+  ● Cart › applies discount
+
+    expect(received).toBe(expected)
+
+ PASS  src/user.test.js
+  ● Console
+
+    console.log
+      debug output
+
+Test Suites: 1 failed, 1 passed, 2 total
+Tests:       1 failed, 1 skipped, 7 passed, 9 total
+Snapshots:   0 total
+Time:        1.234 s
+`
+
+const pytestOutput = `============================= test session starts ==============================
+collected 6 items
+
+tests/test_api.py ..F.s.                                                 [100%]
+
+=================================== FAILURES ===================================
+_________________________________ test_create __________________________________
+E       assert 500 == 201
+=========================== short test summary info ============================
+FAILED tests/test_api.py::test_create - assert 500 == 201
+==================== 1 failed, 4 passed, 1 skipped in 0.12s ====================
+`
+
+func TestParseTestOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   testSummary
+	}{
+		{"go test", goTestOutput, testSummary{Framework: "go test", Passed: 3, Failed: 1, Skipped: 1, FailedTests: []string{"TestDivide"}}},
+		{"jest", jestOutput, testSummary{Framework: "jest", Passed: 7, Failed: 1, Skipped: 1, FailedTests: []string{"Cart › applies discount"}}},
+		{"pytest", pytestOutput, testSummary{Framework: "pytest", Passed: 4, Failed: 1, Skipped: 1, FailedTests: []string{"tests/test_api.py::test_create"}}},
+	}
+	for _, tt := range tests {
+		got, ok := parseTestOutput(tt.output)
+		if !ok {
+			t.Errorf("%s: output not recognised", tt.name)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: summary = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, ok := parseTestOutput("make: *** No rule to make target 'test'."); ok {
+		t.Error("unrelated output was recognised as test output")
+	}
+}
+
+func TestSummarizeTestRun(t *testing.T) {
+	passing := "--- PASS: TestA (0.00s)\n--- PASS: TestB (0.00s)\nok  \texample.com/a\t0.01s\n"
+	out, err := summarizeTestRun(passing, nil)
+	if err != nil || out != "Test summary (go test): 2 passed, 0 failed, 0 skipped" {
+		t.Errorf("passing run = %q, %v", out, err)
+	}
+
+	_, err = summarizeTestRun(goTestOutput, errors.New("exit status 1"))
+	if err == nil {
+		t.Fatal("failing run returned no error")
+	}
+	for _, want := range []string{"1 failed", "  - TestDivide", "Last lines of output:", "divide by zero did not error"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("failure report is missing %q:\n%v", want, err)
+		}
+	}
+
+	raw := "something unexpected happened"
+	if out, err := summarizeTestRun(raw, nil); out != raw || err != nil {
+		t.Errorf("unrecognised output = %q, %v; want it unchanged", out, err)
+	}
+}
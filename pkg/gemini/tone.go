@@ -0,0 +1,21 @@
+package gemini
+
+import "console-ai/pkg/config"
+
+// toneInstructions are the system prompt fragments of the tone profiles in
+// config.Tones. The humor level still decides how many jokes are told.
+var toneInstructions = map[string]string{
+	"professional": "Be formal and precise. Keep answers focused on the task, avoid small talk and keep any humor dry and brief.",
+	"friendly":     "Be warm and encouraging. Use a relaxed, conversational style and acknowledge progress the user makes.",
+	"snarky":       "Be playfully sarcastic about the code and the tools, never about the user. The advice itself must stay accurate and helpful.",
+	"mentor":       "Teach as you go. Explain the reasoning behind each change, point out the concepts involved and suggest what to learn next.",
+}
+
+// toneInstruction returns the prompt fragment of the configured tone, or ""
+// when none is set
+func toneInstruction(cfg *config.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	return toneInstructions[cfg.Tone]
+}
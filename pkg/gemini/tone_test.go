@@ -0,0 +1,44 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+
+	"console-ai/pkg/config"
+)
+
+func TestToneProfilesInjectDistinctText(t *testing.T) {
+	cfg := testConfig(t)
+	seen := make(map[string]string)
+	for _, tone := range config.Tones {
+		cfg.Tone = tone
+		instruction := toneInstruction(cfg)
+		if instruction == "" {
+			t.Errorf("tone %q has no prompt fragment", tone)
+			continue
+		}
+		if other, ok := seen[instruction]; ok {
+			t.Errorf("tones %q and %q inject the same text", tone, other)
+		}
+		seen[instruction] = tone
+
+		prompt := systemInstruction(nil, cfg, 30, nil)
+		if !strings.Contains(prompt, "**Tone:** "+instruction) {
+			t.Errorf("system instruction for %q does not contain its fragment", tone)
+		}
+		if !strings.Contains(prompt, "Humor Level: 30%") {
+			t.Errorf("tone %q replaced the humor level", tone)
+		}
+	}
+}
+
+func TestNoToneInjectsNothing(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Tone = ""
+	if prompt := systemInstruction(nil, cfg, 0, nil); strings.Contains(prompt, "**Tone:**") {
+		t.Error("instruction has a tone without a configured one")
+	}
+	if toneInstruction(nil) != "" {
+		t.Error("a nil config has a tone")
+	}
+}
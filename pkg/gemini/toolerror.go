@@ -0,0 +1,103 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"console-ai/pkg/commander"
+)
+
+// ToolErrorKind categorizes why a tool call failed
+type ToolErrorKind int
+
+const (
+	ToolErrorExecution  ToolErrorKind = iota // The tool ran and failed, e.g. a failing command
+	ToolErrorValidation                      // Missing or invalid arguments
+	ToolErrorNotFound                        // A file or directory doesn't exist
+	ToolErrorPermission                      // Denied by the OS or outside the project root
+	ToolErrorBlocked                         // Refused by configuration or safety mode
+	ToolErrorTimeout                         // The tool ran out of time
+)
+
+// toolErrorKindNames are the names used in step messages, indexed by kind
+var toolErrorKindNames = []string{"execution", "validation", "not found", "permission", "blocked", "timeout"}
+
+// String returns the name of the kind
+func (k ToolErrorKind) String() string {
+	if k < 0 || int(k) >= len(toolErrorKindNames) {
+		return toolErrorKindNames[ToolErrorExecution]
+	}
+	return toolErrorKindNames[k]
+}
+
+// ToolError is the error returned by ToolExecutor.Execute. Its message is the
+// one of the underlying error, which is what the model sees.
+type ToolError struct {
+	Tool string
+	Kind ToolErrorKind
+	Err  error
+}
+
+func (e *ToolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// toolErrorf creates a ToolError of the given kind. Execute fills in the tool name.
+func toolErrorf(kind ToolErrorKind, format string, args ...interface{}) error {
+	return &ToolError{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// newToolError wraps err from the named tool, keeping the kind of a ToolError
+// in its chain and otherwise deriving it from well-known errors.
+func newToolError(tool string, err error) *ToolError {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return &ToolError{Tool: tool, Kind: toolErr.Kind, Err: err}
+	}
+
+	kind := ToolErrorExecution
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		kind = ToolErrorNotFound
+	case errors.Is(err, fs.ErrPermission):
+		kind = ToolErrorPermission
+	case errors.Is(err, commander.ErrNotAllowed):
+		kind = ToolErrorBlocked
+	case errors.Is(err, context.DeadlineExceeded):
+		kind = ToolErrorTimeout
+	}
+	return &ToolError{Tool: tool, Kind: kind, Err: err}
+}
+
+// toolErrorKind returns the kind of a ToolError in err's chain, and
+// ToolErrorExecution for any other error
+func toolErrorKind(err error) ToolErrorKind {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Kind
+	}
+	return ToolErrorExecution
+}
+
+// toolErrorStep formats err for the StepToolError step as "<kind>: <message>"
+func toolErrorStep(err error) string {
+	return toolErrorKind(err).String() + ": " + err.Error()
+}
+
+// ParseToolErrorStep splits the content of a StepToolError step into the
+// error kind and message.
+func ParseToolErrorStep(content string) (ToolErrorKind, string) {
+	for kind, name := range toolErrorKindNames {
+		if message, ok := strings.CutPrefix(content, name+": "); ok {
+			return ToolErrorKind(kind), message
+		}
+	}
+	return ToolErrorExecution, content
+}
@@ -0,0 +1,126 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestToolErrorKinds(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.AllowedCommands = []string{"false"}
+	e.config.Agent.DisabledTools = []string{"delete_file"}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want ToolErrorKind
+	}{
+		{"missing argument", "read_file", map[string]any{}, ToolErrorValidation},
+		{"bad line range", "read_file", map[string]any{"path": "notes.txt", "start_line": 3.0, "end_line": 1.0}, ToolErrorValidation},
+		{"missing file", "read_file", map[string]any{"path": "missing.txt"}, ToolErrorNotFound},
+		{"missing directory", "list_files", map[string]any{"path": "missing"}, ToolErrorNotFound},
+		{"outside the project", "create_file", map[string]any{"path": "../escape.txt", "content": "x"}, ToolErrorPermission},
+		{"disabled tool", "delete_file", map[string]any{"path": "notes.txt"}, ToolErrorBlocked},
+		{"command not allowed", "execute_shell_command", map[string]any{"command": "curl example.com"}, ToolErrorBlocked},
+		{"failing command", "execute_shell_command", map[string]any{"command": "false"}, ToolErrorExecution},
+	}
+	if err := os.WriteFile("notes.txt", []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := call(e, tt.tool, tt.args)
+			var toolErr *ToolError
+			if !errors.As(err, &toolErr) {
+				t.Fatalf("error = %v, want a *ToolError", err)
+			}
+			if toolErr.Kind != tt.want {
+				t.Errorf("kind = %v, want %v (error: %v)", toolErr.Kind, tt.want, err)
+			}
+			if toolErr.Tool != tt.tool {
+				t.Errorf("tool = %q, want %q", toolErr.Tool, tt.tool)
+			}
+		})
+	}
+}
+
+func TestNewToolErrorKinds(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ToolErrorKind
+	}{
+		{fmt.Errorf("wrapped: %w", os.ErrNotExist), ToolErrorNotFound},
+		{fmt.Errorf("wrapped: %w", os.ErrPermission), ToolErrorPermission},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), ToolErrorTimeout},
+		{errors.New("exit status 1"), ToolErrorExecution},
+		{fmt.Errorf("wrapped: %w", toolErrorf(ToolErrorValidation, "bad")), ToolErrorValidation},
+	}
+	for _, tt := range tests {
+		if got := newToolError("tool", tt.err); got.Kind != tt.want {
+			t.Errorf("newToolError(%v).Kind = %v, want %v", tt.err, got.Kind, tt.want)
+		}
+	}
+}
+
+func TestToolErrorStepRoundTrip(t *testing.T) {
+	for kind := ToolErrorExecution; kind <= ToolErrorTimeout; kind++ {
+		err := &ToolError{Tool: "read_file", Kind: kind, Err: errors.New("it broke")}
+		gotKind, message := ParseToolErrorStep(toolErrorStep(err))
+		if gotKind != kind || message != "it broke" {
+			t.Errorf("round trip of %v = %v, %q", kind, gotKind, message)
+		}
+	}
+	if kind, message := ParseToolErrorStep("plain message"); kind != ToolErrorExecution || message != "plain message" {
+		t.Errorf("ParseToolErrorStep without a kind = %v, %q", kind, message)
+	}
+}
+
+func TestToolErrorIsSentToModel(t *testing.T) {
+	fake := useFakeGemini(t,
+		reply(genai.FunctionCall{Name: "read_file", Args: map[string]interface{}{"path": "missing.txt"}}),
+		reply(genai.Text("It doesn't exist.")),
+	)
+	cfg := turnConfig(t)
+
+	if _, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "read it", 0, cfg, noSteps); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.requests) != 2 || len(fake.requests[1].parts) != 1 {
+		t.Fatalf("requests = %+v, want the prompt and one function response", fake.requests)
+	}
+	response, ok := fake.requests[1].parts[0].(genai.FunctionResponse)
+	if !ok {
+		t.Fatalf("second request sent %T, want a FunctionResponse", fake.requests[1].parts[0])
+	}
+	if response.Response["kind"] != "not found" {
+		t.Errorf("kind = %v, want %q", response.Response["kind"], "not found")
+	}
+	if message, _ := response.Response["error"].(string); message == "" {
+		t.Errorf("response = %v, want the error message", response.Response)
+	}
+}
+
+func TestSuccessfulToolSendsNoError(t *testing.T) {
+	fake := useFakeGemini(t,
+		reply(genai.FunctionCall{Name: "create_file", Args: map[string]interface{}{"path": "notes.txt", "content": "hi"}}),
+		reply(genai.Text("Done.")),
+	)
+	cfg := turnConfig(t)
+
+	if _, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "write it", 0, cfg, noSteps); err != nil {
+		t.Fatal(err)
+	}
+
+	response := fake.requests[1].parts[0].(genai.FunctionResponse)
+	if _, ok := response.Response["error"]; ok {
+		t.Errorf("response = %v, want no error for a successful call", response.Response)
+	}
+}
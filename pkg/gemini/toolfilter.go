@@ -0,0 +1,74 @@
+package gemini
+
+import (
+	"console-ai/pkg/config"
+	"console-ai/pkg/logger"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// toolEnabled reports whether the agent config allows the named tool. A tool
+// must be in EnabledTools when that list is set, and never in DisabledTools.
+// fetch_url additionally needs FetchEnabled.
+func toolEnabled(name string, cfg config.AgentConfig) bool {
+	if name == "fetch_url" && !cfg.FetchEnabled {
+		return false
+	}
+	if len(cfg.EnabledTools) > 0 && !containsString(cfg.EnabledTools, name) {
+		return false
+	}
+	return !containsString(cfg.DisabledTools, name)
+}
+
+// filterTools drops the declarations of tools the agent config does not allow,
+// so the model is only offered tools that Execute will run. Tools left without
+// declarations are removed entirely, and advisory mode (NoTools) removes all.
+func filterTools(tools []*genai.Tool, cfg config.AgentConfig) []*genai.Tool {
+	if cfg.NoTools {
+		return nil
+	}
+	warnUnknownTools(tools, cfg.EnabledTools, "enabled_tools")
+	warnUnknownTools(tools, cfg.DisabledTools, "disabled_tools")
+
+	var filtered []*genai.Tool
+	for _, tool := range tools {
+		var decls []*genai.FunctionDeclaration
+		for _, decl := range tool.FunctionDeclarations {
+			if toolEnabled(decl.Name, cfg) {
+				decls = append(decls, decl)
+			}
+		}
+		if len(decls) > 0 {
+			filtered = append(filtered, &genai.Tool{FunctionDeclarations: decls})
+		}
+	}
+	return filtered
+}
+
+// warnUnknownTools logs configured tool names that match no declaration,
+// which usually means a typo
+func warnUnknownTools(tools []*genai.Tool, names []string, setting string) {
+	for _, name := range names {
+		known := false
+		for _, tool := range tools {
+			for _, decl := range tool.FunctionDeclarations {
+				if decl.Name == name {
+					known = true
+				}
+			}
+		}
+		if !known {
+			logger.Warn("Unknown tool %q in %s", name, setting)
+		}
+	}
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/config"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// toolNames returns the names declared by tools
+func toolNames(tools []*genai.Tool) []string {
+	var names []string
+	for _, tool := range tools {
+		for _, decl := range tool.FunctionDeclarations {
+			names = append(names, decl.Name)
+		}
+	}
+	return names
+}
+
+func TestDisabledToolIsNotAdvertised(t *testing.T) {
+	names := toolNames(filterTools(defineTools(), config.AgentConfig{DisabledTools: []string{"delete_file", "execute_shell_command"}}))
+
+	if containsString(names, "delete_file") || containsString(names, "execute_shell_command") {
+		t.Errorf("disabled tools are still declared: %q", names)
+	}
+	if !containsString(names, "read_file") {
+		t.Errorf("other tools were dropped: %q", names)
+	}
+}
+
+func TestEnabledToolsAllowlist(t *testing.T) {
+	names := toolNames(filterTools(defineTools(), config.AgentConfig{EnabledTools: []string{"read_file", "list_files"}, DisabledTools: []string{"list_files"}}))
+	if len(names) != 1 || names[0] != "read_file" {
+		t.Errorf("declared tools = %q, want only read_file", names)
+	}
+}
+
+func TestDisabledToolIsNotExecuted(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.Agent.DisabledTools = []string{"delete_file"}
+	if err := os.WriteFile("keep.txt", []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := call(e, "delete_file", map[string]any{"path": "keep.txt"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Kind != ToolErrorBlocked {
+		t.Fatalf("delete_file = %v, want a blocked tool error", err)
+	}
+	if _, err := os.Stat("keep.txt"); err != nil {
+		t.Errorf("disabled tool deleted the file: %v", err)
+	}
+}
+
+func TestDisabledToolCallFromModelIsRejected(t *testing.T) {
+	useFakeGemini(t,
+		reply(genai.FunctionCall{Name: "delete_file", Args: map[string]interface{}{"path": "keep.txt"}}),
+		reply(genai.Text("I can't delete files here.")),
+	)
+	cfg := turnConfig(t)
+	cfg.Agent.DisabledTools = []string{"delete_file"}
+	if err := os.WriteFile("keep.txt", []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, actions, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "delete keep.txt", 0, cfg, noSteps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || !strings.Contains(actions[0].Error, "disabled by configuration") {
+		t.Errorf("actions = %+v, want the call rejected", actions)
+	}
+	if _, err := os.Stat("keep.txt"); err != nil {
+		t.Errorf("disabled tool deleted the file: %v", err)
+	}
+}
+
+func TestToolDefinitionsFollowFilter(t *testing.T) {
+	cfg := config.AgentConfig{DisabledTools: []string{"delete_file"}}
+	definitions := generateToolDefinitions(filterTools(defineTools(), cfg))
+
+	if strings.Contains(definitions, "**delete_file**") {
+		t.Errorf("definitions advertise a filtered tool:\n%s", definitions)
+	}
+	if !strings.Contains(definitions, "**read_file**") {
+		t.Errorf("definitions lost an enabled tool:\n%s", definitions)
+	}
+
+	model := &genai.GenerativeModel{Tools: filterTools(defineTools(), cfg)}
+	ApplySystemInstruction(model, &config.Config{Agent: cfg}, 0, nil)
+	if prompt := string(model.SystemInstruction.Parts[0].(genai.Text)); strings.Contains(prompt, "**delete_file**") {
+		t.Error("system instruction advertises a filtered tool")
+	}
+}
+
+func TestToolDefinitionsWithoutTools(t *testing.T) {
+	if definitions := generateToolDefinitions(nil); !strings.Contains(definitions, "No tools are available") {
+		t.Errorf("definitions without tools = %q", definitions)
+	}
+}
@@ -0,0 +1,116 @@
+package gemini
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/logger"
+)
+
+// logEntry is a line written by the logger in JSON format
+type logEntry struct {
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields"`
+}
+
+// captureLog sends the log of the test to a buffer in JSON format and
+// returns a function decoding the entries written so far
+func captureLog(t *testing.T) func() []logEntry {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := logger.Initialize(&logger.Config{Level: logger.DEBUG, Format: logger.FormatJSON, Output: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.Initialize(&logger.Config{Level: logger.FATAL, Output: io.Discard}) })
+
+	return func() []logEntry {
+		var entries []logEntry
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var entry logEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("log line %q: %v", line, err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+// findEntry returns the first entry with the given message
+func findEntry(t *testing.T, entries []logEntry, message string) logEntry {
+	t.Helper()
+	for _, entry := range entries {
+		if entry.Message == message {
+			return entry
+		}
+	}
+	t.Fatalf("no %q entry in the log: %+v", message, entries)
+	return logEntry{}
+}
+
+func TestExecuteLogsToolCallAndResult(t *testing.T) {
+	entries := captureLog(t)
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("notes.txt", []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "read_file", map[string]any{"path": "notes.txt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := entries()
+	callEntry := findEntry(t, logged, "Tool call: read_file")
+	params, _ := callEntry.Fields["params"].(map[string]interface{})
+	if params["path"] != "notes.txt" {
+		t.Errorf("call params = %v, want the path", callEntry.Fields["params"])
+	}
+	result := findEntry(t, logged, "Tool result: read_file")
+	if result.Fields["success"] != true || result.Fields["result"] != "hello" {
+		t.Errorf("result fields = %v, want a successful result", result.Fields)
+	}
+}
+
+func TestExecuteLogsFailedTool(t *testing.T) {
+	entries := captureLog(t)
+	e, _ := newTestExecutor(t)
+
+	if _, err := call(e, "read_file", map[string]any{"path": "missing.txt"}); err == nil {
+		t.Fatal("reading a missing file succeeded")
+	}
+
+	result := findEntry(t, entries(), "Tool result: read_file")
+	if result.Level != "ERROR" || result.Fields["success"] != false {
+		t.Errorf("result = %+v, want a failed result at ERROR level", result)
+	}
+	if message, _ := result.Fields["error"].(string); !strings.Contains(message, "missing.txt") {
+		t.Errorf("error field = %v, want the error message", result.Fields["error"])
+	}
+}
+
+func TestExecuteTruncatesLoggedResult(t *testing.T) {
+	entries := captureLog(t)
+	e, _ := newTestExecutor(t)
+	content := strings.Repeat("x", 10*maxLoggedBytes)
+
+	if _, err := call(e, "create_file", map[string]any{"path": "big.txt", "content": content}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(e, "read_file", map[string]any{"path": "big.txt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := entries()
+	params, _ := findEntry(t, logged, "Tool call: create_file").Fields["params"].(map[string]interface{})
+	if text, _ := params["content"].(string); len(text) >= len(content) {
+		t.Errorf("logged content has %d bytes, want it truncated", len(text))
+	}
+	if text, _ := findEntry(t, logged, "Tool result: read_file").Fields["result"].(string); len(text) >= len(content) {
+		t.Errorf("logged result has %d bytes, want it truncated", len(text))
+	}
+}
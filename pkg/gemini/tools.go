@@ -1,9 +1,12 @@
 package gemini
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"console-ai/pkg/agent"
@@ -21,22 +24,66 @@ func defineTools() []*genai.Tool {
 			FunctionDeclarations: []*genai.FunctionDeclaration{
 				{
 					Name:        "execute_shell_command",
-					Description: "Executes a shell command on the user's machine. Use this for general-purpose commands that are not related to file manipulation. For example, 'go run main.go' or 'npm install'.",
+					Description: "Executes a shell command on the user's machine. Use this for general-purpose commands that are not related to file manipulation. For example, 'go run main.go' or 'npm install'. The result ends with the command's EXIT CODE, or the signal that terminated it, so e.g. failing tests (usually 1) can be told from a usage error (usually 2) or a missing command (127).",
 					Parameters: &genai.Schema{
 						Type: genai.TypeObject,
 						Properties: map[string]*genai.Schema{
 							"command": {Type: genai.TypeString, Description: "The command to execute."},
+							"env": {
+								Type:        genai.TypeArray,
+								Description: "Optional environment variables for the command, as KEY=VALUE strings, e.g. [\"NODE_ENV=production\", \"CGO_ENABLED=0\"].",
+								Items:       &genai.Schema{Type: genai.TypeString},
+							},
 						},
 						Required: []string{"command"},
 					},
 				},
+				{
+					Name:        "run_background_command",
+					Description: "Starts a long-running command, such as a dev server ('npm run dev') or a file watcher, in the background and returns its id right away instead of waiting for it to exit. Use tail_background_output to check its output and stop_background_command when it is no longer needed.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"command": {Type: genai.TypeString, Description: "The command to start."},
+							"env": {
+								Type:        genai.TypeArray,
+								Description: "Optional environment variables for the command, as KEY=VALUE strings.",
+								Items:       &genai.Schema{Type: genai.TypeString},
+							},
+						},
+						Required: []string{"command"},
+					},
+				},
+				{
+					Name:        "tail_background_output",
+					Description: "Shows whether a command started with run_background_command is still running and its latest output lines.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"id":    {Type: genai.TypeInteger, Description: "The id returned by run_background_command."},
+							"lines": {Type: genai.TypeInteger, Description: "Number of output lines to return (default 40)."},
+						},
+						Required: []string{"id"},
+					},
+				},
+				{
+					Name:        "stop_background_command",
+					Description: "Stops a command started with run_background_command, together with the processes it spawned, and returns its last output lines.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"id": {Type: genai.TypeInteger, Description: "The id returned by run_background_command."},
+						},
+						Required: []string{"id"},
+					},
+				},
 				{
 					Name:        "create_file",
 					Description: "Creates a new file with the given content. For example, to create a new Python file, you would use create_file('main.py', 'print(\"Hello, World!\")').",
 					Parameters: &genai.Schema{
 						Type: genai.TypeObject,
 						Properties: map[string]*genai.Schema{
-							"path":    {Type: genai.TypeString, Description: "The path of the file to create."},
+							"path":    {Type: genai.TypeString, Description: "The path of the file to create. Missing parent directories are created."},
 							"content": {Type: genai.TypeString, Description: "The content to write to the file."},
 						},
 						Required: []string{"path", "content"},
@@ -44,11 +91,13 @@ func defineTools() []*genai.Tool {
 				},
 				{
 					Name:        "read_file",
-					Description: "Reads the content of a file. For example, to read a file named 'main.go', you would use read_file('main.go').",
+					Description: "Reads the content of a file. For example, to read a file named 'main.go', you would use read_file('main.go'). For large files, pass start_line and end_line to read only part of it; the result then includes the total line count.",
 					Parameters: &genai.Schema{
 						Type: genai.TypeObject,
 						Properties: map[string]*genai.Schema{
-							"path": {Type: genai.TypeString, Description: "The path of the file to read."},
+							"path":       {Type: genai.TypeString, Description: "The path of the file to read."},
+							"start_line": {Type: genai.TypeInteger, Description: "Optional first line to return (1-based)."},
+							"end_line":   {Type: genai.TypeInteger, Description: "Optional last line to return (inclusive). Defaults to the end of the file."},
 						},
 						Required: []string{"path"},
 					},
@@ -87,13 +136,88 @@ func defineTools() []*genai.Tool {
 						Required: []string{"path"},
 					},
 				},
+				{
+					Name:        "copy_file",
+					Description: "Copies a file to a new path without loading it into the conversation, keeping its permissions. Use this for backups and templates instead of read_file plus create_file.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"source":      {Type: genai.TypeString, Description: "The path of the file to copy."},
+							"destination": {Type: genai.TypeString, Description: "The path to copy the file to."},
+							"overwrite":   {Type: genai.TypeBoolean, Description: "Replace the destination if it already exists."},
+						},
+						Required: []string{"source", "destination"},
+					},
+				},
+				{
+					Name:        "stat_file",
+					Description: "Returns a file's size in bytes, permissions, modification time, and whether it is a directory, a symlink, or a binary file. Use this before reading a file that may be large or binary.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"path": {Type: genai.TypeString, Description: "The path of the file or directory to inspect."},
+						},
+						Required: []string{"path"},
+					},
+				},
+				{
+					Name:        "open_in_editor",
+					Description: "Opens a file in the user's editor ($VISUAL, $EDITOR or VS Code) so they can review or continue working on it, without waiting for the editor to close. Use it when the user asks to see or edit a file you created or changed.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"path": {Type: genai.TypeString, Description: "The path of the file to open."},
+						},
+						Required: []string{"path"},
+					},
+				},
+				{
+					Name:        "fetch_url",
+					Description: "Downloads a text document, such as a spec, README or example, over http or https and returns its content. Only allowlisted domains can be fetched, and binary or oversized responses are rejected.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"url": {Type: genai.TypeString, Description: "The http or https URL to fetch."},
+						},
+						Required: []string{"url"},
+					},
+				},
+				{
+					Name:        "git_diff",
+					Description: "Shows the unified diff of uncommitted changes in the git repository. Use this to review your own edits before suggesting a commit.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"staged": {Type: genai.TypeBoolean, Description: "Show staged changes (git diff --staged) instead of unstaged ones."},
+							"path":   {Type: genai.TypeString, Description: "Optional file or directory to limit the diff to."},
+						},
+					},
+				},
+				{
+					Name:        "undo_last_change",
+					Description: "Reverts the most recent file change made by create_file, update_file, delete_file, copy_file or generate_web_file, restoring the previous content or removing a newly created file. Can be called repeatedly to step further back. Moves done with move_go_file cannot be undone.",
+				},
+				{
+					Name:        "move_go_file",
+					Description: "Moves a Go source file to another package directory, rewriting its package clause and updating imports and references in files that use its symbols. Use this instead of create_file/delete_file when relocating Go code.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"source":      {Type: genai.TypeString, Description: "The path of the Go file to move."},
+							"destination": {Type: genai.TypeString, Description: "The target package directory, or the full target file path ending in .go."},
+						},
+						Required: []string{"source", "destination"},
+					},
+				},
 				{
 					Name:        "analyze_project",
 					Description: "Analyzes the current project structure, detects programming language, framework, dependencies, and provides context about the project.",
 					Parameters: &genai.Schema{
 						Type: genai.TypeObject,
 						Properties: map[string]*genai.Schema{
-							"path": {Type: genai.TypeString, Description: "The root path of the project to analyze. Use '.' for current directory."},
+							"path":     {Type: genai.TypeString, Description: "The root path of the project to analyze. Use '.' for current directory."},
+							"detailed": {Type: genai.TypeBoolean, Description: "Return the full analysis as JSON, including every file path, instead of a summary."},
+							"merge":    {Type: genai.TypeBoolean, Description: "Merge the findings for this path (e.g. a subdirectory) into the current project context instead of replacing it."},
 						},
 						Required: []string{"path"},
 					},
@@ -104,7 +228,7 @@ func defineTools() []*genai.Tool {
 					Parameters: &genai.Schema{
 						Type: genai.TypeObject,
 						Properties: map[string]*genai.Schema{
-							"type":        {Type: genai.TypeString, Description: "Type of code to generate: 'function', 'class', 'test', 'config'."},
+							"type":        {Type: genai.TypeString, Description: "Type of code to generate: 'function', 'class', 'test', 'config'. For 'config', the name selects the file: makefile, dockerfile, gitignore, readme, editorconfig, github_actions (a CI workflow for .github/workflows/ci.yml) or license (spec may set license to MIT, Apache-2.0 or GPL-3.0, plus year and author; MIT renders the full LICENSE, the others render the notice for NOTICE or source headers). For gitignore, set merge to true in the spec to add only the missing entries to the existing file."},
 							"name":        {Type: genai.TypeString, Description: "Name of the item to generate."},
 							"description": {Type: genai.TypeString, Description: "Description of what the code should do."},
 							"spec":        {Type: genai.TypeString, Description: "JSON specification for the code (parameters, fields, options)."},
@@ -112,6 +236,22 @@ func defineTools() []*genai.Tool {
 						Required: []string{"type", "name", "description"},
 					},
 				},
+				{
+					Name:        "scaffold_project",
+					Description: "Creates the standard files for a new project in one step: the manifest (go.mod, package.json, requirements.txt or Cargo.toml), an entry point, a Makefile and a .gitignore, plus an optional Dockerfile. Existing files are not overwritten.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"language":     {Type: genai.TypeString, Description: "go, javascript, typescript, python or rust."},
+							"project_type": {Type: genai.TypeString, Description: "cli (default), library or web."},
+							"name":         {Type: genai.TypeString, Description: "Project name, used for the package and binary names."},
+							"module":       {Type: genai.TypeString, Description: "Go module path, e.g. github.com/user/project (defaults to the name)."},
+							"path":         {Type: genai.TypeString, Description: "Directory to create the project in (defaults to the current directory)."},
+							"dockerfile":   {Type: genai.TypeBoolean, Description: "Also generate a Dockerfile."},
+						},
+						Required: []string{"language"},
+					},
+				},
 				{
 					Name:        "install_dependencies",
 					Description: "Installs project dependencies using the appropriate package manager.",
@@ -132,6 +272,16 @@ func defineTools() []*genai.Tool {
 						},
 					},
 				},
+				{
+					Name:        "lint_project",
+					Description: "Runs the project's linter (golangci-lint, golint or go vet for Go; ESLint for JavaScript/TypeScript; flake8 or pylint for Python; clippy for Rust) and returns its findings.",
+					Parameters: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"linter": {Type: genai.TypeString, Description: "Linter to use instead of the detected one (optional): golangci-lint, golint, go vet, eslint, pylint, flake8, clippy or rustfmt."},
+						},
+					},
+				},
 				{
 					Name:        "build_project",
 					Description: "Builds the project using the appropriate build tool.",
@@ -160,10 +310,14 @@ func defineTools() []*genai.Tool {
 	}
 }
 
-func generateToolDefinitions() string {
+// generateToolDefinitions lists the given tools for the system prompt. Pass
+// the tools registered on the model so disabled tools are not advertised.
+func generateToolDefinitions(tools []*genai.Tool) string {
+	if len(tools) == 0 {
+		return "No tools are available in this session. Answer from the conversation and project context only, and give the user any commands or file changes to apply themselves.\n"
+	}
 	var builder strings.Builder
 	builder.WriteString("**Available Tools:**\n\n")
-	tools := defineTools()
 	for _, tool := range tools {
 		for _, decl := range tool.FunctionDeclarations {
 			builder.WriteString(fmt.Sprintf("- **%s**: %s\n", decl.Name, decl.Description))
@@ -177,55 +331,117 @@ type ToolExecutor struct {
 	projectInfo *agent.ProjectInfo
 	analyzer    *agent.ProjectAnalyzer
 	generator   *agent.CodeGenerator
+	rootPath    string        // project root that file changes are confined to
+	log         *logger.Entry // tagged with the tool currently being executed
 }
 
+// NewToolExecutor creates an executor working in config.ProjectRoot, or the
+// current directory when no root is configured.
 func NewToolExecutor(config *config.Config) *ToolExecutor {
-	cwd, _ := os.Getwd()
-	analyzer := agent.NewProjectAnalyzer(cwd)
-	
+	root := config.ProjectRoot
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	analyzer := agent.NewProjectAnalyzer(root)
+	analyzer.IgnorePatterns = config.Agent.IgnorePatterns
+
 	return &ToolExecutor{
 		config:   config,
 		analyzer: analyzer,
+		rootPath: root,
 	}
 }
 
-// executeTool is a dispatcher that calls the appropriate Go function for a given tool name.
+// Execute runs the tool named by the function call, logging the call and its result.
 func (e *ToolExecutor) Execute(fc genai.FunctionCall) (string, error) {
+	e.log = logger.WithFields(map[string]interface{}{"tool": fc.Name})
+	logger.LogToolCall(fc.Name, loggedArgs(fc.Args))
+
+	output, err := e.execute(fc)
+	var loggedErr error
+	if err != nil {
+		loggedErr = errors.New(truncateMiddle(err.Error(), maxLoggedBytes))
+	}
+	logger.LogToolResult(fc.Name, err == nil, truncateMiddle(output, maxLoggedBytes), loggedErr)
+	return output, err
+}
+
+// execute checks that the tool is enabled, runs it and caps its result
+func (e *ToolExecutor) execute(fc genai.FunctionCall) (string, error) {
+	if !toolEnabled(fc.Name, e.config.Agent) {
+		e.log.Warn("Rejected call to disabled tool")
+		return "", &ToolError{Tool: fc.Name, Kind: ToolErrorBlocked, Err: fmt.Errorf("tool '%s' is disabled by configuration", fc.Name)}
+	}
+	output, err := e.dispatch(fc)
+	if err != nil {
+		toolErr := newToolError(fc.Name, err)
+		toolErr.Err = e.capError(toolErr.Err)
+		return e.capOutput(output), toolErr
+	}
+	return e.capOutput(output), nil
+}
+
+// dispatch runs the tool named by the function call
+func (e *ToolExecutor) dispatch(fc genai.FunctionCall) (string, error) {
 	switch fc.Name {
 	case "execute_shell_command":
 		if command, ok := fc.Args["command"].(string); ok {
-			return commander.ExecuteCommand(command, e.config.AllowedCommands)
+			env, err := parseEnvArg(fc.Args["env"])
+			if err != nil {
+				return "", err
+			}
+			return e.runShellCommand(command, env)
 		}
-		return "", fmt.Errorf("invalid or missing 'command' argument")
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'command' argument")
 	case "create_file", "update_file":
 		path, okPath := fc.Args["path"].(string)
 		content, okContent := fc.Args["content"].(string)
 		if !okPath || !okContent {
-			return "", fmt.Errorf("invalid arguments for %s", fc.Name)
+			return "", toolErrorf(ToolErrorValidation, "invalid arguments for %s", fc.Name)
 		}
-		err := os.WriteFile(path, []byte(content), 0644)
+		target, err := e.confinePath(path)
 		if err != nil {
 			return "", err
 		}
+		warning := detectSecretWrite(path, content)
+		if warning != "" {
+			e.log.Warn("Secret guard: %s", warning)
+			if e.config.Agent.SafetyMode {
+				return "", toolErrorf(ToolErrorBlocked, "%s; refusing to write it while safety mode is enabled. Use placeholder values or ask the user to confirm and write the file themselves", warning)
+			}
+		}
+		if e.config.Agent.DryRun {
+			return dryRunResult("would %s '%s' with %d bytes", strings.TrimSuffix(fc.Name, "_file"), path, len(content)), nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		e.snapshotFile(target)
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return "", err
+		}
+		if warning != "" {
+			return fmt.Sprintf("File '%s' was %sd successfully.\nWarning: %s. Make sure it is listed in .gitignore.", path, fc.Name, warning), nil
+		}
 		return fmt.Sprintf("File '%s' was %sd successfully.", path, fc.Name), nil
 	case "read_file":
+		return e.readFile(fc)
+	case "delete_file":
 		if path, ok := fc.Args["path"].(string); ok {
-			content, err := os.ReadFile(path)
+			target, err := e.confinePath(path)
 			if err != nil {
 				return "", err
 			}
-			return string(content), nil
-		}
-		return "", fmt.Errorf("invalid or missing 'path' argument")
-	case "delete_file":
-		if path, ok := fc.Args["path"].(string); ok {
-			err := os.Remove(path)
-			if err != nil {
+			if e.config.Agent.DryRun {
+				return dryRunResult("would delete '%s'", path), nil
+			}
+			e.snapshotFile(target)
+			if err := os.Remove(target); err != nil {
 				return "", err
 			}
 			return "File deleted successfully.", nil
 		}
-		return "", fmt.Errorf("invalid or missing 'path' argument")
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'path' argument")
 	case "list_files":
 		if path, ok := fc.Args["path"].(string); ok {
 			files, err := os.ReadDir(path)
@@ -238,57 +454,165 @@ func (e *ToolExecutor) Execute(fc genai.FunctionCall) (string, error) {
 			}
 			return strings.Join(fileNames, "\n"), nil
 		}
-		return "", fmt.Errorf("invalid or missing 'path' argument")
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'path' argument")
+	case "copy_file":
+		return e.copyFile(fc)
+	case "stat_file":
+		if path, ok := fc.Args["path"].(string); ok {
+			return statFile(path)
+		}
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'path' argument")
+	case "undo_last_change":
+		if e.config.Agent.DryRun {
+			return dryRunResult("would undo the last file change"), nil
+		}
+		return UndoLastChange()
+	case "git_diff":
+		return e.gitDiff(fc)
+	case "fetch_url":
+		return e.fetchURL(fc)
+	case "run_background_command":
+		return e.runBackgroundCommand(fc)
+	case "tail_background_output":
+		return e.tailBackgroundOutput(fc)
+	case "stop_background_command":
+		return e.stopBackgroundCommand(fc)
+	case "open_in_editor":
+		return e.openInEditor(fc)
+	case "move_go_file":
+		return e.moveGoFile(fc)
 	case "analyze_project":
 		if path, ok := fc.Args["path"].(string); ok {
-			return e.analyzeProject(path)
+			detailed, _ := fc.Args["detailed"].(bool)
+			merge, _ := fc.Args["merge"].(bool)
+			return e.analyzeProject(path, detailed, merge)
 		}
-		return "", fmt.Errorf("invalid or missing 'path' argument")
+		return "", toolErrorf(ToolErrorValidation, "invalid or missing 'path' argument")
 	case "generate_code":
 		return e.generateCode(fc)
+	case "scaffold_project":
+		return e.scaffoldProject(fc)
 	case "install_dependencies":
 		return e.installDependencies(fc)
 	case "run_tests":
 		return e.runTests(fc)
+	case "lint_project":
+		return e.lintProject(fc)
 	case "build_project":
 		return e.buildProject(fc)
 	case "generate_web_file":
 		return e.generateWebFile(fc)
 	default:
-		return "", fmt.Errorf("unknown function call: %s", fc.Name)
+		return "", toolErrorf(ToolErrorValidation, "unknown function call: %s", fc.Name)
+	}
+}
+
+// runCommand executes an allowlisted shell command, or only describes it in dry-run mode
+func (e *ToolExecutor) runCommand(command string) (string, error) {
+	return e.runCommandWithEnv(command, nil)
+}
+
+// runCommandWithEnv is like runCommand but sets extra environment variables.
+// Only the variable names are logged.
+func (e *ToolExecutor) runCommandWithEnv(command string, env map[string]string) (string, error) {
+	if e.config.Agent.DryRun {
+		if len(env) > 0 {
+			return dryRunResult("would run: %s (with %s set)", command, envNames(env)), nil
+		}
+		return dryRunResult("would run: %s", command), nil
+	}
+	if len(env) > 0 {
+		e.log.Debug("Running command with environment variables: %s", envNames(env))
+	}
+	return commander.ExecuteCommandWithEnv(command, e.config.AllowedCommands, env)
+}
+
+// runShellCommand runs a command for execute_shell_command and reports its
+// stdout and stderr in labelled sections.
+func (e *ToolExecutor) runShellCommand(command string, env map[string]string) (string, error) {
+	if e.config.Agent.DryRun {
+		return e.runCommandWithEnv(command, env)
+	}
+	if len(env) > 0 {
+		e.log.Debug("Running command with environment variables: %s", envNames(env))
+	}
+	output, err := commander.ExecuteCommandSeparate(command, e.config.AllowedCommands, env)
+	return output.String(), err
+}
+
+// dryRunResult describes an action skipped because dry-run mode is enabled
+func dryRunResult(format string, args ...interface{}) string {
+	return "[dry-run] Nothing was changed; " + fmt.Sprintf(format, args...) + "."
+}
+
+// moveGoFile moves a Go file between packages and fixes up references to it
+func (e *ToolExecutor) moveGoFile(fc genai.FunctionCall) (string, error) {
+	source, ok1 := fc.Args["source"].(string)
+	destination, ok2 := fc.Args["destination"].(string)
+	if !ok1 || !ok2 {
+		return "", toolErrorf(ToolErrorValidation, "missing required arguments for move_go_file")
+	}
+
+	if _, err := e.confinePath(source); err != nil {
+		return "", err
+	}
+	if _, err := e.confinePath(destination); err != nil {
+		return "", err
+	}
+	if e.config.Agent.DryRun {
+		return dryRunResult("would move '%s' to '%s' and update the package clause and importers", source, destination), nil
 	}
+
+	e.log.Info("Moving Go file %s to %s", source, destination)
+	result, err := agent.MoveGoFile(e.rootPath, source, destination)
+	if err != nil {
+		e.log.Error("Moving Go file failed: %v", err)
+		return "", fmt.Errorf("failed to move %s: %w", source, err)
+	}
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to format move result: %w", err)
+	}
+	return fmt.Sprintf("Moved Go file:\n%s", string(output)), nil
 }
 
-// analyzeProject analyzes the project structure and provides context
-func (e *ToolExecutor) analyzeProject(path string) (string, error) {
-	logger.Info("Analyzing project at path: %s", path)
+// analyzeProject analyzes the project structure and provides context.
+// With detailed set the full ProjectInfo is returned as JSON, otherwise a summary.
+// With merge set the findings are added to the current context instead of replacing it.
+func (e *ToolExecutor) analyzeProject(path string, detailed, merge bool) (string, error) {
+	e.log.Info("Analyzing project at path: %s", path)
 	
 	if path == "." {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return "", fmt.Errorf("failed to get current directory: %w", err)
-		}
-		path = cwd
+		path = e.rootPath
 	}
 	
 	analyzer := agent.NewProjectAnalyzer(path)
-	projectInfo, err := analyzer.AnalyzeProject()
+	analyzer.IgnorePatterns = e.config.Agent.IgnorePatterns
+	projectInfo, err := analyzer.AnalyzeProject(context.Background())
 	if err != nil {
-		logger.Error("Project analysis failed: %v", err)
+		e.log.Error("Project analysis failed: %v", err)
 		return "", fmt.Errorf("project analysis failed: %w", err)
 	}
 	
+	if merge && e.projectInfo != nil {
+		projectInfo = e.projectInfo.Merge(projectInfo)
+	}
+
 	// Cache the project info for future use
 	e.projectInfo = projectInfo
 	e.generator = agent.NewCodeGenerator(projectInfo)
 	
+	e.log.Info("Project analysis completed successfully for %s project", projectInfo.Language)
+	if !detailed {
+		return fmt.Sprintf("Project Analysis Results:\n%s", projectInfo.Summary()), nil
+	}
+
 	// Format the analysis result
 	result, err := json.MarshalIndent(projectInfo, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to format analysis result: %w", err)
 	}
-	
-	logger.Info("Project analysis completed successfully for %s project", projectInfo.Language)
 	return fmt.Sprintf("Project Analysis Results:\n%s", string(result)), nil
 }
 
@@ -299,21 +623,22 @@ func (e *ToolExecutor) generateCode(fc genai.FunctionCall) (string, error) {
 	description, ok3 := fc.Args["description"].(string)
 	
 	if !ok1 || !ok2 || !ok3 {
-		return "", fmt.Errorf("missing required arguments for code generation")
+		return "", toolErrorf(ToolErrorValidation, "missing required arguments for code generation")
 	}
 	
 	// Ensure we have project context
 	if e.generator == nil {
 		// Analyze project first
-		if _, err := e.analyzeProject("."); err != nil {
+		if _, err := e.analyzeProject(".", false, false); err != nil {
 			return "", fmt.Errorf("failed to analyze project context: %w", err)
 		}
 	}
 	
-	logger.Info("Generating %s code: %s", codeType, name)
+	e.log.Info("Generating %s code: %s", codeType, name)
 	
 	var code string
 	var filename string
+	var note string
 	var err error
 	
 	switch strings.ToLower(codeType) {
@@ -356,25 +681,34 @@ func (e *ToolExecutor) generateCode(fc genai.FunctionCall) (string, error) {
 		options := make(map[string]interface{})
 		if spec, ok := fc.Args["spec"].(string); ok && spec != "" {
 			if err := json.Unmarshal([]byte(spec), &options); err != nil {
-				logger.Warn("Failed to parse config spec: %v", err)
+				e.log.Warn("Failed to parse config spec: %v", err)
 			}
 		}
 		code, err = e.generator.GenerateConfigFile(name, options)
-		filename = name
+		filename = e.generator.GetSuggestedConfigFilename(name)
+		if name == "license" && err == nil {
+			id, _ := options["license"].(string)
+			if filename = agent.LicenseFilename(id); filename != "LICENSE" {
+				note = fmt.Sprintf("\n\nThis is the %s notice, not the full license; put the official license text in LICENSE.", id)
+			}
+		}
+		if merge, _ := options["merge"].(bool); merge && name == "gitignore" && err == nil {
+			code, _, err = e.mergeGitignore(filename, code)
+		}
 		
 	default:
-		return "", fmt.Errorf("unsupported code type: %s", codeType)
+		return "", toolErrorf(ToolErrorValidation, "unsupported code type: %s", codeType)
 	}
 	
 	if err != nil {
-		logger.Error("Code generation failed: %v", err)
+		e.log.Error("Code generation failed: %v", err)
 		return "", fmt.Errorf("code generation failed: %w", err)
 	}
 	
 	result := fmt.Sprintf("Generated %s code for '%s':\n\nSuggested filename: %s\n\nCode:\n```\n%s\n```", 
-		codeType, name, filename, code)
+		codeType, name, filename, code) + note
 	
-	logger.Info("Code generation completed successfully")
+	e.log.Info("Code generation completed successfully")
 	return result, nil
 }
 
@@ -382,7 +716,7 @@ func (e *ToolExecutor) generateCode(fc genai.FunctionCall) (string, error) {
 func (e *ToolExecutor) installDependencies(fc genai.FunctionCall) (string, error) {
 	// Ensure we have project context
 	if e.projectInfo == nil {
-		if _, err := e.analyzeProject("."); err != nil {
+		if _, err := e.analyzeProject(".", false, false); err != nil {
 			return "", fmt.Errorf("failed to analyze project context: %w", err)
 		}
 	}
@@ -431,15 +765,15 @@ func (e *ToolExecutor) installDependencies(fc genai.FunctionCall) (string, error
 		return "", fmt.Errorf("unknown package manager: %s", e.projectInfo.PackageManager)
 	}
 	
-	logger.Info("Installing dependencies with command: %s", command)
-	return commander.ExecuteCommand(command, e.config.AllowedCommands)
+	e.log.Info("Installing dependencies with command: %s", command)
+	return e.runCommand(command)
 }
 
 // runTests runs the project's test suite
 func (e *ToolExecutor) runTests(fc genai.FunctionCall) (string, error) {
 	// Ensure we have project context
 	if e.projectInfo == nil {
-		if _, err := e.analyzeProject("."); err != nil {
+		if _, err := e.analyzeProject(".", false, false); err != nil {
 			return "", fmt.Errorf("failed to analyze project context: %w", err)
 		}
 	}
@@ -450,9 +784,9 @@ func (e *ToolExecutor) runTests(fc genai.FunctionCall) (string, error) {
 	switch e.projectInfo.Language {
 	case "Go":
 		if pattern != "" {
-			command = fmt.Sprintf("go test %s", pattern)
+			command = fmt.Sprintf("go test -v %s", pattern)
 		} else {
-			command = "go test ./..."
+			command = "go test -v ./..."
 		}
 	case "JavaScript", "TypeScript":
 		if e.projectInfo.TestFramework == "Jest" {
@@ -484,15 +818,15 @@ func (e *ToolExecutor) runTests(fc genai.FunctionCall) (string, error) {
 		return "", fmt.Errorf("testing not supported for language: %s", e.projectInfo.Language)
 	}
 	
-	logger.Info("Running tests with command: %s", command)
-	return commander.ExecuteCommand(command, e.config.AllowedCommands)
+	e.log.Info("Running tests with command: %s", command)
+	return summarizeTestRun(e.runCommand(command))
 }
 
 // buildProject builds the project
 func (e *ToolExecutor) buildProject(fc genai.FunctionCall) (string, error) {
 	// Ensure we have project context
 	if e.projectInfo == nil {
-		if _, err := e.analyzeProject("."); err != nil {
+		if _, err := e.analyzeProject(".", false, false); err != nil {
 			return "", fmt.Errorf("failed to analyze project context: %w", err)
 		}
 	}
@@ -530,8 +864,8 @@ func (e *ToolExecutor) buildProject(fc genai.FunctionCall) (string, error) {
 		return "", fmt.Errorf("building not supported for language: %s", e.projectInfo.Language)
 	}
 	
-	logger.Info("Building project with command: %s", command)
-	return commander.ExecuteCommand(command, e.config.AllowedCommands)
+	e.log.Info("Building project with command: %s", command)
+	return e.runCommand(command)
 }
 
 // generateWebFile generates web files using unique patterns to avoid recitation blocks
@@ -540,23 +874,23 @@ func (e *ToolExecutor) generateWebFile(fc genai.FunctionCall) (string, error) {
 	filename, ok2 := fc.Args["filename"].(string)
 	
 	if !ok1 || !ok2 {
-		return "", fmt.Errorf("missing required arguments for web file generation")
+		return "", toolErrorf(ToolErrorValidation, "missing required arguments for web file generation")
 	}
 	
 	// Ensure we have project context
 	if e.generator == nil {
-		if _, err := e.analyzeProject("."); err != nil {
+		if _, err := e.analyzeProject(".", false, false); err != nil {
 			return "", fmt.Errorf("failed to analyze project context: %w", err)
 		}
 	}
 	
-	logger.Info("Generating %s web file: %s", fileType, filename)
+	e.log.Info("Generating %s web file: %s", fileType, filename)
 	
 	// Parse options if provided
 	options := make(map[string]interface{})
 	if optionsStr, ok := fc.Args["options"].(string); ok && optionsStr != "" {
 		if err := json.Unmarshal([]byte(optionsStr), &options); err != nil {
-			logger.Warn("Failed to parse options: %v, using defaults", err)
+			e.log.Warn("Failed to parse options: %v, using defaults", err)
 		}
 	}
 	
@@ -571,15 +905,23 @@ func (e *ToolExecutor) generateWebFile(fc genai.FunctionCall) (string, error) {
 	// Generate the web file content
 	content, err := e.generator.GenerateWebFile(fileType, options)
 	if err != nil {
-		logger.Error("Web file generation failed: %v", err)
+		e.log.Error("Web file generation failed: %v", err)
 		return "", fmt.Errorf("web file generation failed: %w", err)
 	}
 	
 	// Write the file
-	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+	target, err := e.confinePath(filename)
+	if err != nil {
+		return "", err
+	}
+	if e.config.Agent.DryRun {
+		return dryRunResult("would write %s file '%s' with %d bytes", fileType, filename, len(content)), nil
+	}
+	e.snapshotFile(target)
+	if err := os.WriteFile(target, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write file %s: %w", filename, err)
 	}
 	
-	logger.Info("Web file generation completed successfully: %s", filename)
+	e.log.Info("Web file generation completed successfully: %s", filename)
 	return fmt.Sprintf("Generated unique %s file '%s' successfully using Console Buddy templates to avoid recitation issues.", fileType, filename), nil
 }
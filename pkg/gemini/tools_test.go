@@ -0,0 +1,224 @@
+package gemini
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/config"
+	"console-ai/pkg/logger"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// testConfig returns the default configuration without an API key
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig("")
+	if err != nil && !errors.Is(err, config.ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// newTestExecutor returns an executor working in a new temporary project
+// root, which is also made the working directory of the test
+func newTestExecutor(t *testing.T) (*ToolExecutor, string) {
+	t.Helper()
+	root := t.TempDir()
+	t.Chdir(root)
+	cfg := testConfig(t)
+	cfg.ProjectRoot = root
+	return NewToolExecutor(cfg), root
+}
+
+// call executes the named tool with args
+func call(e *ToolExecutor, name string, args map[string]any) (string, error) {
+	return e.Execute(genai.FunctionCall{Name: name, Args: args})
+}
+
+func TestExecuteTagsLogsWithToolName(t *testing.T) {
+	var buf bytes.Buffer
+	if err := logger.Initialize(&logger.Config{Level: logger.DEBUG, Output: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Initialize(&logger.Config{Level: logger.FATAL, Output: io.Discard})
+
+	e, _ := newTestExecutor(t)
+	e.config.Agent.DisabledTools = []string{"delete_file"}
+	call(e, "delete_file", map[string]any{"path": "main.go"})
+
+	if out := buf.String(); !strings.Contains(out, "Rejected call to disabled tool tool=delete_file") {
+		t.Errorf("log = %q, want the tool name as a field", out)
+	}
+}
+
+func TestDryRunWritesNothing(t *testing.T) {
+	e, root := newTestExecutor(t)
+	e.config.Agent.DryRun = true
+	if err := os.WriteFile("existing.txt", []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := []struct {
+		name string
+		args map[string]any
+	}{
+		{"create_file", map[string]any{"path": "new/file.txt", "content": "hello"}},
+		{"update_file", map[string]any{"path": "existing.txt", "content": "changed"}},
+		{"delete_file", map[string]any{"path": "existing.txt"}},
+		{"copy_file", map[string]any{"source": "existing.txt", "destination": "copy.txt"}},
+		{"execute_shell_command", map[string]any{"command": "mkdir made-by-command"}},
+	}
+	for _, c := range calls {
+		out, err := call(e, c.name, c.args)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !strings.HasPrefix(out, "[dry-run]") {
+			t.Errorf("%s = %q, want a dry-run description", c.name, out)
+		}
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "existing.txt" {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("project contains %q, want only existing.txt", names)
+	}
+	if content, _ := os.ReadFile("existing.txt"); string(content) != "keep me" {
+		t.Errorf("existing.txt = %q, want it unchanged", content)
+	}
+
+	// Read-only tools still run
+	if out, err := call(e, "read_file", map[string]any{"path": "existing.txt"}); err != nil || !strings.Contains(out, "keep me") {
+		t.Errorf("read_file in dry-run = %q, %v", out, err)
+	}
+}
+
+func TestAnalyzeProjectMerge(t *testing.T) {
+	e, root := newTestExecutor(t)
+	files := map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.22\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+		"main.go":          "package main\n",
+		"web/package.json": `{"dependencies": {"react": "^18.0.0"}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := call(e, "analyze_project", map[string]any{"path": "."}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := call(e, "analyze_project", map[string]any{"path": "web", "merge": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "github.com/gin-gonic/gin, react") || !strings.Contains(out, "- Language: Go") {
+		t.Errorf("merged analysis = %s", out)
+	}
+
+	out, err = call(e, "analyze_project", map[string]any{"path": "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "gin") {
+		t.Errorf("analysis without merge kept the old context:\n%s", out)
+	}
+}
+
+func TestGenerateLicenseFilename(t *testing.T) {
+	e, _ := newTestExecutor(t)
+
+	out, err := call(e, "generate_code", map[string]any{"type": "config", "name": "license", "description": "license", "spec": `{"license": "MIT"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Suggested filename: LICENSE\n") {
+		t.Errorf("MIT result does not suggest LICENSE:\n%s", out)
+	}
+
+	out, err = call(e, "generate_code", map[string]any{"type": "config", "name": "license", "description": "license", "spec": `{"license": "GPL-3.0"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Suggested filename: NOTICE\n") || !strings.Contains(out, "not the full license") {
+		t.Errorf("GPL result does not suggest a NOTICE:\n%s", out)
+	}
+}
+
+func TestCreateFileMakesWorkflowDirectories(t *testing.T) {
+	e, root := newTestExecutor(t)
+	path := filepath.Join(".github", "workflows", "ci.yml")
+
+	if _, err := call(e, "create_file", map[string]any{"path": path, "content": "name: CI\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, path)); err != nil || string(data) != "name: CI\n" {
+		t.Errorf("workflow = %q, %v", data, err)
+	}
+}
+
+func TestExecutorUsesProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+	cfg := testConfig(t)
+	cfg.ProjectRoot = root
+	e := NewToolExecutor(cfg)
+
+	out, err := call(e, "analyze_project", map[string]any{"path": "."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Go") {
+		t.Errorf("analysis of the project root = %q, want a Go project", out)
+	}
+
+	if _, err := call(e, "create_file", map[string]any{"path": "main.go", "content": "package main\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "main.go")); err != nil {
+		t.Errorf("file was not created in the project root: %v", err)
+	}
+	if _, err := call(e, "create_file", map[string]any{"path": filepath.Join("..", "outside.go"), "content": "package main\n"}); err == nil {
+		t.Error("a file outside the project root was created")
+	}
+}
+
+func TestShellCommandReportsExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	e, _ := newTestExecutor(t)
+	e.config.AllowedCommands = []string{"true", "false", "exit"}
+
+	for command, want := range map[string]string{"true": "EXIT CODE: 0", "false": "EXIT CODE: 1", "exit 2": "EXIT CODE: 2"} {
+		output, err := call(e, "execute_shell_command", map[string]any{"command": command})
+		if !strings.Contains(output, want) {
+			t.Errorf("%s: output = %q, want %q", command, output, want)
+		}
+		if (err == nil) != (command == "true") {
+			t.Errorf("%s: error = %v", command, err)
+		}
+	}
+}
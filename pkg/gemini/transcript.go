@@ -0,0 +1,114 @@
+package gemini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"console-ai/pkg/config"
+	"console-ai/pkg/logger"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// transcriptDir holds the transcript files, one per process
+const transcriptDir = "transcripts"
+
+// transcriptWriter appends the raw exchange with the model to a timestamped
+// file when Config.Transcript is set. The file is created on first use.
+type transcriptWriter struct {
+	mu         sync.Mutex
+	file       *os.File
+	failed     bool
+	lastSystem string
+}
+
+// sessionTranscript is shared by all turns of the process
+var sessionTranscript = &transcriptWriter{}
+
+// write appends an entry under title. Nothing is written unless the
+// transcript is enabled in cfg.
+func (w *transcriptWriter) write(cfg *config.Config, title, content string) {
+	if !cfg.Transcript {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.open() {
+		return
+	}
+
+	entry := fmt.Sprintf("=== %s [%s] ===\n%s\n\n", title, time.Now().Format("15:04:05"), strings.TrimRight(content, "\n"))
+	if _, err := w.file.WriteString(entry); err != nil {
+		logger.Warn("Failed to write transcript: %v", err)
+	}
+}
+
+// open creates the transcript file if needed and reports whether it is usable.
+// After a failure the transcript stays off for the rest of the process.
+func (w *transcriptWriter) open() bool {
+	if w.file != nil {
+		return true
+	}
+	if w.failed {
+		return false
+	}
+
+	path := filepath.Join(transcriptDir, fmt.Sprintf("transcript-%s.log", time.Now().Format("20060102-150405")))
+	if err := os.MkdirAll(transcriptDir, 0755); err != nil {
+		logger.Warn("Transcript disabled, failed to create %s: %v", transcriptDir, err)
+		w.failed = true
+		return false
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		logger.Warn("Transcript disabled, failed to open %s: %v", path, err)
+		w.failed = true
+		return false
+	}
+	logger.Info("Writing transcript to %s", path)
+	w.file = file
+	return true
+}
+
+// systemInstruction records the model's system instruction when it differs
+// from the one recorded last, so unchanged prompts aren't repeated every turn.
+func (w *transcriptWriter) systemInstruction(cfg *config.Config, model *genai.GenerativeModel) {
+	if !cfg.Transcript || model.SystemInstruction == nil {
+		return
+	}
+
+	var parts []string
+	for _, part := range model.SystemInstruction.Parts {
+		parts = append(parts, formatPart(part))
+	}
+	prompt := strings.Join(parts, "\n")
+
+	w.mu.Lock()
+	changed := prompt != w.lastSystem
+	w.lastSystem = prompt
+	w.mu.Unlock()
+	if changed {
+		w.write(cfg, "System Instruction", prompt)
+	}
+}
+
+// wrap returns a step callback that also records tool calls, their results
+// and other notable steps. Streamed text is left to the final reply entry.
+func (w *transcriptWriter) wrap(cfg *config.Config, stepCallback func(title, content string)) func(title, content string) {
+	if !cfg.Transcript {
+		return stepCallback
+	}
+	return func(title, content string) {
+		switch title {
+		case StepThinking, StepResponse, StepTransformed:
+		default:
+			w.write(cfg, title, content)
+		}
+		stepCallback(title, content)
+	}
+}
@@ -0,0 +1,90 @@
+package gemini
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// useTranscript gives the test its own transcript writer and returns a
+// function reading what it wrote
+func useTranscript(t *testing.T) func() string {
+	t.Helper()
+	previous := sessionTranscript
+	writer := &transcriptWriter{}
+	sessionTranscript = writer
+	t.Cleanup(func() {
+		sessionTranscript = previous
+		if writer.file != nil {
+			writer.file.Close()
+		}
+	})
+	return func() string {
+		paths, _ := filepath.Glob(filepath.Join(transcriptDir, "transcript-*.log"))
+		if len(paths) != 1 {
+			t.Fatalf("found transcripts %q, want one", paths)
+		}
+		data, err := os.ReadFile(paths[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+func TestTranscriptCapturesTurn(t *testing.T) {
+	useFakeGemini(t,
+		reply(genai.FunctionCall{Name: "create_file", Args: map[string]interface{}{"path": "notes.txt", "content": "hi"}}),
+		reply(genai.Text("Created "), genai.Text("notes.txt.")),
+	)
+	useTransformers(t)
+	cfg := turnConfig(t)
+	cfg.Transcript = true
+	read := useTranscript(t)
+
+	if _, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "write notes", 0, cfg, noSteps); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript := read()
+	want := []string{
+		"=== User [",
+		"write notes",
+		"=== System Instruction [",
+		"=== " + StepToolCall + " [",
+		`create_file with args: {"content":"hi","path":"notes.txt"}`,
+		"=== " + StepToolOutput + " [",
+		"File 'notes.txt' was ",
+		"=== Model [",
+		"Created notes.txt.",
+	}
+	pos := 0
+	for _, part := range want {
+		i := strings.Index(transcript[pos:], part)
+		if i < 0 {
+			t.Fatalf("transcript is missing %q after position %d:\n%s", part, pos, transcript)
+		}
+		pos += i + len(part)
+	}
+	if strings.Contains(transcript, StepResponse) {
+		t.Errorf("transcript records streamed chunks:\n%s", transcript)
+	}
+}
+
+func TestTranscriptDisabled(t *testing.T) {
+	useFakeGemini(t, reply(genai.Text("Hi.")))
+	cfg := turnConfig(t)
+	cfg.Transcript = false
+	useTranscript(t)
+
+	if _, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "hi", 0, cfg, noSteps); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(transcriptDir); !os.IsNotExist(err) {
+		t.Errorf("transcript directory exists with transcripts disabled: %v", err)
+	}
+}
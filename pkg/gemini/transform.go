@@ -0,0 +1,107 @@
+package gemini
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"console-ai/pkg/config"
+)
+
+// ResponseTransformer adjusts a final reply before it is returned to the
+// caller and stored in the history. humorLevel is the level the turn ran with.
+type ResponseTransformer interface {
+	Transform(reply string, humorLevel int) string
+}
+
+// ResponseTransformerFunc adapts a plain function to a ResponseTransformer
+type ResponseTransformerFunc func(reply string, humorLevel int) string
+
+// Transform calls f(reply, humorLevel)
+func (f ResponseTransformerFunc) Transform(reply string, humorLevel int) string {
+	return f(reply, humorLevel)
+}
+
+var (
+	transformersMu sync.Mutex
+	transformers   []ResponseTransformer
+)
+
+// RegisterResponseTransformer adds a transformer to run on every final reply.
+// Transformers run in the order they were registered.
+func RegisterResponseTransformer(t ResponseTransformer) {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	transformers = append(transformers, t)
+}
+
+// ClearResponseTransformers removes all registered transformers
+func ClearResponseTransformers() {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	transformers = nil
+}
+
+// RegisterConfiguredTransformers registers the transformers enabled in cfg:
+// the emoji stripper for humor level 0 and the reply length limit.
+func RegisterConfiguredTransformers(cfg *config.Config) {
+	if cfg.Agent.StripEmoji {
+		RegisterResponseTransformer(StripEmojiTransformer(config.MinHumorLevel))
+	}
+	if cfg.Agent.MaxReplyChars > 0 {
+		RegisterResponseTransformer(MaxLengthTransformer(cfg.Agent.MaxReplyChars))
+	}
+}
+
+// transformResponse runs the registered transformers over reply
+func transformResponse(reply string, humorLevel int) string {
+	transformersMu.Lock()
+	registered := append([]ResponseTransformer(nil), transformers...)
+	transformersMu.Unlock()
+
+	for _, t := range registered {
+		reply = t.Transform(reply, humorLevel)
+	}
+	return reply
+}
+
+// MaxLengthTransformer truncates replies longer than maxRunes runes, marking
+// the cut with an ellipsis. A non-positive limit leaves replies alone.
+func MaxLengthTransformer(maxRunes int) ResponseTransformer {
+	return ResponseTransformerFunc(func(reply string, _ int) string {
+		runes := []rune(reply)
+		if maxRunes <= 0 || len(runes) <= maxRunes {
+			return reply
+		}
+		return strings.TrimRightFunc(string(runes[:maxRunes]), unicode.IsSpace) + "…"
+	})
+}
+
+// StripEmojiTransformer removes emoji from replies when the humor level is
+// at or below maxHumorLevel, e.g. 0 to keep strictly professional replies plain.
+func StripEmojiTransformer(maxHumorLevel int) ResponseTransformer {
+	return ResponseTransformerFunc(func(reply string, humorLevel int) string {
+		if humorLevel > maxHumorLevel {
+			return reply
+		}
+		return strings.Map(func(r rune) rune {
+			if isEmoji(r) {
+				return -1
+			}
+			return r
+		}, reply)
+	})
+}
+
+// isEmoji reports whether r falls in the common emoji and pictograph blocks
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F300 && r <= 0x1FAFF: // pictographs, emoticons, transport, supplemental symbols
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // miscellaneous symbols and dingbats
+		return true
+	case r == 0xFE0F || r == 0x200D: // variation selector and zero-width joiner
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// useTransformers registers transformers for the duration of the test
+func useTransformers(t *testing.T, transformers ...ResponseTransformer) {
+	t.Helper()
+	ClearResponseTransformers()
+	t.Cleanup(ClearResponseTransformers)
+	for _, transformer := range transformers {
+		RegisterResponseTransformer(transformer)
+	}
+}
+
+func TestMaxLengthTransformer(t *testing.T) {
+	truncate := MaxLengthTransformer(10)
+
+	if got := truncate.Transform("short", 0); got != "short" {
+		t.Errorf("short reply = %q, want it unchanged", got)
+	}
+	if got := truncate.Transform("the quick brown fox", 0); got != "the quick…" {
+		t.Errorf("long reply = %q, want %q", got, "the quick…")
+	}
+	if got := truncate.Transform("ünïcödé ünïcödé", 0); got != "ünïcödé ün…" {
+		t.Errorf("multibyte reply = %q, want whole runes kept", got)
+	}
+	if got := MaxLengthTransformer(0).Transform("the quick brown fox", 0); got != "the quick brown fox" {
+		t.Errorf("unlimited transformer truncated the reply to %q", got)
+	}
+}
+
+func TestTruncatingTransformerShortensReply(t *testing.T) {
+	useFakeGemini(t, reply(genai.Text("The answer is forty-two, "), genai.Text("as computed at length.")))
+	useTransformers(t, MaxLengthTransformer(20))
+	cfg := turnConfig(t)
+
+	var transformed []string
+	answer, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "question", 0, cfg, func(title, content string) {
+		if title == StepTransformed {
+			transformed = append(transformed, content)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "The answer is forty-…" {
+		t.Errorf("reply = %q, want it truncated", answer)
+	}
+	if len(transformed) != 1 || transformed[0] != answer {
+		t.Errorf("transformed steps = %q, want one with the reply", transformed)
+	}
+}
+
+func TestUnchangedReplyHasNoTransformedStep(t *testing.T) {
+	useFakeGemini(t, reply(genai.Text("Short.")))
+	useTransformers(t, MaxLengthTransformer(100))
+	cfg := turnConfig(t)
+
+	_, _, err := ContinueConversation(context.Background(), newTestModel(cfg), nil, nil, "question", 0, cfg, func(title, content string) {
+		if title == StepTransformed {
+			t.Errorf("got a transformed step for an unchanged reply: %q", content)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStripEmojiTransformer(t *testing.T) {
+	strip := StripEmojiTransformer(0)
+
+	if got := strip.Transform("Done 🎉 all good ✅", 0); got != "Done  all good " {
+		t.Errorf("humor 0 reply = %q, want the emoji removed", got)
+	}
+	if got := strip.Transform("Done 🎉", 40); got != "Done 🎉" {
+		t.Errorf("humor 40 reply = %q, want it unchanged", got)
+	}
+}
+
+func TestRegisterConfiguredTransformers(t *testing.T) {
+	useTransformers(t)
+	cfg := testConfig(t)
+	cfg.Agent.StripEmoji = true
+	cfg.Agent.MaxReplyChars = 8
+
+	RegisterConfiguredTransformers(cfg)
+	if got := transformResponse("🎉 Hello there", 0); got != " Hello t…" {
+		t.Errorf("humor 0 reply = %q", got)
+	}
+	if got := transformResponse("🎉 Hi", 50); got != "🎉 Hi" {
+		t.Errorf("humor 50 reply = %q, want the emoji kept", got)
+	}
+
+	ClearResponseTransformers()
+	cfg.Agent.StripEmoji = false
+	cfg.Agent.MaxReplyChars = 0
+	RegisterConfiguredTransformers(cfg)
+	if got := transformResponse("🎉 Hello there", 0); got != "🎉 Hello there" {
+		t.Errorf("reply = %q with no transformers configured", got)
+	}
+}
@@ -0,0 +1,67 @@
+package gemini
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxLoggedBytes bounds the tool arguments and results written to the log
+const maxLoggedBytes = 1024
+
+// loggedArgs returns the tool call arguments for the log, with secret
+// environment variable values masked and long strings such as file
+// contents truncated.
+func loggedArgs(args map[string]interface{}) map[string]interface{} {
+	var masked map[string]interface{}
+	if err := json.Unmarshal([]byte(displayArgs(args)), &masked); err != nil {
+		masked = args
+	}
+	logged := make(map[string]interface{}, len(masked))
+	for key, value := range masked {
+		if text, ok := value.(string); ok {
+			value = truncateMiddle(text, maxLoggedBytes)
+		}
+		logged[key] = value
+	}
+	return logged
+}
+
+// capOutput truncates a tool result to the configured MaxToolOutputBytes
+func (e *ToolExecutor) capOutput(output string) string {
+	limit := e.config.Agent.MaxToolOutputBytes
+	if limit <= 0 || len(output) <= limit {
+		return output
+	}
+	e.log.Warn("Tool output of %d bytes truncated to %d", len(output), limit)
+	return truncateMiddle(output, limit)
+}
+
+// capError truncates an oversized tool error, which may embed command output
+func (e *ToolExecutor) capError(err error) error {
+	limit := e.config.Agent.MaxToolOutputBytes
+	if err == nil || limit <= 0 || len(err.Error()) <= limit {
+		return err
+	}
+	return errors.New(truncateMiddle(err.Error(), limit))
+}
+
+// truncateMiddle keeps the head and tail of s within about limit bytes and
+// replaces the middle with a marker saying how much was left out.
+func truncateMiddle(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+
+	head := limit / 2
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	tail := len(s) - (limit - limit/2)
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+
+	return fmt.Sprintf("%s\n[output truncated, %d bytes omitted]\n%s", s[:head], tail-head, s[tail:])
+}
@@ -0,0 +1,65 @@
+package gemini
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateMiddleKeepsHeadAndTail(t *testing.T) {
+	s := "HEAD" + strings.Repeat("x", 1000) + "TAIL"
+	got := truncateMiddle(s, 100)
+
+	if !strings.HasPrefix(got, "HEAD") || !strings.HasSuffix(got, "TAIL") {
+		t.Errorf("head or tail lost: %q", got)
+	}
+	if !strings.Contains(got, "[output truncated, 908 bytes omitted]") {
+		t.Errorf("marker missing or wrong: %q", got)
+	}
+	if len(got)-len("\n[output truncated, 908 bytes omitted]\n") != 100 {
+		t.Errorf("kept %d bytes, want 100", len(got))
+	}
+}
+
+func TestTruncateMiddleKeepsRunesWhole(t *testing.T) {
+	got := truncateMiddle(strings.Repeat("日本語", 100), 50)
+	if !utf8.ValidString(got) {
+		t.Errorf("truncation split a character: %q", got)
+	}
+}
+
+func TestSmallOutputIsUntouched(t *testing.T) {
+	if got := truncateMiddle("short", 100); got != "short" {
+		t.Errorf("truncateMiddle = %q", got)
+	}
+
+	e, _ := newTestExecutor(t)
+	content := strings.Repeat("a", 1000)
+	if err := os.WriteFile("small.txt", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := call(e, "read_file", map[string]any{"path": "small.txt"}); err != nil || out != content {
+		t.Errorf("read_file changed small output (%d bytes), %v", len(out), err)
+	}
+}
+
+func TestExecuteCapsOversizedOutput(t *testing.T) {
+	e, _ := newTestExecutor(t)
+	e.config.Agent.MaxToolOutputBytes = 1024
+	content := "first line\n" + strings.Repeat("0123456789", 1000) + "\nlast line"
+	if err := os.WriteFile("big.log", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := call(e, "read_file", map[string]any{"path": "big.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "first line") || !strings.HasSuffix(out, "last line") {
+		t.Error("capped output lost its head or tail")
+	}
+	if !strings.Contains(out, "bytes omitted]") || len(out) > 1024+100 {
+		t.Errorf("output of %d bytes was not capped", len(out))
+	}
+}
@@ -0,0 +1,92 @@
+package gemini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxUndoSnapshots bounds how many file changes can be undone
+const maxUndoSnapshots = 20
+
+// fileSnapshot is the state of a file before a tool changed it
+type fileSnapshot struct {
+	path    string
+	existed bool
+	content []byte
+	mode    os.FileMode
+}
+
+// The snapshot stack lives for the whole process so changes made in earlier
+// turns can still be undone; a new ToolExecutor is created for every turn.
+var (
+	snapshotsMu sync.Mutex
+	snapshots   []fileSnapshot
+)
+
+// snapshotFile records the current state of path so the next change to it
+// can be undone. Failing to take a snapshot doesn't block the change.
+func (e *ToolExecutor) snapshotFile(path string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		e.log.Warn("Failed to snapshot %s for undo: %v", path, err)
+		return
+	}
+
+	snapshot := fileSnapshot{path: abs}
+	info, err := os.Stat(abs)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		e.log.Warn("Failed to snapshot %s for undo: %v", path, err)
+		return
+	case info.IsDir():
+		return
+	default:
+		content, err := os.ReadFile(abs)
+		if err != nil {
+			e.log.Warn("Failed to snapshot %s for undo: %v", path, err)
+			return
+		}
+		snapshot.existed = true
+		snapshot.content = content
+		snapshot.mode = info.Mode().Perm()
+	}
+
+	snapshotsMu.Lock()
+	defer snapshotsMu.Unlock()
+	snapshots = append(snapshots, snapshot)
+	if len(snapshots) > maxUndoSnapshots {
+		snapshots = snapshots[len(snapshots)-maxUndoSnapshots:]
+	}
+}
+
+// UndoLastChange restores the file touched by the most recent create, update,
+// delete, copy or generate tool call to its previous state.
+func UndoLastChange() (string, error) {
+	snapshotsMu.Lock()
+	defer snapshotsMu.Unlock()
+
+	if len(snapshots) == 0 {
+		return "", fmt.Errorf("there are no file changes to undo")
+	}
+	snapshot := snapshots[len(snapshots)-1]
+
+	if !snapshot.existed {
+		if err := os.Remove(snapshot.path); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to remove %s: %w", snapshot.path, err)
+		}
+		snapshots = snapshots[:len(snapshots)-1]
+		return fmt.Sprintf("Removed '%s', which did not exist before the last change.", snapshot.path), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(snapshot.path), 0755); err != nil {
+		return "", fmt.Errorf("failed to recreate directory for %s: %w", snapshot.path, err)
+	}
+	if err := os.WriteFile(snapshot.path, snapshot.content, snapshot.mode); err != nil {
+		return "", fmt.Errorf("failed to restore %s: %w", snapshot.path, err)
+	}
+	snapshots = snapshots[:len(snapshots)-1]
+	return fmt.Sprintf("Restored '%s' to its previous content.", snapshot.path), nil
+}
@@ -0,0 +1,89 @@
+package gemini
+
+import (
+	"os"
+	"testing"
+)
+
+// resetSnapshots starts the test with an empty undo stack
+func resetSnapshots(t *testing.T) {
+	t.Helper()
+	snapshotsMu.Lock()
+	snapshots = nil
+	snapshotsMu.Unlock()
+	t.Cleanup(func() {
+		snapshotsMu.Lock()
+		snapshots = nil
+		snapshotsMu.Unlock()
+	})
+}
+
+func TestUndoRestoresOverwrittenFile(t *testing.T) {
+	resetSnapshots(t)
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("config.yaml", []byte("port: 80\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "update_file", map[string]any{"path": "config.yaml", "content": "port: 8080\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(e, "undo_last_change", nil); err != nil {
+		t.Fatalf("undo_last_change: %v", err)
+	}
+
+	content, err := os.ReadFile("config.yaml")
+	if err != nil || string(content) != "port: 80\n" {
+		t.Errorf("config.yaml = %q, %v; want the original content", content, err)
+	}
+	if info, _ := os.Stat("config.yaml"); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestUndoRecreatesDeletedFile(t *testing.T) {
+	resetSnapshots(t)
+	e, _ := newTestExecutor(t)
+	if err := os.WriteFile("notes.txt", []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := call(e, "delete_file", map[string]any{"path": "notes.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UndoLastChange(); err != nil {
+		t.Fatalf("UndoLastChange: %v", err)
+	}
+	if content, err := os.ReadFile("notes.txt"); err != nil || string(content) != "keep" {
+		t.Errorf("notes.txt = %q, %v; want it recreated", content, err)
+	}
+}
+
+func TestUndoRemovesCreatedFileAndStops(t *testing.T) {
+	resetSnapshots(t)
+	e, _ := newTestExecutor(t)
+
+	if _, err := call(e, "create_file", map[string]any{"path": "new.txt", "content": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UndoLastChange(); err != nil {
+		t.Fatalf("UndoLastChange: %v", err)
+	}
+	if _, err := os.Stat("new.txt"); !os.IsNotExist(err) {
+		t.Error("created file still exists after undo")
+	}
+	if _, err := UndoLastChange(); err == nil {
+		t.Error("undo with an empty stack succeeded")
+	}
+}
+
+func TestUndoStackIsBounded(t *testing.T) {
+	resetSnapshots(t)
+	e, _ := newTestExecutor(t)
+	for i := 0; i < maxUndoSnapshots+5; i++ {
+		e.snapshotFile("file.txt")
+	}
+	if len(snapshots) != maxUndoSnapshots {
+		t.Errorf("got %d snapshots, want at most %d", len(snapshots), maxUndoSnapshots)
+	}
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxActionArgsChars bounds the argument summary stored for a tool action.
+	maxActionArgsChars = 200
+
+	// maxActionResultChars bounds the result stored for a tool action.
+	maxActionResultChars = 500
+)
+
+// ToolAction records a tool call made by the assistant during a turn.
+type ToolAction struct {
+	Name   string `json:"name"`
+	Args   string `json:"args"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// NewToolAction creates a ToolAction with the arguments and result truncated
+// so the audit trail stays small.
+func NewToolAction(name, args, result string, err error) ToolAction {
+	action := ToolAction{
+		Name:   name,
+		Args:   truncate(args, maxActionArgsChars),
+		Result: truncate(result, maxActionResultChars),
+	}
+	if err != nil {
+		action.Error = truncate(err.Error(), maxActionResultChars)
+	}
+	return action
+}
+
+// String renders the action on a single line.
+func (a ToolAction) String() string {
+	line := a.Name + " " + a.Args
+	if a.Error != "" {
+		return line + " -> error: " + a.Error
+	}
+	if result := strings.TrimSpace(a.Result); result != "" {
+		return line + " -> " + strings.ReplaceAll(truncate(result, 80), "\n", " ")
+	}
+	return line
+}
+
+// AlignToolActions returns actions with exactly one entry per user/model pair
+// in history. Entries are aligned to the most recent turns: missing entries
+// for older turns are padded with nil and surplus old entries are dropped.
+func AlignToolActions(actions [][]ToolAction, history []string) [][]ToolAction {
+	turns := (len(history) + 1) / 2
+	if len(actions) > turns {
+		return actions[len(actions)-turns:]
+	}
+	if len(actions) < turns {
+		padded := make([][]ToolAction, turns-len(actions), turns)
+		return append(padded, actions...)
+	}
+	return actions
+}
+
+// truncate shortens s to at most limit bytes without splitting a UTF-8 character.
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit] + "..."
+}
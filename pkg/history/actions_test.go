@@ -0,0 +1,64 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewToolActionTruncates(t *testing.T) {
+	action := NewToolAction("run_command", strings.Repeat("a", 300), strings.Repeat("é", 400), errors.New("exit status 1"))
+	if len(action.Args) > maxActionArgsChars+3 || !strings.HasSuffix(action.Args, "...") {
+		t.Errorf("args not truncated: %d bytes", len(action.Args))
+	}
+	if len(action.Result) > maxActionResultChars+3 || !strings.HasSuffix(action.Result, "...") {
+		t.Errorf("result not truncated: %d bytes", len(action.Result))
+	}
+	if !strings.HasPrefix(action.Result, "é") || strings.Contains(action.Result, "�") {
+		t.Error("truncation split a character")
+	}
+	if action.Error != "exit status 1" {
+		t.Errorf("Error = %q", action.Error)
+	}
+	if got := action.String(); !strings.Contains(got, "-> error: exit status 1") {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestToolActionsSurviveSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CB.hist")
+	conversation := []string{"make a file", "Created it.", "thanks", "You're welcome."}
+	actions := [][]ToolAction{
+		{
+			NewToolAction("create_file", `{"path":"a.txt"}`, "File created", nil),
+			NewToolAction("run_command", `{"command":"ls"}`, "", errors.New("not allowed")),
+		},
+		nil,
+	}
+	if err := SaveSession(path, conversation, actions, nil, 0, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := LoadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data.ToolActions, actions) {
+		t.Errorf("ToolActions = %+v, want %+v", data.ToolActions, actions)
+	}
+}
+
+func TestAlignToolActions(t *testing.T) {
+	a := []ToolAction{{Name: "a"}}
+	b := []ToolAction{{Name: "b"}}
+	history := []string{"q1", "r1", "q2", "r2"}
+
+	if got := AlignToolActions([][]ToolAction{b}, history); !reflect.DeepEqual(got, [][]ToolAction{nil, b}) {
+		t.Errorf("padding = %v", got)
+	}
+	if got := AlignToolActions([][]ToolAction{a, a, b}, history); !reflect.DeepEqual(got, [][]ToolAction{a, b}) {
+		t.Errorf("trimming = %v", got)
+	}
+}
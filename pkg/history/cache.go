@@ -0,0 +1,79 @@
+package history
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// cachedSession is the session data last written to a history file, with
+// the file's size and modification time right after the write.
+type cachedSession struct {
+	data    SessionData
+	size    int64
+	modTime time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+
+	// sessionCache lets the saves made after every turn update the session
+	// they wrote last instead of decrypting and decoding the whole file again
+	sessionCache = make(map[string]cachedSession)
+
+	// beganSessions holds the history files BeginSession was called for whose
+	// TotalSessions hasn't been incremented yet
+	beganSessions = make(map[string]bool)
+)
+
+// loadForUpdate returns the session stored at path for a save to modify. It
+// comes from the cache when the file hasn't changed since this process wrote
+// it, and from LoadSession otherwise. The result is a copy the caller may
+// change.
+func loadForUpdate(path string) (*SessionData, error) {
+	cacheMu.Lock()
+	cached, ok := sessionCache[path]
+	cacheMu.Unlock()
+
+	if ok {
+		if info, err := os.Stat(path); err == nil && info.Size() == cached.size && info.ModTime().Equal(cached.modTime) {
+			data := cached.data
+			return &data, nil
+		}
+	}
+	return LoadSession(path)
+}
+
+// rememberSession caches the data just written to path
+func rememberSession(path string, data *SessionData) {
+	info, err := os.Stat(path)
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if err != nil {
+		delete(sessionCache, path)
+		return
+	}
+	sessionCache[path] = cachedSession{data: *data, size: info.Size(), modTime: info.ModTime()}
+}
+
+// BeginSession records that a run of the program starts using the session
+// stored at path. The next save of path increments TotalSessions; saves
+// without a BeginSession, like the ones of each turn, leave it alone. A
+// session file created by that save starts at 1.
+func BeginSession(path string) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	beganSessions[resolvePath(path)] = true
+}
+
+// countSession reports whether a save of path should increment
+// TotalSessions, which is once after BeginSession
+func countSession(path string) bool {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if !beganSessions[path] {
+		return false
+	}
+	delete(beganSessions, path)
+	return true
+}
@@ -0,0 +1,80 @@
+package history
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// saveTurns saves n turns to the session at path as one run would
+func saveTurns(t *testing.T, path string, n int) {
+	t.Helper()
+	var conversation []string
+	for i := 0; i < n; i++ {
+		conversation = append(conversation, "User: question", "AI: answer")
+		if err := SaveSession(path, conversation, nil, nil, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTotalSessionsCountsRuns(t *testing.T) {
+	path := filepath.Join(useHistoryDir(t), DefaultFileName)
+
+	for run := 1; run <= 3; run++ {
+		BeginSession(path)
+		saveTurns(t, path, 4)
+
+		data, err := LoadSession(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.TotalSessions != run {
+			t.Errorf("after run %d with 4 saves, TotalSessions = %d, want %d", run, data.TotalSessions, run)
+		}
+	}
+}
+
+func TestSaveReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(useHistoryDir(t), DefaultFileName)
+	saveTurns(t, path, 1)
+
+	// Another process rewrites the session between two saves of this one
+	var buf bytes.Buffer
+	changed := SessionData{Conversations: []string{"User: elsewhere", "AI: ok"}, HumorLevel: 77, TotalSessions: 5}
+	if err := gob.NewEncoder(&buf).Encode(changed); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveSession(path, []string{"User: here", "AI: ok"}, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	data, err := LoadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.HumorLevel != 77 || data.TotalSessions != 5 {
+		t.Errorf("session = %+v, want the changed file's humor level and count kept", data)
+	}
+}
+
+func TestSaveUsesCachedSession(t *testing.T) {
+	path := filepath.Join(useHistoryDir(t), DefaultFileName)
+	saveTurns(t, path, 1)
+
+	cacheMu.Lock()
+	cached, ok := sessionCache[path]
+	cacheMu.Unlock()
+	if !ok {
+		t.Fatal("the saved session was not cached")
+	}
+	data, err := loadForUpdate(path)
+	if err != nil || data == nil || len(data.Conversations) != len(cached.data.Conversations) {
+		t.Fatalf("loadForUpdate = %+v, %v; want the cached session", data, err)
+	}
+}
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// KeyEnvVar is the environment variable holding the passphrase used to
+// encrypt CB.hist. When it is unset, history is stored as plain gob.
+const KeyEnvVar = "CONSOLE_AI_HISTORY_KEY"
+
+const (
+	saltSize         = 16
+	keySize          = 32
+	pbkdf2Iterations = 100000
+)
+
+// encryptedMagic prefixes encrypted history files so they can be told apart
+// from plain gob files written by older versions.
+var encryptedMagic = []byte("CBHIST-AESGCM1\n")
+
+// ErrWrongPassphrase is returned when an encrypted history file cannot be
+// decrypted with the configured passphrase.
+var ErrWrongPassphrase = errors.New("failed to decrypt history: wrong passphrase or corrupted file")
+
+// historyPassphrase returns the passphrase configured for history encryption
+func historyPassphrase() string {
+	return os.Getenv(KeyEnvVar)
+}
+
+// isEncrypted reports whether data was written by encrypt
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// encrypt seals plaintext with AES-GCM using a key derived from passphrase.
+// The output layout is magic | salt | nonce | ciphertext.
+func encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, encryptedMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, encryptedMagic), nil
+}
+
+// decrypt opens data produced by encrypt
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	data = data[len(encryptedMagic):]
+	if len(data) < saltSize {
+		return nil, ErrWrongPassphrase
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, encryptedMagic)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive history key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
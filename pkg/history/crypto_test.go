@@ -0,0 +1,83 @@
+package history
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	plaintext := []byte("func main() { secret := \"hunter2\" }")
+
+	sealed, err := encrypt(plaintext, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncrypted(sealed) {
+		t.Error("encrypted data has no header")
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Error("encrypted data contains the plaintext")
+	}
+
+	opened, err := decrypt(sealed, "correct horse")
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("decrypt = %q, want %q", opened, plaintext)
+	}
+}
+
+func TestDecryptWrongPassphrase(t *testing.T) {
+	sealed, err := encrypt([]byte("data"), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decrypt(sealed, "battery staple"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("decrypt with wrong passphrase = %v, want ErrWrongPassphrase", err)
+	}
+	if _, err := decrypt(encryptedMagic, "correct horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("decrypt of truncated data = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestEncryptedSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CB.hist")
+	t.Setenv(KeyEnvVar, "correct horse")
+	if err := SaveHistory(path, []string{"my password is hunter2", "noted"}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncrypted(content) || bytes.Contains(content, []byte("hunter2")) {
+		t.Error("history file is not encrypted")
+	}
+
+	data, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if want := []string{"my password is hunter2", "noted"}; !reflect.DeepEqual(data.Conversations, want) {
+		t.Errorf("Conversations = %q, want %q", data.Conversations, want)
+	}
+
+	t.Setenv(KeyEnvVar, "battery staple")
+	if _, err := LoadSession(path); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("LoadSession with wrong passphrase = %v, want ErrWrongPassphrase", err)
+	}
+	if _, err := os.Stat(path + ".corrupt"); !os.IsNotExist(err) {
+		t.Error("an undecryptable file was moved aside as corrupt")
+	}
+
+	t.Setenv(KeyEnvVar, "")
+	if _, err := LoadSession(path); err == nil {
+		t.Error("loading an encrypted file without a passphrase succeeded")
+	}
+}
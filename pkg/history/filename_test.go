@@ -0,0 +1,93 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useFileName sets the default session's history file name for the test
+func useFileName(t *testing.T, name string) {
+	t.Helper()
+	if err := SetFileName(name); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetFileName("") })
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestDefaultHistoryFile(t *testing.T) {
+	dir := useHistoryDir(t)
+
+	if err := SaveSession("", []string{"User: hi"}, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(filepath.Join(dir, DefaultFileName)) {
+		t.Errorf("the default session was not saved to %s", DefaultFileName)
+	}
+	if path, _ := SessionPath("feature"); path != filepath.Join(dir, "CB.feature.hist") {
+		t.Errorf("named session path = %q", path)
+	}
+}
+
+func TestCustomHistoryFileName(t *testing.T) {
+	dir := useHistoryDir(t)
+	useFileName(t, "team.history")
+
+	if err := SaveSession("", []string{"User: hi"}, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(filepath.Join(dir, "team.history")) || exists(filepath.Join(dir, DefaultFileName)) {
+		t.Error("the default session was not saved to the configured file name")
+	}
+
+	path, err := SessionPath("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "team.feature.history") {
+		t.Errorf("named session path = %q, want it based on the configured name", path)
+	}
+	if err := SaveSession(path, []string{"User: feature"}, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := ListSessions(); err != nil || len(names) != 2 {
+		t.Errorf("ListSessions = %q, %v; want the default and the named session", names, err)
+	}
+}
+
+func TestExplicitHistoryPathIsRespected(t *testing.T) {
+	dir := useHistoryDir(t)
+
+	// Names that used to be redirected to CB.hist are used as given
+	for _, name := range []string{"conversation_history.json", "CB.hist.bak"} {
+		path := filepath.Join(dir, name)
+		if err := SaveSession(path, []string{"User: " + name}, nil, nil, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+		data, err := LoadSession(path)
+		if err != nil || data == nil || data.Conversations[0] != "User: "+name {
+			t.Errorf("LoadSession(%s) = %+v, %v", name, data, err)
+		}
+	}
+	if exists(filepath.Join(dir, DefaultFileName)) {
+		t.Error("an explicit path was redirected to the default history file")
+	}
+}
+
+func TestInvalidHistoryFileName(t *testing.T) {
+	t.Cleanup(func() { SetFileName("") })
+	for _, name := range []string{"dir/CB.hist", `dir\CB.hist`, "..", "."} {
+		if err := SetFileName(name); err == nil {
+			t.Errorf("SetFileName(%q) was accepted", name)
+		}
+	}
+	if fileName != DefaultFileName {
+		t.Errorf("fileName = %q after invalid names, want %q", fileName, DefaultFileName)
+	}
+}
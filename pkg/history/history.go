@@ -1,14 +1,34 @@
 package history
 
 import (
+	"bytes"
 	"encoding/gob"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"console-ai/pkg/agent"
+	"console-ai/pkg/logger"
 )
 
+// DefaultMaxTurns is the default number of user/model pairs kept in CB.hist.
+const DefaultMaxTurns = 50
+
+// MaxInputHistory is the number of submitted prompts kept for recall.
+const MaxInputHistory = 100
+
+// DefaultSessionName is the name of the session stored in CB.hist.
+const DefaultSessionName = "default"
+
+// validSessionName restricts session names to characters safe in file names.
+var validSessionName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 // SessionData contains all data stored in CB.hist
 type SessionData struct {
 	ProjectInfo    *agent.ProjectInfo `json:"project_info"`
@@ -16,40 +36,43 @@ type SessionData struct {
 	LastUpdated    time.Time         `json:"last_updated"`
 	TotalSessions  int               `json:"total_sessions"`
 	HumorLevel     int               `json:"humor_level"`
+	HumorLevelSet  bool              `json:"humor_level_set,omitempty"` // HumorLevel was chosen with SaveHumorLevel, even when 0
+	Tone           string            `json:"tone,omitempty"`
+	InputHistory   []string          `json:"input_history,omitempty"`
+	ToolActions    [][]ToolAction    `json:"tool_actions,omitempty"` // per user/model pair in Conversations
 }
 
 // SaveHistory saves the conversation history and project context to CB.hist.
 // The file is saved as CB.hist in the current working directory.
 func SaveHistory(path string, history []string) error {
-	return SaveSession(path, history, nil, 0)
+	return SaveSession(path, history, nil, nil, 0, 0)
 }
 
 // SaveSession saves both conversation history and project context to CB.hist.
-func SaveSession(path string, history []string, projectInfo *agent.ProjectInfo, humorLevel int) error {
-	// Always use CB.hist in current working directory
-	if path == "" || path == "conversation_history.json" || path == "CB.hist" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			// Fallback to current directory if we can't get working directory
-			path = "CB.hist"
-		} else {
-			path = filepath.Join(cwd, "CB.hist")
-		}
-	}
+// toolActions holds the tool calls made in each user/model pair of history.
+// Only the most recent maxTurns user/model pairs are kept; 0 disables pruning.
+func SaveSession(path string, history []string, toolActions [][]ToolAction, projectInfo *agent.ProjectInfo, humorLevel int, maxTurns int) error {
+	path = resolvePath(path)
 
-	// Load existing session data if it exists
-	existingData, _ := LoadSession(path)
+	// Load existing session data if it exists. Refuse to overwrite a file
+	// we cannot read, such as one encrypted with a different passphrase.
+	existingData, err := loadForUpdate(path)
+	if err != nil {
+		return err
+	}
 	if existingData == nil {
-		existingData = &SessionData{
-			TotalSessions: 0,
-			HumorLevel:    humorLevel,
-		}
+		existingData = &SessionData{HumorLevel: humorLevel}
 	}
 
 	// Update session data
-	existingData.Conversations = history
+	existingData.Conversations = PruneHistory(history, maxTurns)
+	existingData.ToolActions = AlignToolActions(toolActions, existingData.Conversations)
 	existingData.LastUpdated = time.Now()
-	existingData.TotalSessions++
+	// A session is one run of the program that called BeginSession, however
+	// many turns it saves
+	if countSession(path) {
+		existingData.TotalSessions++
+	}
 	if projectInfo != nil {
 		existingData.ProjectInfo = projectInfo
 	}
@@ -57,14 +80,237 @@ func SaveSession(path string, history []string, projectInfo *agent.ProjectInfo,
 		existingData.HumorLevel = humorLevel
 	}
 
-	f, err := os.Create(path)
+	return writeSession(path, existingData)
+}
+
+// SaveInputHistory stores the prompts submitted by the user so they can be
+// recalled in later sessions. Only the last MaxInputHistory entries are kept.
+func SaveInputHistory(path string, inputs []string) error {
+	path = resolvePath(path)
+
+	existingData, err := loadForUpdate(path)
+	if err != nil {
+		return err
+	}
+	if existingData == nil {
+		existingData = &SessionData{}
+	}
+
+	if len(inputs) > MaxInputHistory {
+		inputs = inputs[len(inputs)-MaxInputHistory:]
+	}
+	existingData.InputHistory = inputs
+	existingData.LastUpdated = time.Now()
+
+	return writeSession(path, existingData)
+}
+
+// SaveHumorLevel stores the humor level of the session. Unlike SaveSession it
+// also stores 0, so humor can be turned off for a session.
+func SaveHumorLevel(path string, humorLevel int) error {
+	path = resolvePath(path)
+
+	existingData, err := loadForUpdate(path)
+	if err != nil {
+		return err
+	}
+	if existingData == nil {
+		existingData = &SessionData{}
+	}
+
+	existingData.HumorLevel = humorLevel
+	existingData.HumorLevelSet = true
+	existingData.LastUpdated = time.Now()
+
+	return writeSession(path, existingData)
+}
+
+// SavedHumorLevel returns the humor level stored with the session and
+// whether there is one: a level chosen with SaveHumorLevel, including 0, or
+// a non-zero level saved by SaveSession.
+func (s *SessionData) SavedHumorLevel() (int, bool) {
+	if s.HumorLevelSet || s.HumorLevel > 0 {
+		return s.HumorLevel, true
+	}
+	return 0, false
+}
+
+// SaveTone stores the tone profile of the session; an empty tone falls back
+// to the configured one on the next start.
+func SaveTone(path string, tone string) error {
+	path = resolvePath(path)
+
+	existingData, err := loadForUpdate(path)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	if existingData == nil {
+		existingData = &SessionData{}
+	}
+
+	existingData.Tone = tone
+	existingData.LastUpdated = time.Now()
+
+	return writeSession(path, existingData)
+}
+
+// PruneHistory returns the most recent maxTurns user/model pairs of history.
+// A maxTurns of 0 or less returns the history unchanged.
+func PruneHistory(history []string, maxTurns int) []string {
+	keep := maxTurns * 2
+	if maxTurns <= 0 || len(history) <= keep {
+		return history
+	}
+	return history[len(history)-keep:]
+}
+
+// historyDir is the directory holding the history files. Empty means the
+// current working directory.
+var historyDir string
+
+// DefaultFileName is the history file of the default session unless another
+// name is configured.
+const DefaultFileName = "CB.hist"
+
+// fileName is the history file of the default session. Named sessions use
+// it with the session name before the extension, e.g. CB.<name>.hist.
+var fileName = DefaultFileName
+
+// SetFileName changes the history file name of the default session. The
+// name must be a plain file name; the location is set by SetDirectory.
+func SetFileName(name string) error {
+	if name == "" {
+		name = DefaultFileName
+	}
+	if name != filepath.Base(name) || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid history file name %q: use a file name without a directory", name)
+	}
+	fileName = name
+	return nil
+}
+
+// sessionFileName returns the history file name of a named session
+func sessionFileName(name string) string {
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "." + name + ext
+}
+
+// SetDirectory stores the history files in dir, normally the project root,
+// instead of the current working directory.
+func SetDirectory(dir string) {
+	historyDir = dir
+}
+
+// projectMarkers are the files and directories that mark a project root
+// besides the history file itself
+var projectMarkers = []string{".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml"}
+
+// FindProjectRoot returns the closest directory at or above dir that holds
+// an existing history file or a project marker such as .git or go.mod, so runs from
+// any subdirectory share the project's history. dir itself is returned when
+// no marker is found.
+func FindProjectRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for current := dir; ; {
+		for _, marker := range append([]string{fileName}, projectMarkers...) {
+			if _, err := os.Stat(filepath.Join(current, marker)); err == nil {
+				return current
+			}
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// resolvePath maps an empty path to the default history file in the history
+// directory, which defaults to the current working directory. Any other
+// path is used as given.
+func resolvePath(path string) string {
+	if path != "" {
+		return path
+	}
+	if historyDir != "" {
+		return filepath.Join(historyDir, fileName)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		// Fallback to current directory if we can't get working directory
+		return fileName
+	}
+	return filepath.Join(cwd, fileName)
+}
+
+// writeSession encodes the session data to path in gob format, encrypting it
+// when a history passphrase is configured. The whole file is rewritten, since
+// encryption covers it as one block; the data is cached for the next save.
+func writeSession(path string, data *SessionData) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return err
+	}
+
+	content := buf.Bytes()
+	if passphrase := historyPassphrase(); passphrase != "" {
+		encrypted, err := encrypt(content, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt history: %w", err)
+		}
+		content = encrypted
+	}
+
+	if err := writeFileAtomic(path, content); err != nil {
+		return err
+	}
+	rememberSession(path, data)
+	return nil
+}
+
+// writeFileAtomic writes content to a temporary file next to path and renames it
+// into place, so an interrupted write never leaves a truncated history file.
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
 
-	enc := gob.NewEncoder(f)
-	return enc.Encode(existingData)
+// backupCorruptFile moves an unreadable history file aside so a fresh session
+// can start without destroying the original data.
+func backupCorruptFile(path string) {
+	backupPath := path + ".corrupt"
+	if err := os.Rename(path, backupPath); err != nil {
+		logger.Warn("History file %s is corrupt and could not be backed up: %v", path, err)
+		return
+	}
+	logger.Warn("History file %s is corrupt; moved it to %s and starting a fresh session", path, backupPath)
 }
 
 // LoadHistory loads just the conversation history from CB.hist for backward compatibility.
@@ -79,18 +325,9 @@ func LoadHistory(path string) ([]string, error) {
 // LoadSession loads the complete session data from CB.hist binary file.
 // Looks for CB.hist in the current working directory.
 func LoadSession(path string) (*SessionData, error) {
-	// Always use CB.hist in current working directory
-	if path == "" || path == "conversation_history.json" || path == "CB.hist" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			// Fallback to current directory if we can't get working directory
-			path = "CB.hist"
-		} else {
-			path = filepath.Join(cwd, "CB.hist")
-		}
-	}
+	path = resolvePath(path)
 
-	f, err := os.Open(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Return nil if file doesn't exist
@@ -98,29 +335,249 @@ func LoadSession(path string) (*SessionData, error) {
 		}
 		return nil, err
 	}
-	defer f.Close()
 
-	dec := gob.NewDecoder(f)
-	
-	// Try to decode as SessionData first
+	if isEncrypted(content) {
+		passphrase := historyPassphrase()
+		if passphrase == "" {
+			return nil, fmt.Errorf("history file %s is encrypted; set %s to read it", path, KeyEnvVar)
+		}
+		if content, err = decrypt(content, passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	sessionData, legacy, err := decodeSession(content)
+	if err != nil {
+		// Neither format matched, keep a backup and start fresh
+		backupCorruptFile(path)
+		return nil, nil
+	}
+	if legacy {
+		// Persist the conversion so it only happens once
+		if err := writeSession(path, sessionData); err != nil {
+			logger.Warn("Failed to migrate legacy history file %s: %v", path, err)
+		} else {
+			logger.Info("Migrated legacy history file %s to the current format", path)
+		}
+	}
+	return sessionData, nil
+}
+
+// decodeSession decodes gob session data. legacy reports that content held
+// the old format, a bare []string of conversations, which is converted.
+func decodeSession(content []byte) (data *SessionData, legacy bool, err error) {
 	var sessionData SessionData
-	if err := dec.Decode(&sessionData); err != nil {
-		// If that fails, try to decode as old format ([]string)
-		f.Seek(0, 0)
-		dec = gob.NewDecoder(f)
-		var oldHistory []string
-		if err2 := dec.Decode(&oldHistory); err2 != nil {
-			// Both failed, return empty
-			return nil, nil
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&sessionData); err == nil {
+		return &sessionData, false, nil
+	}
+
+	var oldHistory []string
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&oldHistory); err != nil {
+		return nil, false, fmt.Errorf("unknown history format: %w", err)
+	}
+	return &SessionData{
+		Conversations: oldHistory,
+		LastUpdated:   time.Now(),
+		TotalSessions: 1,
+		HumorLevel:    0,
+	}, true, nil
+}
+
+// MigrateSessions rewrites every session in the history directory in the
+// current format, converting legacy files, and returns the migrated names.
+func MigrateSessions() ([]string, error) {
+	names, err := ListSessions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var migrated []string
+	for _, name := range names {
+		path, err := SessionPath(name)
+		if err != nil {
+			return migrated, err
+		}
+		sessionData, err := LoadSession(path)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to load session %s: %w", name, err)
+		}
+		if sessionData == nil {
+			continue
 		}
-		// Convert old format to new format
-		return &SessionData{
-			Conversations: oldHistory,
-			LastUpdated:   time.Now(),
-			TotalSessions: 1,
-			HumorLevel:    0,
-		}, nil
+		if err := writeSession(path, sessionData); err != nil {
+			return migrated, fmt.Errorf("failed to rewrite session %s: %w", name, err)
+		}
+		migrated = append(migrated, name)
+	}
+	return migrated, nil
+}
+
+// ExportSessionJSON writes the session stored at path to outPath as indented JSON
+// so it can be inspected, edited, or moved to another machine.
+func ExportSessionJSON(path, outPath string) error {
+	sessionData, err := LoadSession(path)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	if sessionData == nil {
+		return fmt.Errorf("no session data found at %s", resolvePath(path))
+	}
+
+	data, err := json.MarshalIndent(sessionData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session as JSON: %w", err)
+	}
+
+	return os.WriteFile(outPath, data, 0644)
+}
+
+// ImportSessionJSON reads a JSON session from inPath and stores it at path,
+// replacing any existing session data.
+func ImportSessionJSON(inPath, path string) error {
+	data, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inPath, err)
+	}
+
+	var sessionData SessionData
+	if err := json.Unmarshal(data, &sessionData); err != nil {
+		return fmt.Errorf("failed to decode session JSON: %w", err)
 	}
-	
-	return &sessionData, nil
+
+	return writeSession(resolvePath(path), &sessionData)
+}
+
+// SessionPath returns the history file for a named session in the history
+// directory. The default session is stored in CB.hist, others in CB.<name>.hist,
+// or the same pattern based on the configured file name.
+func SessionPath(name string) (string, error) {
+	if name == "" || name == DefaultSessionName {
+		return resolvePath(""), nil
+	}
+	if !validSessionName.MatchString(name) {
+		return "", fmt.Errorf("invalid session name %q: use letters, digits, '-' or '_'", name)
+	}
+	return filepath.Join(filepath.Dir(resolvePath("")), sessionFileName(name)), nil
+}
+
+// LoadNamedSession loads the session data for a named session.
+func LoadNamedSession(name string) (*SessionData, error) {
+	path, err := SessionPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadSession(path)
+}
+
+// ListSessions returns the names of all sessions stored in the history directory.
+func ListSessions() ([]string, error) {
+	dir := filepath.Dir(resolvePath(""))
+	ext := filepath.Ext(fileName)
+	prefix := strings.TrimSuffix(fileName, ext) + "."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if name == fileName {
+			names = append(names, DefaultSessionName)
+			continue
+		}
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) && len(name) > len(prefix)+len(ext) {
+			sessionName := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+			if validSessionName.MatchString(sessionName) {
+				names = append(names, sessionName)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Match is a single conversation entry that matched a history search.
+type Match struct {
+	Index   int    `json:"index"`   // Position in SessionData.Conversations
+	Turn    int    `json:"turn"`    // 1-based user/model turn number
+	Role    string `json:"role"`    // "user" or "model"
+	Snippet string `json:"snippet"` // Text surrounding the match
+}
+
+// snippetRadius is the number of characters shown on each side of a match.
+const snippetRadius = 40
+
+// SearchHistory finds conversation entries containing query, ignoring case.
+// A query wrapped in slashes (e.g. /go (build|test)/) is treated as a regular expression.
+func SearchHistory(path, query string) ([]Match, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+
+	pattern := regexp.QuoteMeta(query)
+	if len(query) > 2 && strings.HasPrefix(query, "/") && strings.HasSuffix(query, "/") {
+		pattern = query[1 : len(query)-1]
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+
+	sessionData, err := LoadSession(path)
+	if err != nil {
+		return nil, err
+	}
+	if sessionData == nil {
+		return nil, nil
+	}
+
+	var matches []Match
+	for i, entry := range sessionData.Conversations {
+		loc := re.FindStringIndex(entry)
+		if loc == nil {
+			continue
+		}
+		role := "user"
+		if i%2 == 1 {
+			role = "model"
+		}
+		matches = append(matches, Match{
+			Index:   i,
+			Turn:    i/2 + 1,
+			Role:    role,
+			Snippet: snippet(entry, loc[0], loc[1]),
+		})
+	}
+	return matches, nil
+}
+
+// snippet returns the text around [start, end) on a single line
+func snippet(text string, start, end int) string {
+	from := start - snippetRadius
+	prefix := "..."
+	if from <= 0 {
+		from = 0
+		prefix = ""
+	}
+	to := end + snippetRadius
+	suffix := "..."
+	if to >= len(text) {
+		to = len(text)
+		suffix = ""
+	}
+
+	// Avoid cutting multi-byte characters in half
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	return prefix + strings.Join(strings.Fields(text[from:to]), " ") + suffix
 }
@@ -0,0 +1,299 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"console-ai/pkg/agent"
+)
+
+// sameSession compares two sessions, with LastUpdated compared as an instant
+// since encoding may change its location
+func sameSession(t *testing.T, got, want *SessionData) {
+	t.Helper()
+	if !got.LastUpdated.Equal(want.LastUpdated) {
+		t.Errorf("LastUpdated = %v, want %v", got.LastUpdated, want.LastUpdated)
+	}
+	gotCopy, wantCopy := *got, *want
+	gotCopy.LastUpdated, wantCopy.LastUpdated = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(gotCopy, wantCopy) {
+		t.Errorf("session = %+v, want %+v", gotCopy, wantCopy)
+	}
+}
+
+func TestExportImportSessionJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CB.hist")
+	original := &SessionData{
+		ProjectInfo: &agent.ProjectInfo{
+			RootPath:     dir,
+			Language:     "Go",
+			Dependencies: []string{"github.com/charmbracelet/bubbletea"},
+			Scripts:      map[string]string{"test": "go test ./..."},
+		},
+		Conversations: []string{"hello", "hi there"},
+		LastUpdated:   time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		TotalSessions: 3,
+		HumorLevel:    40,
+		InputHistory:  []string{"hello"},
+		ToolActions:   [][]ToolAction{{{Name: "list_files", Args: `{"path":"."}`, Result: "main.go"}}},
+	}
+	if err := writeSession(path, original); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonPath := filepath.Join(dir, "session.json")
+	if err := ExportSessionJSON(path, jsonPath); err != nil {
+		t.Fatalf("ExportSessionJSON: %v", err)
+	}
+	importedPath := filepath.Join(dir, "imported.hist")
+	if err := ImportSessionJSON(jsonPath, importedPath); err != nil {
+		t.Fatalf("ImportSessionJSON: %v", err)
+	}
+
+	imported, err := LoadSession(importedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported == nil {
+		t.Fatal("no session imported")
+	}
+	sameSession(t, imported, original)
+}
+
+func TestExportSessionJSONWithoutSession(t *testing.T) {
+	dir := t.TempDir()
+	if err := ExportSessionJSON(filepath.Join(dir, "CB.hist"), filepath.Join(dir, "out.json")); err == nil {
+		t.Error("exporting a missing session succeeded")
+	}
+}
+
+func TestSaveSessionPrunesOldestTurns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CB.hist")
+	info := &agent.ProjectInfo{Language: "Go"}
+	BeginSession(path)
+	if err := SaveSession(path, []string{"q1", "a1"}, nil, info, 10, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	conversation := []string{"q1", "a1", "q2", "a2", "q3", "a3"}
+	if err := SaveSession(path, conversation, nil, nil, 10, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := LoadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"q2", "a2", "q3", "a3"}; !reflect.DeepEqual(data.Conversations, want) {
+		t.Errorf("Conversations = %q, want %q", data.Conversations, want)
+	}
+	if data.ProjectInfo == nil || data.ProjectInfo.Language != "Go" {
+		t.Errorf("ProjectInfo = %+v, want it kept", data.ProjectInfo)
+	}
+	if data.TotalSessions != 1 {
+		t.Errorf("TotalSessions = %d, want 1", data.TotalSessions)
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	conversation := []string{"q1", "a1", "q2", "a2"}
+	tests := []struct {
+		maxTurns int
+		want     []string
+	}{
+		{0, conversation},
+		{-1, conversation},
+		{2, conversation},
+		{5, conversation},
+		{1, []string{"q2", "a2"}},
+	}
+	for _, tt := range tests {
+		if got := PruneHistory(conversation, tt.maxTurns); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PruneHistory(%d) = %q, want %q", tt.maxTurns, got, tt.want)
+		}
+	}
+}
+
+// useHistoryDir stores the history files of the test in a temporary directory
+func useHistoryDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	SetDirectory(dir)
+	t.Cleanup(func() { SetDirectory("") })
+	return dir
+}
+
+func TestNamedSessionsAreSeparate(t *testing.T) {
+	dir := useHistoryDir(t)
+
+	workPath, err := SessionPath("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hobbyPath, err := SessionPath("hobby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workPath != filepath.Join(dir, "CB.work.hist") {
+		t.Errorf("SessionPath(work) = %s", workPath)
+	}
+
+	if err := SaveSession(workPath, []string{"deploy the api", "done"}, nil, &agent.ProjectInfo{Language: "Go"}, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSession(hobbyPath, []string{"write a game", "sure"}, nil, &agent.ProjectInfo{Language: "Rust"}, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	work, err := LoadNamedSession("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hobby, err := LoadNamedSession("hobby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(work.Conversations, []string{"deploy the api", "done"}) || work.ProjectInfo.Language != "Go" {
+		t.Errorf("work session = %q (%s)", work.Conversations, work.ProjectInfo.Language)
+	}
+	if !reflect.DeepEqual(hobby.Conversations, []string{"write a game", "sure"}) || hobby.ProjectInfo.Language != "Rust" {
+		t.Errorf("hobby session = %q (%s)", hobby.Conversations, hobby.ProjectInfo.Language)
+	}
+
+	if defaultSession, err := LoadNamedSession(DefaultSessionName); err != nil || defaultSession != nil {
+		t.Errorf("default session = %+v, %v; want none", defaultSession, err)
+	}
+
+	names, err := ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hobby", "work"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListSessions() = %q, want %q", names, want)
+	}
+}
+
+func TestSessionPathRejectsInvalidNames(t *testing.T) {
+	useHistoryDir(t)
+	for _, name := range []string{"../escape", "a/b", "with space", "dots.too"} {
+		if _, err := SessionPath(name); err == nil {
+			t.Errorf("SessionPath(%q) succeeded", name)
+		}
+	}
+}
+
+func TestLoadSessionRecoversFromTruncatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CB.hist")
+	if err := SaveHistory(path, []string{"a long question about the project", "a long answer"}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content[:len(content)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if data != nil {
+		t.Errorf("LoadSession = %+v, want a fresh session", data)
+	}
+	backup, err := os.ReadFile(path + ".corrupt")
+	if err != nil {
+		t.Fatalf("no backup of the corrupt file: %v", err)
+	}
+	if !bytes.Equal(backup, content[:len(content)/2]) {
+		t.Error("backup differs from the corrupt file")
+	}
+
+	// The next save starts over instead of failing on the corrupt file
+	if err := SaveHistory(path, []string{"new", "start"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err = LoadSession(path)
+	if err != nil || data == nil || len(data.Conversations) != 2 {
+		t.Errorf("LoadSession after a fresh save = %+v, %v", data, err)
+	}
+}
+
+func TestWriteFileAtomicLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CB.hist")
+	if err := writeFileAtomic(path, []byte("content")); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "CB.hist" {
+		t.Errorf("directory holds %v, want only CB.hist", entries)
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CB.hist")
+	conversation := []string{
+		"How do I run the tests?",
+		"Run `go test ./...` from the project root.",
+		"And how do I build it?",
+		"Use go build -o app . to build the binary.",
+		"Thanks!",
+		"You're welcome.",
+	}
+	if err := SaveHistory(path, conversation); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := SearchHistory(path, "GO TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("found %d matches, want 1: %+v", len(matches), matches)
+	}
+	if m := matches[0]; m.Index != 1 || m.Turn != 1 || m.Role != "model" || !strings.Contains(m.Snippet, "go test ./...") {
+		t.Errorf("match = %+v", m)
+	}
+
+	matches, err = SearchHistory(path, "/how do i (run|build)/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Index != 0 || matches[1].Index != 2 || matches[1].Turn != 2 || matches[1].Role != "user" {
+		t.Errorf("regex matches = %+v", matches)
+	}
+
+	if matches, err := SearchHistory(path, "deploy"); err != nil || len(matches) != 0 {
+		t.Errorf("search without hits = %+v, %v", matches, err)
+	}
+	if _, err := SearchHistory(path, "  "); err == nil {
+		t.Error("empty query succeeded")
+	}
+	if _, err := SearchHistory(path, "/(/"); err == nil {
+		t.Error("invalid regex succeeded")
+	}
+}
+
+func TestSnippetKeepsRunesWhole(t *testing.T) {
+	text := strings.Repeat("é", 60) + "needle" + strings.Repeat("ü", 60)
+	start := strings.Index(text, "needle")
+	got := snippet(text, start, start+len("needle"))
+	if !utf8.ValidString(got) {
+		t.Errorf("snippet %q is not valid UTF-8", got)
+	}
+	if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "...") || !strings.Contains(got, "needle") {
+		t.Errorf("snippet = %q", got)
+	}
+}
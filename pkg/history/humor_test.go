@@ -0,0 +1,43 @@
+package history
+
+import "testing"
+
+func TestHumorLevelZeroRoundTrip(t *testing.T) {
+	useHistoryDir(t)
+	if err := SaveSession("", []string{"User: hi", "AI: hello"}, nil, nil, 60, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveHumorLevel("", 0); err != nil {
+		t.Fatal(err)
+	}
+	// Later turns save the current level, which is now 0
+	if err := SaveSession("", []string{"User: hi", "AI: hello"}, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := LoadSession("")
+	if err != nil || data == nil {
+		t.Fatalf("LoadSession = %+v, %v", data, err)
+	}
+	if level, ok := data.SavedHumorLevel(); !ok || level != 0 {
+		t.Errorf("SavedHumorLevel = %d, %v; want 0 restored", level, ok)
+	}
+}
+
+func TestSavedHumorLevel(t *testing.T) {
+	tests := []struct {
+		data      SessionData
+		wantLevel int
+		wantOK    bool
+	}{
+		{SessionData{}, 0, false},
+		{SessionData{HumorLevel: 40}, 40, true},
+		{SessionData{HumorLevel: 0, HumorLevelSet: true}, 0, true},
+		{SessionData{HumorLevel: 80, HumorLevelSet: true}, 80, true},
+	}
+	for _, tt := range tests {
+		if level, ok := tt.data.SavedHumorLevel(); level != tt.wantLevel || ok != tt.wantOK {
+			t.Errorf("SavedHumorLevel(%+v) = %d, %v; want %d, %v", tt.data, level, ok, tt.wantLevel, tt.wantOK)
+		}
+	}
+}
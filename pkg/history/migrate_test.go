@@ -0,0 +1,86 @@
+package history
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeLegacy stores conversations at path in the old format, a bare gob
+// encoded []string
+func writeLegacy(t *testing.T, path string, conversations []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(conversations); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertCurrentFormat fails unless the file at path decodes as SessionData
+func assertCurrentFormat(t *testing.T, path string) *SessionData {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, legacy, err := decodeSession(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy {
+		t.Fatalf("%s is still in the legacy format", path)
+	}
+	return data
+}
+
+func TestLegacyHistoryIsMigratedOnLoad(t *testing.T) {
+	dir := useHistoryDir(t)
+	path := filepath.Join(dir, fileName)
+	old := []string{"User: hello", "AI: hi"}
+	writeLegacy(t, path, old)
+
+	data, err := LoadSession("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data == nil || !reflect.DeepEqual(data.Conversations, old) {
+		t.Fatalf("LoadSession = %+v, want conversations %q", data, old)
+	}
+	if got := assertCurrentFormat(t, path); !reflect.DeepEqual(got.Conversations, old) {
+		t.Errorf("migrated file holds %q, want %q", got.Conversations, old)
+	}
+
+	updated := append(old, "User: again", "AI: sure")
+	if err := SaveSession("", updated, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := assertCurrentFormat(t, path); !reflect.DeepEqual(got.Conversations, updated) {
+		t.Errorf("saved file holds %q, want %q", got.Conversations, updated)
+	}
+}
+
+func TestMigrateSessions(t *testing.T) {
+	dir := useHistoryDir(t)
+	writeLegacy(t, filepath.Join(dir, fileName), []string{"User: default"})
+	namedPath, err := SessionPath("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLegacy(t, namedPath, []string{"User: feature"})
+
+	migrated, err := MigrateSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrated) != 2 {
+		t.Errorf("MigrateSessions migrated %q, want both sessions", migrated)
+	}
+	assertCurrentFormat(t, filepath.Join(dir, fileName))
+	assertCurrentFormat(t, namedPath)
+}
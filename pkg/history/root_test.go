@@ -0,0 +1,92 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useProjectDir points the history directory at the project root found from
+// dir, as main does at startup
+func useProjectDir(t *testing.T, dir string) {
+	t.Helper()
+	SetDirectory(FindProjectRoot(dir))
+	t.Cleanup(func() { SetDirectory("") })
+}
+
+// newProject creates a project root marked by go.mod with the given
+// subdirectories and returns its path
+func newProject(t *testing.T, subdirs ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range subdirs {
+		if err := os.MkdirAll(filepath.Join(root, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestSubdirectoriesShareHistory(t *testing.T) {
+	root := newProject(t, "cmd/app", "pkg/lib")
+	history := []string{"User: hello", "AI: hi"}
+
+	useProjectDir(t, filepath.Join(root, "cmd", "app"))
+	if err := SaveSession("", history, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	useProjectDir(t, filepath.Join(root, "pkg", "lib"))
+	data, err := LoadSession("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data == nil || !reflect.DeepEqual(data.Conversations, history) {
+		t.Fatalf("history from another subdirectory = %+v, want %q", data, history)
+	}
+	if _, err := os.Stat(filepath.Join(root, fileName)); err != nil {
+		t.Errorf("history should be stored at the project root: %v", err)
+	}
+}
+
+func TestDifferentRootsKeepSeparateHistory(t *testing.T) {
+	first := newProject(t, "src")
+	second := newProject(t, "src")
+
+	useProjectDir(t, filepath.Join(first, "src"))
+	if err := SaveSession("", []string{"User: first project"}, nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	useProjectDir(t, filepath.Join(second, "src"))
+	data, err := LoadSession("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != nil {
+		t.Errorf("another project should have no history, got %q", data.Conversations)
+	}
+}
+
+func TestFindProjectRootPrefersHistoryFile(t *testing.T) {
+	root := newProject(t, "nested/deeper")
+	nested := filepath.Join(root, "nested")
+	if err := os.WriteFile(filepath.Join(nested, fileName), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FindProjectRoot(filepath.Join(nested, "deeper")); got != nested {
+		t.Errorf("FindProjectRoot = %q, want the directory holding %s (%q)", got, fileName, nested)
+	}
+}
+
+func TestFindProjectRootWithoutMarkers(t *testing.T) {
+	dir := t.TempDir()
+	if got := FindProjectRoot(dir); got != dir {
+		t.Errorf("FindProjectRoot = %q, want %q when no marker exists", got, dir)
+	}
+}
@@ -0,0 +1,56 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestManySavesInOneSessionCountOnce(t *testing.T) {
+	useHistoryDir(t)
+
+	BeginSession("")
+	saveTurns(t, "", 10)
+
+	data, err := LoadSession("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalSessions != 1 {
+		t.Errorf("TotalSessions = %d after 10 saves in the first session, want 1", data.TotalSessions)
+	}
+}
+
+func TestSavesWithoutBeginSessionDontCount(t *testing.T) {
+	path := filepath.Join(useHistoryDir(t), DefaultFileName)
+	BeginSession(path)
+	saveTurns(t, path, 1)
+
+	// Saves by other code paths, such as the TUI after each turn
+	saveTurns(t, path, 3)
+
+	data, err := LoadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalSessions != 1 {
+		t.Errorf("TotalSessions = %d, want 1", data.TotalSessions)
+	}
+}
+
+func TestBeginSessionOnlyCountsItsFile(t *testing.T) {
+	dir := useHistoryDir(t)
+	feature, err := SessionPath("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	BeginSession(feature)
+	saveTurns(t, "", 1)
+	saveTurns(t, feature, 1)
+
+	defaultSession, _ := LoadSession(filepath.Join(dir, DefaultFileName))
+	named, _ := LoadSession(feature)
+	if defaultSession.TotalSessions != 0 || named.TotalSessions != 1 {
+		t.Errorf("TotalSessions = %d for the default and %d for the named session, want 0 and 1", defaultSession.TotalSessions, named.TotalSessions)
+	}
+}
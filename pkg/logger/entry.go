@@ -0,0 +1,78 @@
+package logger
+
+import "fmt"
+
+// Entry is a set of structured fields attached to every message it logs.
+// Fields are appended as key=value pairs in text format and as the "fields"
+// object in JSON format.
+type Entry struct {
+	logger *Logger
+	fields map[string]interface{}
+}
+
+// WithFields returns an entry that logs through l with the given fields
+func (l *Logger) WithFields(fields map[string]interface{}) *Entry {
+	return &Entry{logger: l, fields: copyFields(nil, fields)}
+}
+
+// WithFields returns an entry that logs through the default logger with the given fields.
+// The default logger is resolved when a message is logged, so entries may be
+// created before Initialize is called.
+func WithFields(fields map[string]interface{}) *Entry {
+	return &Entry{fields: copyFields(nil, fields)}
+}
+
+// WithFields returns a new entry with fields added to the entry's existing fields
+func (e *Entry) WithFields(fields map[string]interface{}) *Entry {
+	if e == nil {
+		return WithFields(fields)
+	}
+	return &Entry{logger: e.logger, fields: copyFields(e.fields, fields)}
+}
+
+// Debug logs a debug message with the entry's fields
+func (e *Entry) Debug(format string, args ...interface{}) {
+	e.logf(1, DEBUG, format, args...)
+}
+
+// Info logs an info message with the entry's fields
+func (e *Entry) Info(format string, args ...interface{}) {
+	e.logf(1, INFO, format, args...)
+}
+
+// Warn logs a warning message with the entry's fields
+func (e *Entry) Warn(format string, args ...interface{}) {
+	e.logf(1, WARN, format, args...)
+}
+
+// Error logs an error message with the entry's fields
+func (e *Entry) Error(format string, args ...interface{}) {
+	e.logf(1, ERROR, format, args...)
+}
+
+func (e *Entry) logf(skip int, level LogLevel, format string, args ...interface{}) {
+	var fields map[string]interface{}
+	l := defaultLogger
+	if e != nil {
+		fields = e.fields
+		if e.logger != nil {
+			l = e.logger
+		}
+	}
+	if l == nil || !l.shouldLog(level) {
+		return
+	}
+	l.output(skip+1, level, fmt.Sprintf(format, args...), fields)
+}
+
+// copyFields merges extra into a copy of base
+func copyFields(base, extra map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(extra))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEntryFieldsInText(t *testing.T) {
+	l, buf := newTestLogger(t, FormatText)
+	entry := l.WithFields(map[string]interface{}{"tool": "read_file", "session": "work"})
+	entry.Info("reading %s", "main.go")
+
+	if out := buf.String(); !strings.Contains(out, "reading main.go session=work tool=read_file") {
+		t.Errorf("output = %q, want sorted fields after the message", out)
+	}
+}
+
+func TestEntryFieldsInJSON(t *testing.T) {
+	l, buf := newTestLogger(t, FormatJSON)
+	l.WithFields(map[string]interface{}{"tool": "read_file"}).
+		WithFields(map[string]interface{}{"request": 7}).
+		Warn("slow")
+
+	entries := jsonLines(t, buf.String())
+	fields, ok := entries[0]["fields"].(map[string]interface{})
+	if !ok || fields["tool"] != "read_file" || fields["request"] != float64(7) {
+		t.Errorf("fields = %v, want tool and request", entries[0]["fields"])
+	}
+}
+
+func TestWithFieldsDoesNotChangeParent(t *testing.T) {
+	l, buf := newTestLogger(t, FormatText)
+	parent := l.WithFields(map[string]interface{}{"tool": "a"})
+	parent.WithFields(map[string]interface{}{"tool": "b"})
+	parent.Info("parent")
+
+	if out := buf.String(); !strings.Contains(out, "tool=a") || strings.Contains(out, "tool=b") {
+		t.Errorf("output = %q, want the parent's own fields", out)
+	}
+}
+
+func TestPackageEntryUsesDefaultLoggerAtLogTime(t *testing.T) {
+	entry := WithFields(map[string]interface{}{"tool": "list_files"})
+	entry.Info("dropped") // no default logger yet
+
+	l, buf := newTestLogger(t, FormatText)
+	useDefaultLogger(t, l)
+	entry.Info("listed")
+
+	out := buf.String()
+	if strings.Contains(out, "dropped") || !strings.Contains(out, "listed tool=list_files") {
+		t.Errorf("output = %q", out)
+	}
+}
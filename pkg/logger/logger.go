@@ -1,12 +1,16 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -21,6 +25,20 @@ const (
 	FATAL
 )
 
+// levelColors maps log levels to ANSI color codes
+var levelColors = map[LogLevel]string{
+	DEBUG: "\033[90m",
+	INFO:  "\033[34m",
+	WARN:  "\033[33m",
+	ERROR: "\033[31m",
+	FATAL: "\033[1;91m",
+}
+
+const colorReset = "\033[0m"
+
+// ansiPattern matches ANSI escape sequences
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
 // String returns the string representation of the log level
 func (l LogLevel) String() string {
 	switch l {
@@ -39,9 +57,17 @@ func (l LogLevel) String() string {
 	}
 }
 
+// Output formats supported by the logger
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 // Logger provides structured logging with different levels
 type Logger struct {
 	level      LogLevel
+	format     string
+	color      bool
 	logger     *log.Logger
 	logFile    *os.File
 	enableFile bool
@@ -49,17 +75,29 @@ type Logger struct {
 
 // Config holds logger configuration
 type Config struct {
-	Level      LogLevel
-	Output     io.Writer
-	LogFile    string
-	EnableFile bool
-	Prefix     string
+	Level       LogLevel
+	Format      string // FormatText (default) or FormatJSON
+	EnableColor bool   // Colorize levels when Output is a terminal and NO_COLOR is unset
+	Output      io.Writer
+	LogFile     string
+	EnableFile  bool
+	Prefix      string
+}
+
+// jsonEntry is a single log line in JSON format
+type jsonEntry struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Caller    string                 `json:"caller"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
 }
 
 // DefaultConfig returns a default logger configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Level:      INFO,
+		Format:     FormatText,
 		Output:     os.Stdout,
 		EnableFile: false,
 		Prefix:     "[Console-AI] ",
@@ -74,8 +112,13 @@ func NewLogger(config *Config) (*Logger, error) {
 
 	logger := &Logger{
 		level:      config.Level,
+		format:     strings.ToLower(config.Format),
 		enableFile: config.EnableFile,
 	}
+	if logger.format != FormatJSON {
+		logger.format = FormatText
+	}
+	logger.color = config.EnableColor && logger.format == FormatText && colorSupported(config.Output)
 
 	var writers []io.Writer
 	if config.Output != nil {
@@ -96,7 +139,8 @@ func NewLogger(config *Config) (*Logger, error) {
 		}
 
 		logger.logFile = file
-		writers = append(writers, file)
+		// Keep file output free of color codes
+		writers = append(writers, stripANSIWriter{file})
 	}
 
 	// Create multi-writer if we have multiple outputs
@@ -109,7 +153,12 @@ func NewLogger(config *Config) (*Logger, error) {
 		}
 	}
 
-	logger.logger = log.New(output, config.Prefix, 0)
+	// A prefix would break one-object-per-line JSON output
+	prefix := config.Prefix
+	if logger.format == FormatJSON {
+		prefix = ""
+	}
+	logger.logger = log.New(output, prefix, 0)
 
 	return logger, nil
 }
@@ -127,17 +176,37 @@ func (l *Logger) SetLevel(level LogLevel) {
 	l.level = level
 }
 
+// IsEnabled reports whether messages at the given level are logged
+func (l *Logger) IsEnabled(level LogLevel) bool {
+	return l.shouldLog(level)
+}
+
 // shouldLog determines if a message should be logged based on the current level
 func (l *Logger) shouldLog(level LogLevel) bool {
 	return level >= l.level
 }
 
-// formatMessage formats a log message with timestamp, level, and caller information
-func (l *Logger) formatMessage(level LogLevel, message string) string {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+// output writes a formatted entry. skip is the number of stack frames between
+// the caller being reported and the function calling output.
+func (l *Logger) output(skip int, level LogLevel, message string, fields map[string]interface{}) {
+	l.logger.Println(l.formatMessage(skip+1, level, message, fields))
+}
+
+// logf formats and writes a message if level is enabled. skip has the same
+// meaning as for output.
+func (l *Logger) logf(skip int, level LogLevel, format string, args ...interface{}) {
+	if l.shouldLog(level) {
+		l.output(skip+1, level, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// formatMessage formats a log message with timestamp, level, caller information,
+// and optional structured fields in the configured output format
+func (l *Logger) formatMessage(skip int, level LogLevel, message string, fields map[string]interface{}) string {
+	now := time.Now()
 
-	// Get caller information
-	_, file, line, ok := runtime.Caller(3) // Skip formatMessage, log method, and public method
+	// Get caller information, skipping formatMessage and the frames reported by skip
+	_, file, line, ok := runtime.Caller(skip + 1)
 	var caller string
 	if ok {
 		caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
@@ -145,46 +214,107 @@ func (l *Logger) formatMessage(level LogLevel, message string) string {
 		caller = "unknown"
 	}
 
-	return fmt.Sprintf("%s [%s] %s - %s", timestamp, level.String(), caller, message)
+	if l.format == FormatJSON {
+		entry := jsonEntry{
+			Timestamp: now.Format(time.RFC3339),
+			Level:     level.String(),
+			Caller:    caller,
+			Message:   strings.TrimSpace(message),
+			Fields:    fields,
+		}
+		data, err := json.Marshal(entry)
+		if err == nil {
+			return string(data)
+		}
+		// Fall back to stringified fields if they can't be marshalled
+		entry.Fields = map[string]interface{}{"fields": fmt.Sprintf("%+v", fields)}
+		data, _ = json.Marshal(entry)
+		return string(data)
+	}
+
+	levelToken := level.String()
+	if l.color {
+		levelToken = levelColors[level] + levelToken + colorReset
+	}
+
+	return fmt.Sprintf("%s [%s] %s - %s%s", now.Format("2006-01-02 15:04:05"), levelToken, caller, message, formatFields(fields))
+}
+
+// colorSupported reports whether w is a terminal and color output is not disabled via NO_COLOR
+func colorSupported(w io.Writer) bool {
+	if _, noColor := os.LookupEnv("NO_COLOR"); noColor {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stripANSIWriter removes ANSI escape sequences before writing
+type stripANSIWriter struct {
+	w io.Writer
+}
+
+func (s stripANSIWriter) Write(p []byte) (int, error) {
+	if _, err := s.w.Write(ansiPattern.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// formatFields renders structured fields as sorted key=value pairs for text output
+func formatFields(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf(" %s=%v", key, fields[key]))
+	}
+	return builder.String()
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if l.shouldLog(DEBUG) {
-		message := fmt.Sprintf(format, args...)
-		l.logger.Println(l.formatMessage(DEBUG, message))
-	}
+	l.logf(1, DEBUG, format, args...)
 }
 
 // Info logs an info message
 func (l *Logger) Info(format string, args ...interface{}) {
-	if l.shouldLog(INFO) {
-		message := fmt.Sprintf(format, args...)
-		l.logger.Println(l.formatMessage(INFO, message))
-	}
+	l.logf(1, INFO, format, args...)
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(format string, args ...interface{}) {
-	if l.shouldLog(WARN) {
-		message := fmt.Sprintf(format, args...)
-		l.logger.Println(l.formatMessage(WARN, message))
-	}
+	l.logf(1, WARN, format, args...)
 }
 
 // Error logs an error message
 func (l *Logger) Error(format string, args ...interface{}) {
-	if l.shouldLog(ERROR) {
-		message := fmt.Sprintf(format, args...)
-		l.logger.Println(l.formatMessage(ERROR, message))
-	}
+	l.logf(1, ERROR, format, args...)
 }
 
 // Fatal logs a fatal message and exits the program
 func (l *Logger) Fatal(format string, args ...interface{}) {
+	l.fatal(1, format, args...)
+}
+
+func (l *Logger) fatal(skip int, format string, args ...interface{}) {
 	if l.shouldLog(FATAL) {
-		message := fmt.Sprintf(format, args...)
-		l.logger.Println(l.formatMessage(FATAL, message))
+		l.output(skip+1, FATAL, fmt.Sprintf(format, args...), nil)
 		l.Close()
 		os.Exit(1)
 	}
@@ -192,6 +322,10 @@ func (l *Logger) Fatal(format string, args ...interface{}) {
 
 // ErrorWithStack logs an error message with stack trace
 func (l *Logger) ErrorWithStack(err error, format string, args ...interface{}) {
+	l.errorWithStack(1, err, format, args...)
+}
+
+func (l *Logger) errorWithStack(skip int, err error, format string, args ...interface{}) {
 	if l.shouldLog(ERROR) {
 		message := fmt.Sprintf(format, args...)
 		if err != nil {
@@ -209,39 +343,69 @@ func (l *Logger) ErrorWithStack(err error, format string, args ...interface{}) {
 			buf = make([]byte, 2*len(buf))
 		}
 
+		if l.format == FormatJSON {
+			l.output(skip+1, ERROR, message, map[string]interface{}{"stack": string(buf)})
+			return
+		}
 		fullMessage := fmt.Sprintf("%s\nStack trace:\n%s", message, string(buf))
-		l.logger.Println(l.formatMessage(ERROR, fullMessage))
+		l.output(skip+1, ERROR, fullMessage, nil)
 	}
 }
 
 // LogToolCall logs a tool call with its parameters
 func (l *Logger) LogToolCall(toolName string, params map[string]interface{}) {
+	l.logToolCall(1, toolName, params)
+}
+
+func (l *Logger) logToolCall(skip int, toolName string, params map[string]interface{}) {
 	if l.shouldLog(DEBUG) {
+		if l.format == FormatJSON {
+			l.output(skip+1, DEBUG, "Tool call: "+toolName, map[string]interface{}{"tool": toolName, "params": params})
+			return
+		}
 		message := fmt.Sprintf("\nTool call: %s with params: %+v", toolName, params)
-		l.logger.Println(l.formatMessage(DEBUG, message))
+		l.output(skip+1, DEBUG, message, nil)
 	}
 }
 
 // LogToolResult logs a tool call result
 func (l *Logger) LogToolResult(toolName string, success bool, result interface{}, err error) {
+	l.logToolResult(1, toolName, success, result, err)
+}
+
+func (l *Logger) logToolResult(skip int, toolName string, success bool, result interface{}, err error) {
 	level := INFO
 	if !success {
 		level = ERROR
 	}
 
 	if l.shouldLog(level) {
+		if l.format == FormatJSON {
+			fields := map[string]interface{}{"tool": toolName, "success": success}
+			if success {
+				fields["result"] = result
+			} else if err != nil {
+				fields["error"] = err.Error()
+			}
+			l.output(skip+1, level, "Tool result: "+toolName, fields)
+			return
+		}
 		var message string
 		if success {
 			message = fmt.Sprintf("\nTool %s completed successfully: %+v", toolName, result)
 		} else {
 			message = fmt.Sprintf("\nTool %s failed: %v", toolName, err)
 		}
-		l.logger.Println(l.formatMessage(level, message))
+		l.output(skip+1, level, message, nil)
 	}
 }
 
 // LogConversation logs conversation messages
 func (l *Logger) LogConversation(role, message string) {
+	l.logConversation(1, role, message)
+}
+
+func (l *Logger) logConversation(skip int, role, message string) {
 	if l.shouldLog(DEBUG) {
 		// Truncate very long messages for logging
 		truncated := message
@@ -249,7 +413,7 @@ func (l *Logger) LogConversation(role, message string) {
 			truncated = message[:500] + "..."
 		}
 		logMessage := fmt.Sprintf("\nConversation [%s]: %s", role, truncated)
-		l.logger.Println(l.formatMessage(DEBUG, logMessage))
+		l.output(skip+1, DEBUG, logMessage, nil)
 	}
 }
 
@@ -262,9 +426,11 @@ type PerformanceTimer struct {
 
 // StartTimer starts a performance timer for the given operation
 func (l *Logger) StartTimer(operation string) *PerformanceTimer {
-	if l.shouldLog(DEBUG) {
-		l.Debug("\nStarting operation: %s", operation)
-	}
+	return l.startTimer(1, operation)
+}
+
+func (l *Logger) startTimer(skip int, operation string) *PerformanceTimer {
+	l.logf(skip+1, DEBUG, "\nStarting operation: %s", operation)
 	return &PerformanceTimer{
 		logger:    l,
 		operation: operation,
@@ -275,8 +441,8 @@ func (l *Logger) StartTimer(operation string) *PerformanceTimer {
 // Stop stops the performance timer and logs the duration
 func (pt *PerformanceTimer) Stop() {
 	duration := time.Since(pt.startTime)
-	if pt.logger.shouldLog(DEBUG) {
-		pt.logger.Debug("Operation %s completed in %v", pt.operation, duration)
+	if pt.logger != nil {
+		pt.logger.logf(1, DEBUG, "Operation %s completed in %v", pt.operation, duration)
 	}
 }
 
@@ -297,64 +463,72 @@ func Shutdown() {
 	}
 }
 
-// Global logging functions using the default logger
+// Global logging functions using the default logger. They call the internal
+// helpers directly so the reported caller is the same as for the methods.
 func Debug(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.Debug(format, args...)
+		defaultLogger.logf(1, DEBUG, format, args...)
 	}
 }
 
 func Info(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.Info(format, args...)
+		defaultLogger.logf(1, INFO, format, args...)
 	}
 }
 
 func Warn(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.Warn(format, args...)
+		defaultLogger.logf(1, WARN, format, args...)
 	}
 }
 
 func Error(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.Error(format, args...)
+		defaultLogger.logf(1, ERROR, format, args...)
 	}
 }
 
 func Fatal(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.Fatal(format, args...)
+		defaultLogger.fatal(1, format, args...)
+	}
+}
+
+func IsEnabled(level LogLevel) bool {
+	if defaultLogger != nil {
+		return defaultLogger.IsEnabled(level)
 	}
+	return false
 }
 
 func ErrorWithStack(err error, format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.ErrorWithStack(err, format, args...)
+		defaultLogger.errorWithStack(1, err, format, args...)
 	}
 }
 
 func LogToolCall(toolName string, params map[string]interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.LogToolCall(toolName, params)
+		defaultLogger.logToolCall(1, toolName, params)
 	}
 }
 
 func LogToolResult(toolName string, success bool, result interface{}, err error) {
 	if defaultLogger != nil {
-		defaultLogger.LogToolResult(toolName, success, result, err)
+		defaultLogger.logToolResult(1, toolName, success, result, err)
 	}
 }
 
 func LogConversation(role, message string) {
 	if defaultLogger != nil {
-		defaultLogger.LogConversation(role, message)
+		defaultLogger.logConversation(1, role, message)
 	}
 }
 
 func StartTimer(operation string) *PerformanceTimer {
 	if defaultLogger != nil {
-		return defaultLogger.StartTimer(operation)
+		return defaultLogger.startTimer(1, operation)
 	}
 	return &PerformanceTimer{
 		operation: operation,
@@ -0,0 +1,214 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newTestLogger returns a logger writing to the returned buffer
+func newTestLogger(t *testing.T, format string) (*Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	l, err := NewLogger(&Config{Level: DEBUG, Format: format, Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, &buf
+}
+
+// jsonLines decodes each line of out as a JSON log entry
+func jsonLines(t *testing.T, out string) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestJSONFormat(t *testing.T) {
+	l, buf := newTestLogger(t, FormatJSON)
+	l.Debug("debug %d", 1)
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+
+	entries := jsonLines(t, buf.String())
+	levels := []string{"DEBUG", "INFO", "WARN", "ERROR"}
+	if len(entries) != len(levels) {
+		t.Fatalf("got %d lines, want %d", len(entries), len(levels))
+	}
+	for i, entry := range entries {
+		if entry["level"] != levels[i] {
+			t.Errorf("line %d level = %v, want %s", i, entry["level"], levels[i])
+		}
+		for _, key := range []string{"timestamp", "caller", "message"} {
+			if _, ok := entry[key]; !ok {
+				t.Errorf("line %d has no %s: %v", i, key, entry)
+			}
+		}
+	}
+	if entries[0]["message"] != "debug 1" {
+		t.Errorf("message = %v, want \"debug 1\"", entries[0]["message"])
+	}
+}
+
+func TestJSONToolLogsNestParams(t *testing.T) {
+	l, buf := newTestLogger(t, FormatJSON)
+	l.LogToolCall("read_file", map[string]interface{}{"path": "main.go"})
+	l.LogToolResult("read_file", true, "package main", nil)
+
+	entries := jsonLines(t, buf.String())
+	fields, ok := entries[0]["fields"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("tool call has no fields object: %v", entries[0])
+	}
+	params, ok := fields["params"].(map[string]interface{})
+	if !ok || params["path"] != "main.go" {
+		t.Errorf("params = %v, want a nested object", fields["params"])
+	}
+	result, ok := entries[1]["fields"].(map[string]interface{})
+	if !ok || result["result"] != "package main" || result["success"] != true {
+		t.Errorf("tool result fields = %v", entries[1]["fields"])
+	}
+}
+
+func TestJSONFormatHasNoPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewLogger(&Config{Level: INFO, Format: FormatJSON, Output: &buf, Prefix: "[Console-AI] "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("hello")
+	if !strings.HasPrefix(buf.String(), "{") {
+		t.Errorf("JSON line does not start with an object: %q", buf.String())
+	}
+}
+
+func TestUnknownFormatFallsBackToText(t *testing.T) {
+	l, buf := newTestLogger(t, "xml")
+	l.Info("hello")
+	if !strings.Contains(buf.String(), "[INFO]") {
+		t.Errorf("output = %q, want text format", buf.String())
+	}
+}
+
+func TestColorOnlyWhenEnabled(t *testing.T) {
+	// A buffer is not a terminal, so color stays off even when requested
+	var buf bytes.Buffer
+	l, err := NewLogger(&Config{Level: INFO, EnableColor: true, Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Warn("plain")
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("non-terminal output is colored: %q", buf.String())
+	}
+
+	buf.Reset()
+	l.color = true
+	l.Warn("colored")
+	if !strings.Contains(buf.String(), levelColors[WARN]+"WARN"+colorReset) {
+		t.Errorf("output has no WARN color: %q", buf.String())
+	}
+}
+
+func TestColorDisabledByNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if colorSupported(os.Stdout) {
+		t.Error("color supported with NO_COLOR set")
+	}
+}
+
+func TestFileOutputIsUncolored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	var buf bytes.Buffer
+	l, err := NewLogger(&Config{Level: INFO, Output: &buf, LogFile: path, EnableFile: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.color = true
+	l.Error("boom")
+	l.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "\033[") || !strings.Contains(string(content), "[ERROR]") {
+		t.Errorf("log file = %q, want uncolored output", content)
+	}
+	if !strings.Contains(buf.String(), levelColors[ERROR]) {
+		t.Errorf("console output = %q, want it colored", buf.String())
+	}
+}
+
+// useDefaultLogger installs l as the default logger for the test
+func useDefaultLogger(t *testing.T, l *Logger) {
+	t.Helper()
+	previous := defaultLogger
+	defaultLogger = l
+	t.Cleanup(func() { defaultLogger = previous })
+}
+
+// nextLine returns the caller's file name and the line after the call
+func nextLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line+1)
+}
+
+func TestCallerOfMethods(t *testing.T) {
+	l, buf := newTestLogger(t, FormatJSON)
+
+	var want []string
+	want = append(want, nextLine())
+	l.Info("method")
+	want = append(want, nextLine())
+	l.LogToolCall("read_file", nil)
+	want = append(want, nextLine())
+	l.StartTimer("walk")
+
+	entries := jsonLines(t, buf.String())
+	if len(entries) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry["caller"] != want[i] {
+			t.Errorf("line %d caller = %v, want %s", i, entry["caller"], want[i])
+		}
+	}
+}
+
+func TestCallerOfPackageFunctions(t *testing.T) {
+	l, buf := newTestLogger(t, FormatJSON)
+	useDefaultLogger(t, l)
+
+	var want []string
+	want = append(want, nextLine())
+	Info("package function")
+	want = append(want, nextLine())
+	LogToolResult("read_file", false, nil, errors.New("missing"))
+	want = append(want, nextLine())
+	ErrorWithStack(errors.New("boom"), "failed")
+
+	entries := jsonLines(t, buf.String())
+	if len(entries) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry["caller"] != want[i] {
+			t.Errorf("line %d caller = %v, want %s", i, entry["caller"], want[i])
+		}
+	}
+}
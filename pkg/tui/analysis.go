@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/history"
+	"console-ai/pkg/logger"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// projectAnalyzedMsg delivers the result of the background project analysis
+type projectAnalyzedMsg struct {
+	info *agent.ProjectInfo
+	err  error
+}
+
+// AnalyzeOnStart makes the TUI analyze the project in the background once
+// it is running, so large projects don't delay the first frame. The analysis
+// is abandoned after timeout with the files found so far.
+func (m *Model) AnalyzeOnStart(timeout time.Duration) {
+	m.analyzing = true
+	m.analysisTimeout = timeout
+}
+
+// analyzeProject runs the project analysis off the UI goroutine
+func (m Model) analyzeProject() tea.Cmd {
+	cfg := m.Config
+	timeout := m.analysisTimeout
+	return func() tea.Msg {
+		analyzer := agent.NewProjectAnalyzer(cfg.ProjectRoot)
+		analyzer.IgnorePatterns = cfg.Agent.IgnorePatterns
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		info, err := analyzer.AnalyzeProject(ctx)
+		return projectAnalyzedMsg{info: info, err: err}
+	}
+}
+
+// handleProjectAnalyzed stores the analysis result with the session and
+// starts a prompt that was submitted while the analysis was running.
+func (m Model) handleProjectAnalyzed(msg projectAnalyzedMsg) (tea.Model, tea.Cmd) {
+	m.analyzing = false
+	if msg.err != nil {
+		logger.Warn("Failed to analyze project: %v", msg.err)
+	} else {
+		m.ProjectInfo = msg.info
+		logger.Info("Project analyzed: %s (%s)", msg.info.Language, msg.info.Framework)
+		if msg.info.Truncated {
+			logger.Warn("Project analysis stopped after %s; the file list is incomplete", m.analysisTimeout)
+		}
+		history.SaveSession(m.Config.ConversationHistory, m.ConversationHistory, m.ToolActions, m.ProjectInfo, m.Config.HumorLevel, m.Config.MaxHistoryTurns)
+	}
+
+	if m.pendingStart == nil {
+		return m, nil
+	}
+	start := *m.pendingStart
+	m.pendingStart = nil
+	return m, func() tea.Msg { return start }
+}
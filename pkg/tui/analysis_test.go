@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/history"
+)
+
+// analyzingModel returns a model that analyzes the project on start
+func analyzingModel(t *testing.T) Model {
+	t.Helper()
+	m := InitialModel(testConfig(t))
+	m.AnalyzeOnStart(time.Second)
+	return resize(m, 100, 30)
+}
+
+func TestProjectAnalyzedMsgUpdatesModel(t *testing.T) {
+	m := analyzingModel(t)
+	if view := m.View(); !strings.Contains(view, "Analyzing project...") {
+		t.Errorf("status bar does not show the running analysis:\n%s", view)
+	}
+
+	info := &agent.ProjectInfo{Language: "Go", Framework: "Gin"}
+	updated, cmd := m.Update(projectAnalyzedMsg{info: info})
+	m = updated.(Model)
+
+	if cmd != nil {
+		t.Error("analysis without a pending prompt returned a command")
+	}
+	if m.analyzing || m.ProjectInfo != info {
+		t.Errorf("analyzing = %v, ProjectInfo = %+v; want the analysis result", m.analyzing, m.ProjectInfo)
+	}
+	if view := m.View(); !strings.Contains(view, "| Go (Gin)") || strings.Contains(view, "Analyzing project...") {
+		t.Errorf("status bar does not show the analyzed project:\n%s", view)
+	}
+	session, err := history.LoadSession(m.Config.ConversationHistory)
+	if err != nil || session == nil || session.ProjectInfo == nil || session.ProjectInfo.Language != "Go" {
+		t.Errorf("saved session = %+v, %v; want the project info", session, err)
+	}
+}
+
+func TestFailedAnalysisKeepsProjectInfo(t *testing.T) {
+	m := analyzingModel(t)
+	updated, _ := m.Update(projectAnalyzedMsg{err: errors.New("walk failed")})
+	m = updated.(Model)
+
+	if m.analyzing || m.ProjectInfo != nil {
+		t.Errorf("analyzing = %v, ProjectInfo = %+v; want no project info", m.analyzing, m.ProjectInfo)
+	}
+	if view := m.View(); strings.Contains(view, "Analyzing project...") {
+		t.Errorf("status bar still shows the analysis:\n%s", view)
+	}
+}
+
+func TestPromptWaitsForAnalysis(t *testing.T) {
+	m := analyzingModel(t)
+	m.Loading = true
+
+	updated, cmd := m.Update(startConversationMsg{input: "explain main.go"})
+	m = updated.(Model)
+	if cmd != nil || m.stream != nil {
+		t.Fatal("a prompt submitted during the analysis started a request")
+	}
+	if view := m.View(); !strings.Contains(view, "Waiting for the project analysis...") {
+		t.Errorf("status bar does not say the prompt is waiting:\n%s", view)
+	}
+
+	updated, cmd = m.Update(projectAnalyzedMsg{info: &agent.ProjectInfo{Language: "Go"}})
+	m = updated.(Model)
+	if m.pendingStart != nil {
+		t.Error("the pending prompt was kept after the analysis finished")
+	}
+	if cmd == nil {
+		t.Fatal("the pending prompt was not started after the analysis")
+	}
+	if msg, ok := cmd().(startConversationMsg); !ok || msg.input != "explain main.go" {
+		t.Errorf("command returned %#v, want the pending prompt", msg)
+	}
+}
+
+func TestAnalyzeProjectCommand(t *testing.T) {
+	m := analyzingModel(t)
+	m.Config.ProjectRoot = t.TempDir()
+	if err := os.WriteFile(filepath.Join(m.Config.ProjectRoot, "go.mod"), []byte("module example\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, ok := m.analyzeProject()().(projectAnalyzedMsg)
+	if !ok {
+		t.Fatal("analyzeProject did not return a projectAnalyzedMsg")
+	}
+	if msg.err != nil || msg.info == nil || msg.info.Language != "Go" {
+		t.Errorf("analysis = %+v, %v; want a Go project", msg.info, msg.err)
+	}
+}
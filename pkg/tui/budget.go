@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// charsPerToken is the rough ratio used to estimate tokens from text
+const charsPerToken = 4
+
+// Fractions of the context window at which the token estimate changes color
+const (
+	budgetWarnFraction     = 0.5
+	budgetCriticalFraction = 0.85
+)
+
+// budgetLevel classifies how full the context window is
+type budgetLevel int
+
+const (
+	budgetOK budgetLevel = iota
+	budgetWarn
+	budgetCritical
+)
+
+// estimateTokens approximates the tokens the history costs per request
+func estimateTokens(history []string) int {
+	chars := 0
+	for _, entry := range history {
+		chars += utf8.RuneCountInString(entry)
+	}
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// contextWindow returns the approximate context window of a model in tokens
+func contextWindow(modelName string) int {
+	name := strings.ToLower(modelName)
+	if strings.HasPrefix(name, "gemini-1.0") || name == "gemini-pro" || name == "gemini-pro-vision" {
+		return 32 * 1024
+	}
+	return 1024 * 1024
+}
+
+// budgetLevelFor returns the level for an estimate against a context window
+func budgetLevelFor(tokens, window int) budgetLevel {
+	if window <= 0 {
+		return budgetOK
+	}
+	used := float64(tokens) / float64(window)
+	switch {
+	case used >= budgetCriticalFraction:
+		return budgetCritical
+	case used >= budgetWarnFraction:
+		return budgetWarn
+	default:
+		return budgetOK
+	}
+}
+
+// formatTokens renders a token count compactly, e.g. 950, 12.3k or 1.2M
+func formatTokens(tokens int) string {
+	switch {
+	case tokens >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(tokens)/1000000)
+	case tokens >= 1000:
+		return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+	default:
+		return fmt.Sprintf("%d", tokens)
+	}
+}
+
+// tokenBudgetView renders the estimated history size for the status bar,
+// colored by how close it is to the model's context window.
+func (m Model) tokenBudgetView() string {
+	tokens := estimateTokens(m.ConversationHistory)
+	text := fmt.Sprintf(" | ~%s tokens", formatTokens(tokens))
+
+	switch budgetLevelFor(tokens, contextWindow(m.Config.ModelName)) {
+	case budgetWarn:
+		return lipgloss.NewStyle().Foreground(m.Theme.Warning).Render(text)
+	case budgetCritical:
+		return lipgloss.NewStyle().Foreground(m.Theme.Danger).Bold(true).Render(text + " - /clear to free context")
+	default:
+		return text
+	}
+}
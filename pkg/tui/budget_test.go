@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	if got := estimateTokens(nil); got != 0 {
+		t.Errorf("empty history = %d tokens, want 0", got)
+	}
+	if got := estimateTokens([]string{"abcd", "efghi"}); got != 3 {
+		t.Errorf("9 characters = %d tokens, want 3", got)
+	}
+	if got := estimateTokens([]string{"ééééé"}); got != 2 {
+		t.Errorf("5 runes = %d tokens, want 2", got)
+	}
+}
+
+func TestBudgetLevelThresholds(t *testing.T) {
+	tests := []struct {
+		tokens, window int
+		want           budgetLevel
+	}{
+		{0, 1000, budgetOK},
+		{499, 1000, budgetOK},
+		{500, 1000, budgetWarn},
+		{849, 1000, budgetWarn},
+		{850, 1000, budgetCritical},
+		{2000, 1000, budgetCritical},
+		{2000, 0, budgetOK},
+	}
+	for _, tt := range tests {
+		if got := budgetLevelFor(tt.tokens, tt.window); got != tt.want {
+			t.Errorf("budgetLevelFor(%d, %d) = %v, want %v", tt.tokens, tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	if got := contextWindow("gemini-1.0-pro"); got != 32*1024 {
+		t.Errorf("gemini-1.0-pro window = %d", got)
+	}
+	if got := contextWindow("gemini-2.5-flash"); got != 1024*1024 {
+		t.Errorf("gemini-2.5-flash window = %d", got)
+	}
+}
+
+func TestTokenBudgetView(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.ModelName = "gemini-1.0-pro"
+	m := InitialModel(cfg)
+
+	m.ConversationHistory = []string{strings.Repeat("a", 4000)}
+	if view := m.tokenBudgetView(); view != " | ~1.0k tokens" {
+		t.Errorf("small history view = %q", view)
+	}
+
+	m.ConversationHistory = []string{strings.Repeat("a", 4*30*1024)}
+	if view := m.tokenBudgetView(); !strings.Contains(view, "~30.7k tokens - /clear to free context") {
+		t.Errorf("critical view = %q, want the /clear hint", view)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	for tokens, want := range map[int]string{950: "950", 12345: "12.3k", 1200000: "1.2M"} {
+		if got := formatTokens(tokens); got != want {
+			t.Errorf("formatTokens(%d) = %q, want %q", tokens, got, want)
+		}
+	}
+}
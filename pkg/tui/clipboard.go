@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"console-ai/pkg/logger"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// statusTimeout is how long transient status messages stay visible
+const statusTimeout = 2 * time.Second
+
+// clearStatusMsg clears a transient status message
+type clearStatusMsg struct{}
+
+// lastResponseText returns the most recent model response: the last model
+// turn in the conversation history, without the tool steps shown in the
+// transcript, or the text being displayed when there is no complete turn yet.
+func (m *Model) lastResponseText() string {
+	// History alternates user/model turns, so a complete history ends with a model reply
+	if n := len(m.ConversationHistory); !m.Loading && n > 0 && n%2 == 0 {
+		return m.ConversationHistory[n-1]
+	}
+	return strings.TrimSpace(m.currentResponse.String())
+}
+
+// copyLastResponse copies the last model response to the system clipboard
+// and shows the outcome in the status bar.
+func (m *Model) copyLastResponse() tea.Cmd {
+	text := m.lastResponseText()
+	switch {
+	case text == "":
+		m.statusMessage = "Nothing to copy yet"
+	case clipboard.Unsupported:
+		m.statusMessage = "Clipboard not available"
+	default:
+		if err := clipboard.WriteAll(text); err != nil {
+			logger.Warn("Failed to copy to clipboard: %v", err)
+			m.statusMessage = "Clipboard not available"
+		} else {
+			m.statusMessage = "Copied last response to clipboard"
+		}
+	}
+	return tea.Tick(statusTimeout, func(time.Time) tea.Msg {
+		return clearStatusMsg{}
+	})
+}
@@ -0,0 +1,34 @@
+package tui
+
+import "testing"
+
+func TestLastResponseText(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	if got := m.lastResponseText(); got != "" {
+		t.Errorf("empty session: %q, want nothing", got)
+	}
+
+	m.ConversationHistory = []string{"question", "the answer"}
+	m.currentResponse.WriteString("> Tool Call: read_file\n\nthe answer")
+	if got := m.lastResponseText(); got != "the answer" {
+		t.Errorf("completed turn: %q, want the reply without tool steps", got)
+	}
+
+	// While a new request streams, the partial output is what's on screen
+	m.Loading = true
+	m.currentResponse.Reset()
+	m.currentResponse.WriteString("  partial reply \n")
+	if got := m.lastResponseText(); got != "partial reply" {
+		t.Errorf("streaming: %q, want the partial reply", got)
+	}
+}
+
+func TestCopyWithNothingToCopy(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	if cmd := m.copyLastResponse(); cmd == nil {
+		t.Error("no command to clear the status message")
+	}
+	if m.statusMessage != "Nothing to copy yet" {
+		t.Errorf("status = %q", m.statusMessage)
+	}
+}
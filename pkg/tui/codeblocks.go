@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/gemini"
+	"console-ai/pkg/logger"
+)
+
+// codeBlock is a fenced code block found in a model response.
+type codeBlock struct {
+	lang    string
+	content string
+}
+
+// fenceLangAliases maps common info-string spellings to the language names
+// CodeGenerator understands.
+var fenceLangAliases = map[string]string{
+	"golang": "go",
+	"js":     "javascript",
+	"jsx":    "javascript",
+	"ts":     "typescript",
+	"tsx":    "typescript",
+	"py":     "python",
+	"rs":     "rust",
+}
+
+// filenameCommentPattern matches a leading comment naming the file, such as
+// "// main.go", "# app.py" or "<!-- index.html -->".
+var filenameCommentPattern = regexp.MustCompile(`^\s*(?://|#|--|<!--|/\*)\s*(?:file(?:name)?:\s*)?([\w./-]+\.\w+)\s*(?:-->|\*/)?\s*$`)
+
+// extractCodeBlocks returns the fenced code blocks of text in order. Both
+// ``` and ~~~ fences are recognized; a block left open at the end of the
+// text runs to the end.
+func extractCodeBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var fence string
+	var body []string
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current == nil {
+			if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+				fence = trimmed[:3]
+				info := strings.Fields(strings.TrimLeft(trimmed, fence[:1]))
+				current = &codeBlock{}
+				if len(info) > 0 {
+					current.lang = strings.ToLower(info[0])
+				}
+				body = nil
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.content = strings.Join(body, "\n")
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		body = append(body, line)
+	}
+	if current != nil && len(body) > 0 {
+		current.content = strings.TrimRight(strings.Join(body, "\n"), "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// suggestedFilename returns the name a block would be saved under: the file
+// named in its first line when there is one, otherwise a name from the
+// CodeGenerator for the block's language.
+func (b codeBlock) suggestedFilename(index int) string {
+	firstLine, _, _ := strings.Cut(b.content, "\n")
+	if match := filenameCommentPattern.FindStringSubmatch(firstLine); match != nil {
+		return match[1]
+	}
+
+	lang := b.lang
+	if alias, ok := fenceLangAliases[lang]; ok {
+		lang = alias
+	}
+	generator := agent.NewCodeGenerator(&agent.ProjectInfo{Language: lang})
+	return generator.GetSuggestedFilename("", fmt.Sprintf("snippet%d", index))
+}
+
+// saveCommand lists the code blocks of the last response, or writes the
+// chosen one to the suggested or given path inside the project root.
+func (m *Model) saveCommand(args []string) string {
+	blocks := extractCodeBlocks(m.lastResponseText())
+	if len(blocks) == 0 {
+		return "The last response has no code blocks to save."
+	}
+
+	if len(args) == 0 {
+		var builder strings.Builder
+		builder.WriteString("Code blocks in the last response:\n")
+		for i, block := range blocks {
+			lines := strings.Count(block.content, "\n") + 1
+			builder.WriteString(fmt.Sprintf("  %d. %s (%s, %d lines)\n", i+1, block.suggestedFilename(i+1), langLabel(block.lang), lines))
+		}
+		builder.WriteString("\nUsage: /save <number> [path]")
+		return builder.String()
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(blocks) {
+		return fmt.Sprintf("Usage: /save <1-%d> [path]", len(blocks))
+	}
+	block := blocks[n-1]
+	path := block.suggestedFilename(n)
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	target, err := m.projectPath(path)
+	if err != nil {
+		return fmt.Sprintf("Not saved: %v", err)
+	}
+	if err := writeNewFile(target, block.content+"\n"); err != nil {
+		return fmt.Sprintf("Not saved: %v", err)
+	}
+	logger.Info("Saved code block %d to %s", n, target)
+	return fmt.Sprintf("Saved code block %d to %s.", n, path)
+}
+
+// langLabel names a block's language for listings.
+func langLabel(lang string) string {
+	if lang == "" {
+		return "no language"
+	}
+	return lang
+}
+
+// projectPath resolves path against the project root and rejects paths
+// that leave it, following symlinks like the file tools do.
+func (m *Model) projectPath(path string) (string, error) {
+	root := m.Config.ProjectRoot
+	if root == "" {
+		root = "."
+	}
+	return gemini.ConfinePath(root, path)
+}
+
+// writeNewFile writes content to path, creating parent directories, and
+// refuses to replace an existing file.
+func writeNewFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("'%s' already exists; give another path", path)
+		}
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// reply with a Go block, a Python block naming its file and a block
+// without a language
+const codeReply = "Here is the server:\n\n" +
+	"```go\npackage main\n\nfunc main() {}\n```\n\n" +
+	"And a helper:\n\n" +
+	"~~~py\n# tools/build.py\nprint(\"build\")\n~~~\n\n" +
+	"```\nmake run\n```\n"
+
+func TestExtractCodeBlocks(t *testing.T) {
+	want := []codeBlock{
+		{lang: "go", content: "package main\n\nfunc main() {}"},
+		{lang: "py", content: "# tools/build.py\nprint(\"build\")"},
+		{lang: "", content: "make run"},
+	}
+	if got := extractCodeBlocks(codeReply); !reflect.DeepEqual(got, want) {
+		t.Errorf("extractCodeBlocks = %#v, want %#v", got, want)
+	}
+}
+
+func TestExtractCodeBlocksEdgeCases(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []codeBlock
+	}{
+		{"no blocks", "just prose", nil},
+		{"info string after language", "```Go title=main.go\nx := 1\n```", []codeBlock{{lang: "go", content: "x := 1"}}},
+		{"shorter fence inside", "~~~md\n```\ninner\n```\n~~~", []codeBlock{{lang: "md", content: "```\ninner\n```"}}},
+		{"unclosed block", "```rust\nfn main() {}\n", []codeBlock{{lang: "rust", content: "fn main() {}"}}},
+	}
+	for _, tt := range tests {
+		if got := extractCodeBlocks(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: extractCodeBlocks = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestedFilename(t *testing.T) {
+	tests := []struct {
+		block codeBlock
+		want  string
+	}{
+		{codeBlock{lang: "go", content: "package main"}, "snippet1.go"},
+		{codeBlock{lang: "golang", content: "package main"}, "snippet1.go"},
+		{codeBlock{lang: "ts", content: "let x = 1"}, "snippet1.ts"},
+		{codeBlock{lang: "rs", content: "fn main() {}"}, "snippet1.rs"},
+		{codeBlock{lang: "", content: "make run"}, "snippet1.txt"},
+		{codeBlock{lang: "py", content: "# tools/build.py\nprint()"}, "tools/build.py"},
+		{codeBlock{lang: "go", content: "// file: cmd/app/main.go\npackage main"}, "cmd/app/main.go"},
+		{codeBlock{lang: "html", content: "<!-- index.html -->\n<p></p>"}, "index.html"},
+	}
+	for _, tt := range tests {
+		if got := tt.block.suggestedFilename(1); got != tt.want {
+			t.Errorf("suggestedFilename(%q) = %q, want %q", tt.block.lang, got, tt.want)
+		}
+	}
+}
+
+// savingModel returns a model rooted in a temporary project whose last
+// response is codeReply
+func savingModel(t *testing.T) Model {
+	t.Helper()
+	cfg := testConfig(t)
+	cfg.ProjectRoot = t.TempDir()
+	m := InitialModel(cfg)
+	m.ConversationHistory = []string{"write a server", codeReply}
+	return m
+}
+
+func TestSaveListsBlocks(t *testing.T) {
+	m := savingModel(t)
+
+	out := m.saveCommand(nil)
+	for _, want := range []string{"1. snippet1.go (go, 3 lines)", "2. tools/build.py (py, 2 lines)", "3. snippet3.txt (no language, 1 lines)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("listing is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSaveWritesChosenBlock(t *testing.T) {
+	m := savingModel(t)
+
+	if out := m.saveCommand([]string{"2"}); out != "Saved code block 2 to tools/build.py." {
+		t.Errorf("saveCommand = %q", out)
+	}
+	content, err := os.ReadFile(filepath.Join(m.Config.ProjectRoot, "tools", "build.py"))
+	if err != nil || string(content) != "# tools/build.py\nprint(\"build\")\n" {
+		t.Errorf("saved file = %q, %v", content, err)
+	}
+
+	if out := m.saveCommand([]string{"2"}); !strings.Contains(out, "already exists") {
+		t.Errorf("saving again = %q, want the existing file kept", out)
+	}
+	if out := m.saveCommand([]string{"4"}); out != "Usage: /save <1-3> [path]" {
+		t.Errorf("saving a missing block = %q", out)
+	}
+}
+
+func TestSaveStaysInProject(t *testing.T) {
+	m := savingModel(t)
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(m.Config.ProjectRoot, "link")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	for _, path := range []string{"../escape.go", filepath.Join(outside, "abs.go"), "link/escape.go"} {
+		if out := m.saveCommand([]string{"1", path}); !strings.HasPrefix(out, "Not saved:") {
+			t.Errorf("saving to %s = %q, want it refused", path, out)
+		}
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("files were written outside the project: %v", entries)
+	}
+}
@@ -0,0 +1,327 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"console-ai/pkg/config"
+	"console-ai/pkg/gemini"
+	"console-ai/pkg/history"
+	"console-ai/pkg/logger"
+)
+
+// slashCommand describes a command handled locally by the TUI.
+type slashCommand struct {
+	name        string
+	usage       string
+	description string
+}
+
+// slashCommands is the registry of slash commands shown in help and autocomplete.
+var slashCommands = []slashCommand{
+	{name: "/clear", usage: "/clear", description: "Clear the conversation history of this session"},
+	{name: "/debug", usage: "/debug", description: "Show the raw last request and response"},
+	{name: "/help", usage: "/help", description: "List the available commands"},
+	{name: "/history", usage: "/history [turns]", description: "Review recent turns and the tool actions taken"},
+	{name: "/humor", usage: "/humor <0-100>", description: "Set the humor level"},
+	{name: "/model", usage: "/model <name>", description: "Switch the Gemini model"},
+	{name: "/save", usage: "/save [n] [path]", description: "List the code blocks of the last response or save one to a file"},
+	{name: "/search", usage: "/search <text>", description: "Search the conversation history"},
+	{name: "/sessions", usage: "/sessions", description: "List the saved sessions"},
+	{name: "/tone", usage: "/tone [name|off]", description: "Show or set the tone profile"},
+	{name: "/undo", usage: "/undo", description: "Revert the last file change made by a tool"},
+}
+
+// maxSuggestions limits the number of commands shown in the autocomplete list.
+const maxSuggestions = 6
+
+// filterCommands returns the registered commands whose name starts with prefix.
+func filterCommands(prefix string) []slashCommand {
+	prefix = strings.ToLower(prefix)
+	var matches []slashCommand
+	for _, command := range slashCommands {
+		if strings.HasPrefix(command.name, prefix) {
+			matches = append(matches, command)
+		}
+	}
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	return matches
+}
+
+// isCommand reports whether the input is a slash command handled locally by the
+// TUI. Only registered command names count, so a prompt that merely starts
+// with a path like "/etc/hosts" still goes to the model.
+func isCommand(input string) bool {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return false
+	}
+	name := strings.ToLower(fields[0])
+	for _, command := range slashCommands {
+		if command.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// handleCommand executes a slash command and renders its output in the viewport.
+func (m *Model) handleCommand(input string) {
+	fields := strings.Fields(input)
+	name := strings.ToLower(fields[0])
+
+	// A pending /clear is only confirmed by the very next command
+	confirmClear := m.confirmClear
+	m.confirmClear = false
+
+	var output string
+	switch name {
+	case "/clear":
+		output = m.clearCommand(confirmClear)
+	case "/debug":
+		output = m.debugCommand()
+	case "/help":
+		output = helpCommand(m.Version)
+	case "/history":
+		output = m.historyCommand(fields[1:])
+	case "/humor":
+		output = m.humorCommand(fields[1:])
+	case "/model":
+		output = m.modelCommand(fields[1:])
+	case "/save":
+		output = m.saveCommand(fields[1:])
+	case "/sessions":
+		output = m.sessionsCommand()
+	case "/search":
+		output = m.searchCommand(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), fields[0])))
+	case "/tone":
+		output = m.toneCommand(fields[1:])
+	case "/undo":
+		output = undoCommand()
+	default:
+		output = fmt.Sprintf("Unknown command: %s", name)
+	}
+
+	m.resetOutput()
+	m.writeOutput(output, false)
+	m.renderView()
+}
+
+// clearCommand empties the conversation history of the active session while
+// keeping the project context. It asks for confirmation first.
+func (m *Model) clearCommand(confirmed bool) string {
+	if !confirmed {
+		m.confirmClear = true
+		return "This erases the conversation history of this session. Type /clear again to confirm."
+	}
+
+	m.ConversationHistory = []string{}
+	m.ToolActions = nil
+	m.lastRendered = ""
+	m.Viewport.SetContent("")
+	if err := history.SaveSession(m.Config.ConversationHistory, m.ConversationHistory, nil, m.ProjectInfo, m.Config.HumorLevel, m.Config.MaxHistoryTurns); err != nil {
+		return fmt.Sprintf("Conversation cleared, but saving the session failed: %v", err)
+	}
+	return "Conversation cleared."
+}
+
+// helpCommand lists the registered slash commands.
+func helpCommand(version string) string {
+	var builder strings.Builder
+	if version != "" {
+		builder.WriteString(version + "\n\n")
+	}
+	builder.WriteString("Commands:\n")
+	for _, command := range slashCommands {
+		builder.WriteString(fmt.Sprintf("  %-16s %s\n", command.usage, command.description))
+	}
+	return builder.String()
+}
+
+// defaultHistoryTurns is the number of turns shown by /history without an argument.
+const defaultHistoryTurns = 5
+
+// historyCommand lists the most recent turns with the tool actions taken in each.
+func (m *Model) historyCommand(args []string) string {
+	count := defaultHistoryTurns
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return "Usage: /history [turns]"
+		}
+		count = n
+	}
+
+	turns := len(m.ConversationHistory) / 2
+	if turns == 0 {
+		return "No conversation history yet."
+	}
+	actions := history.AlignToolActions(m.ToolActions, m.ConversationHistory)
+
+	var builder strings.Builder
+	for turn := max(0, turns-count); turn < turns; turn++ {
+		builder.WriteString(fmt.Sprintf("Turn %d\n", turn+1))
+		builder.WriteString(fmt.Sprintf("  You: %s\n", oneLine(m.ConversationHistory[turn*2])))
+		for _, action := range actions[turn] {
+			builder.WriteString(fmt.Sprintf("  ⚙ %s\n", action))
+		}
+		builder.WriteString(fmt.Sprintf("  AI: %s\n\n", oneLine(m.ConversationHistory[turn*2+1])))
+	}
+	return builder.String()
+}
+
+// oneLine collapses text to a single truncated line for listings.
+func oneLine(text string) string {
+	return truncateText(strings.Join(strings.Fields(text), " "), 120)
+}
+
+// humorCommand sets the humor level, saves it with the session and applies it
+// to the system instruction for the next turn.
+func (m *Model) humorCommand(args []string) string {
+	if len(args) != 1 {
+		return fmt.Sprintf("Current humor level: %d%%\nUsage: /humor <%d-%d>", m.Config.HumorLevel, config.MinHumorLevel, config.MaxHumorLevel)
+	}
+
+	level, err := strconv.Atoi(strings.TrimSuffix(args[0], "%"))
+	if err != nil {
+		return fmt.Sprintf("Invalid humor level %q. Usage: /humor <%d-%d>", args[0], config.MinHumorLevel, config.MaxHumorLevel)
+	}
+	level = config.ClampHumorLevel(level)
+
+	m.Config.HumorLevel = level
+	if m.Gemini != nil {
+		gemini.ApplySystemInstruction(m.Gemini, m.Config, level, m.ProjectInfo)
+	}
+	if err := history.SaveHumorLevel(m.Config.ConversationHistory, level); err != nil {
+		return fmt.Sprintf("Humor level set to %d%%, but saving the session failed: %v", level, err)
+	}
+	return fmt.Sprintf("Humor level set to %d%%.", level)
+}
+
+// toneCommand sets the tone profile, saves it with the session and applies
+// it to the system instruction for the next turn. "off" removes the tone.
+func (m *Model) toneCommand(args []string) string {
+	current := m.Config.Tone
+	if current == "" {
+		current = "off"
+	}
+	if len(args) != 1 {
+		return fmt.Sprintf("Current tone: %s\nUsage: /tone <%s|off>", current, strings.Join(config.Tones, "|"))
+	}
+
+	tone := strings.ToLower(args[0])
+	if tone == "off" {
+		tone = ""
+	}
+	if !config.ValidTone(tone) {
+		return fmt.Sprintf("Unknown tone %q. Available tones: %s, off", args[0], strings.Join(config.Tones, ", "))
+	}
+
+	m.Config.Tone = tone
+	if m.Gemini != nil {
+		gemini.ApplySystemInstruction(m.Gemini, m.Config, m.Config.HumorLevel, m.ProjectInfo)
+	}
+	label := tone
+	if label == "" {
+		label = "off"
+	}
+	if err := history.SaveTone(m.Config.ConversationHistory, tone); err != nil {
+		return fmt.Sprintf("Tone set to %s, but saving the session failed: %v", label, err)
+	}
+	return fmt.Sprintf("Tone set to %s.", label)
+}
+
+// modelCommand switches the Gemini model, keeping the current conversation.
+func (m *Model) modelCommand(args []string) string {
+	if len(args) != 1 {
+		return fmt.Sprintf("Current model: %s\nUsage: /model <name>\nAvailable models: %s", m.Config.ModelName, strings.Join(gemini.KnownModels, ", "))
+	}
+
+	name := args[0]
+	if !gemini.IsKnownModel(name) {
+		return fmt.Sprintf("Unknown model %q. Available models: %s", name, strings.Join(gemini.KnownModels, ", "))
+	}
+
+	model, client, err := gemini.NewClient(m.Config, name)
+	if err != nil {
+		return fmt.Sprintf("Failed to switch model: %v", err)
+	}
+	if m.GeminiClient != nil {
+		m.GeminiClient.Close()
+	}
+	m.Gemini, m.GeminiClient = model, client
+	m.Config.ModelName = name
+	logger.Info("Switched model to %s", name)
+	return fmt.Sprintf("Switched model to %s.", name)
+}
+
+// debugCommand renders the raw last request and response. It is only available
+// when debug logging is enabled since the output may contain sensitive context.
+func (m *Model) debugCommand() string {
+	if !logger.IsEnabled(logger.DEBUG) {
+		return "The /debug command requires debug logging. Set CONSOLE_AI_LOG_LEVEL=DEBUG and restart."
+	}
+	return gemini.LastTurnDebug(m.Config.GeminiAPIKey)
+}
+
+// sessionsCommand lists the named sessions stored in the working directory.
+func (m *Model) sessionsCommand() string {
+	sessions, err := history.ListSessions()
+	if err != nil {
+		return fmt.Sprintf("Failed to list sessions: %v", err)
+	}
+	if len(sessions) == 0 {
+		return "No saved sessions yet."
+	}
+
+	active := m.Config.Session
+	if active == "" {
+		active = history.DefaultSessionName
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Sessions:\n")
+	for _, name := range sessions {
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+		builder.WriteString(marker + name + "\n")
+	}
+	return builder.String()
+}
+
+// searchCommand searches the stored conversation history of the active session.
+func (m *Model) searchCommand(query string) string {
+	if query == "" {
+		return "Usage: /search <text> or /search /regex/"
+	}
+
+	matches, err := history.SearchHistory(m.Config.ConversationHistory, query)
+	if err != nil {
+		return fmt.Sprintf("Search failed: %v", err)
+	}
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for %q.", query)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%d matches for %q:\n\n", len(matches), query))
+	for _, match := range matches {
+		builder.WriteString(fmt.Sprintf("[turn %d, %s] %s\n", match.Turn, match.Role, match.Snippet))
+	}
+	return builder.String()
+}
+
+// undoCommand reverts the most recent file change made by a tool.
+func undoCommand() string {
+	result, err := gemini.UndoLastChange()
+	if err != nil {
+		return fmt.Sprintf("Nothing undone: %v", err)
+	}
+	logger.Info("Undo: %s", result)
+	return result
+}
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"console-ai/pkg/agent"
+	"console-ai/pkg/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// submit types input into the input box and presses enter
+func submit(m Model, input string) Model {
+	m.TextInput.SetValue(input)
+	return press(m, tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestIsCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"/help", true},
+		{"  /humor 40", true},
+		{"/HELP", true},
+		{"/etc/hosts is broken, why?", false},
+		{"/usr/bin/env: python: not found", false},
+		{"/", false},
+		{"explain /help", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isCommand(tt.input); got != tt.want {
+			t.Errorf("isCommand(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPathPromptGoesToModel(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 30)
+	m = submit(m, "/etc/hosts is broken, why?")
+	if !m.Loading || m.submittedInput != "/etc/hosts is broken, why?" {
+		t.Errorf("Loading = %v, submitted %q; want the prompt sent to the model", m.Loading, m.submittedInput)
+	}
+	if strings.Contains(m.currentResponse.String(), "Unknown command") {
+		t.Error("the prompt was handled as an unknown command")
+	}
+}
+
+func TestClearKeepsProjectInfo(t *testing.T) {
+	cfg := testConfig(t)
+	m := InitialModel(cfg)
+	m.ProjectInfo = &agent.ProjectInfo{Language: "Go"}
+	m.ConversationHistory = []string{"question", "answer"}
+	m.ToolActions = [][]history.ToolAction{{{Name: "list_files"}}}
+
+	m = submit(m, "/clear")
+	if len(m.ConversationHistory) != 2 {
+		t.Fatal("history cleared without confirmation")
+	}
+	m = submit(m, "/clear")
+
+	if len(m.ConversationHistory) != 0 || m.ToolActions != nil {
+		t.Errorf("history = %q, actions = %v; want both empty", m.ConversationHistory, m.ToolActions)
+	}
+	if m.ProjectInfo == nil || m.ProjectInfo.Language != "Go" {
+		t.Errorf("ProjectInfo = %+v, want it kept", m.ProjectInfo)
+	}
+	if !strings.Contains(m.currentResponse.String(), "Conversation cleared.") {
+		t.Errorf("output = %q", m.currentResponse.String())
+	}
+
+	data, err := history.LoadSession(cfg.ConversationHistory)
+	if err != nil || data == nil {
+		t.Fatalf("LoadSession = %v, %v", data, err)
+	}
+	if len(data.Conversations) != 0 || data.ProjectInfo == nil || data.ProjectInfo.Language != "Go" {
+		t.Errorf("saved session = %+v", data)
+	}
+}
+
+func TestClearNeedsImmediateConfirmation(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	m.ConversationHistory = []string{"question", "answer"}
+
+	m = submit(m, "/clear")
+	m = submit(m, "/help")
+	m = submit(m, "/clear")
+	if len(m.ConversationHistory) != 2 {
+		t.Error("a /clear confirmed after another command cleared the history")
+	}
+}
+
+func TestModelCommandRecreatesClient(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.GeminiAPIKey = "test-key"
+	m := InitialModel(cfg)
+	m.ConversationHistory = []string{"question", "answer"}
+
+	m = submit(m, "/model gemini-2.5-pro")
+	if cfg.ModelName != "gemini-2.5-pro" {
+		t.Errorf("ModelName = %s, want gemini-2.5-pro", cfg.ModelName)
+	}
+	first := m.Gemini
+	if first == nil {
+		t.Fatal("no client was created")
+	}
+	if len(m.ConversationHistory) != 2 {
+		t.Error("switching models dropped the conversation")
+	}
+
+	firstClient := m.GeminiClient
+	if firstClient == nil {
+		t.Fatal("the client owning the model was not kept")
+	}
+
+	m = submit(m, "/model gemini-2.5-flash")
+	if m.Gemini == first || m.GeminiClient == firstClient {
+		t.Error("the client was not recreated")
+	}
+	if view := m.View(); !strings.Contains(view, "Model: gemini-2.5-flash") {
+		t.Errorf("status bar does not show the new model:\n%s", view)
+	}
+}
+
+func TestModelCommandRejectsUnknownModel(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.GeminiAPIKey = "test-key"
+	m := InitialModel(cfg)
+
+	m = submit(m, "/model gpt-4")
+	if cfg.ModelName != "gemini-2.5-flash" || m.Gemini != nil {
+		t.Errorf("unknown model changed the client (model %s)", cfg.ModelName)
+	}
+	if !strings.Contains(m.currentResponse.String(), `Unknown model "gpt-4"`) {
+		t.Errorf("output = %q", m.currentResponse.String())
+	}
+}
+
+func TestHumorCommandClampsAndStores(t *testing.T) {
+	cfg := testConfig(t)
+	m := InitialModel(cfg)
+
+	for _, tt := range []struct {
+		arg  string
+		want int
+	}{
+		{"150", 100},
+		{"-10", 0},
+		{"35%", 35},
+	} {
+		m = submit(m, "/humor "+tt.arg)
+		if cfg.HumorLevel != tt.want {
+			t.Errorf("/humor %s: HumorLevel = %d, want %d", tt.arg, cfg.HumorLevel, tt.want)
+		}
+		data, err := history.LoadSession(cfg.ConversationHistory)
+		if err != nil || data == nil {
+			t.Fatalf("LoadSession = %v, %v", data, err)
+		}
+		if data.HumorLevel != tt.want {
+			t.Errorf("/humor %s: saved humor = %d, want %d", tt.arg, data.HumorLevel, tt.want)
+		}
+	}
+
+	m = submit(m, "/humor lots")
+	if cfg.HumorLevel != 35 {
+		t.Errorf("invalid level changed HumorLevel to %d", cfg.HumorLevel)
+	}
+	if !strings.Contains(m.currentResponse.String(), `Invalid humor level "lots"`) {
+		t.Errorf("output = %q", m.currentResponse.String())
+	}
+}
+
+func TestToneCommand(t *testing.T) {
+	m := InitialModel(testConfig(t))
+
+	if out := m.toneCommand([]string{"Snarky"}); out != "Tone set to snarky." {
+		t.Errorf("/tone Snarky = %q", out)
+	}
+	if m.Config.Tone != "snarky" {
+		t.Errorf("Tone = %q, want snarky", m.Config.Tone)
+	}
+	data, err := history.LoadSession(m.Config.ConversationHistory)
+	if err != nil || data == nil || data.Tone != "snarky" {
+		t.Errorf("saved session = %+v, %v; want the tone persisted", data, err)
+	}
+	if out := m.toneCommand(nil); !strings.HasPrefix(out, "Current tone: snarky\n") {
+		t.Errorf("/tone = %q, want the current tone", out)
+	}
+
+	if out := m.toneCommand([]string{"off"}); out != "Tone set to off." || m.Config.Tone != "" {
+		t.Errorf("/tone off = %q, tone %q", out, m.Config.Tone)
+	}
+	if out := m.toneCommand([]string{"grumpy"}); !strings.HasPrefix(out, `Unknown tone "grumpy"`) || m.Config.Tone != "" {
+		t.Errorf("/tone grumpy = %q, tone %q", out, m.Config.Tone)
+	}
+}
@@ -9,19 +9,31 @@ import (
 // helpKeyMap defines the key bindings for the help view.
 // It is used to navigate the help view and to close it.
 type helpKeyMap struct {
-	help key.Binding
-	quit key.Binding
+	help     key.Binding
+	quit     key.Binding
+	focus    key.Binding
+	up       key.Binding
+	down     key.Binding
+	pageUp   key.Binding
+	pageDown key.Binding
+	home     key.Binding
+	end      key.Binding
+	copy     key.Binding
+	complete key.Binding
+	cancel   key.Binding
+	steps    key.Binding
 }
 
 // ShortHelp returns a slice of key bindings to be displayed in the short help view.
 func (k helpKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.help, k.quit}
+	return []key.Binding{k.help, k.focus, k.pageUp, k.pageDown, k.quit}
 }
 
 // FullHelp returns a slice of key bindings to be displayed in the full help view.
 func (k helpKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.help, k.quit},
+		{k.up, k.down, k.pageUp, k.pageDown, k.home, k.end},
+		{k.complete, k.focus, k.copy, k.steps, k.cancel, k.help, k.quit},
 	}
 }
 
@@ -36,13 +48,57 @@ func newHelpKeyMap() *helpKeyMap {
 			key.WithKeys("q", "esc"),
 			key.WithHelp("q", "quit"),
 		),
+		focus: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "focus input/output"),
+		),
+		up: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑", "scroll up"),
+		),
+		down: key.NewBinding(
+			key.WithKeys("down"),
+			key.WithHelp("↓", "scroll down"),
+		),
+		pageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("pgup", "page up"),
+		),
+		pageDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+			key.WithHelp("pgdn", "page down"),
+		),
+		home: key.NewBinding(
+			key.WithKeys("home"),
+			key.WithHelp("home", "scroll to top"),
+		),
+		end: key.NewBinding(
+			key.WithKeys("end"),
+			key.WithHelp("end", "scroll to bottom"),
+		),
+		cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel request"),
+		),
+		complete: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "complete /command"),
+		),
+		copy: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy last response"),
+		),
+		steps: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "toggle step log"),
+		),
 	}
 }
 
-// newHelp creates a new help model with the given key map.
-func newHelp(keys *helpKeyMap) help.Model {
+// newHelp creates a new help model with the given key map and theme.
+func newHelp(keys *helpKeyMap, theme Theme) help.Model {
 	h := help.New()
-	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
-	h.Styles.FullDesc = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(theme.Muted)
+	h.Styles.FullDesc = lipgloss.NewStyle().Foreground(theme.Muted)
 	return h
 }
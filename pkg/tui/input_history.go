@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"console-ai/pkg/history"
+	"console-ai/pkg/logger"
+)
+
+// rememberInput records a submitted prompt for recall and persists the list.
+// Consecutive duplicates are stored once.
+func (m *Model) rememberInput(input string) {
+	m.inputIndex = -1
+	if input == "" {
+		return
+	}
+	if n := len(m.InputHistory); n > 0 && m.InputHistory[n-1] == input {
+		return
+	}
+
+	m.InputHistory = append(m.InputHistory, input)
+	if len(m.InputHistory) > history.MaxInputHistory {
+		m.InputHistory = m.InputHistory[len(m.InputHistory)-history.MaxInputHistory:]
+	}
+
+	if err := history.SaveInputHistory(m.Config.ConversationHistory, m.InputHistory); err != nil {
+		logger.Warn("Failed to save input history: %v", err)
+	}
+}
+
+// canRecallInput reports whether Up/Down should cycle through input history
+// rather than being left to the input box. Recall only takes over when the
+// input is empty or still shows a recalled entry, so edits are not lost.
+func (m *Model) canRecallInput() bool {
+	if len(m.InputHistory) == 0 {
+		return false
+	}
+	value := m.TextInput.Value()
+	if m.inputIndex < 0 || m.inputIndex >= len(m.InputHistory) {
+		return value == ""
+	}
+	return value == m.InputHistory[m.inputIndex]
+}
+
+// recallInput moves through input history by step (-1 for older, 1 for newer)
+// and restores the selected entry into the input box. Positions wrap around,
+// with an empty input between the newest and the oldest entry.
+func (m *Model) recallInput(step int) {
+	n := len(m.InputHistory)
+	pos := m.inputIndex
+	if pos < 0 || pos >= n {
+		pos = n
+	}
+
+	pos = (pos + step + n + 1) % (n + 1)
+	if pos == n {
+		m.inputIndex = -1
+		m.TextInput.SetValue("")
+		return
+	}
+
+	m.inputIndex = pos
+	m.TextInput.SetValue(m.InputHistory[pos])
+	m.TextInput.CursorEnd()
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	"console-ai/pkg/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRecallInputCyclesAndWraps(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	m.InputHistory = []string{"first", "second", "third"}
+
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	steps := []struct {
+		key  tea.KeyMsg
+		want string
+	}{
+		{up, "third"},
+		{up, "second"},
+		{up, "first"},
+		{up, ""}, // wraps past the oldest entry to an empty input
+		{up, "third"},
+		{down, ""},
+		{down, "first"},
+		{down, "second"},
+	}
+	for i, step := range steps {
+		m = press(m, step.key)
+		if got := m.TextInput.Value(); got != step.want {
+			t.Fatalf("step %d: input = %q, want %q", i, got, step.want)
+		}
+	}
+}
+
+func TestRecallInputKeepsEdits(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	m.InputHistory = []string{"first"}
+	m.TextInput.SetValue("half typed")
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.TextInput.Value(); got != "half typed" {
+		t.Errorf("input = %q, want the edit kept", got)
+	}
+}
+
+func TestRememberInputPersists(t *testing.T) {
+	cfg := testConfig(t)
+	m := InitialModel(cfg)
+	m.rememberInput("build it")
+	m.rememberInput("build it")
+	m.rememberInput("test it")
+
+	if want := []string{"build it", "test it"}; !reflect.DeepEqual(m.InputHistory, want) {
+		t.Errorf("InputHistory = %q, want %q", m.InputHistory, want)
+	}
+	data, err := history.LoadSession(cfg.ConversationHistory)
+	if err != nil || data == nil {
+		t.Fatalf("LoadSession = %v, %v", data, err)
+	}
+	if !reflect.DeepEqual(data.InputHistory, m.InputHistory) {
+		t.Errorf("saved InputHistory = %q, want %q", data.InputHistory, m.InputHistory)
+	}
+}
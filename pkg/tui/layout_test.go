@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestViewAtTinyWidths(t *testing.T) {
+	for _, width := range []int{0, 1, 5, 7, 8} {
+		t.Run(fmt.Sprintf("width %d", width), func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.Session = "a-rather-long-session-name"
+			m := resize(InitialModel(cfg), width, 3)
+			m.ConversationHistory = []string{strings.Repeat("history ", 50)}
+			m.currentResponse.WriteString(strings.Repeat("a long line of output ", 20))
+			m.renderView()
+
+			if view := m.View(); !utf8.ValidString(view) {
+				t.Errorf("view is not valid UTF-8 at width %d", width)
+			}
+		})
+	}
+}
+
+func TestFitWidth(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"status", 10, "status"},
+		{"status bar text", 10, "status ..."},
+		{"status", 3, "sta"},
+		{"status", 0, ""},
+		{"status", -4, ""},
+		{"héllo wörld", 5, "hé..."},
+		{"日本語のテキスト", 7, "日本..."},
+		{"日本語", 3, "日"},
+	}
+	for _, tt := range tests {
+		if got := fitWidth(tt.text, tt.width); got != tt.want {
+			t.Errorf("fitWidth(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestFitWidthKeepsStyling(t *testing.T) {
+	styled := "\x1b[38;5;212m=^.^=\x1b[0m purring along the status bar"
+	for width := 1; width <= lipgloss.Width(styled); width++ {
+		got := fitWidth(styled, width)
+		if w := lipgloss.Width(got); w > width {
+			t.Errorf("fitWidth(width %d) is %d cells wide: %q", width, w, got)
+		}
+		// A cut escape would leave its tail behind as visible text.
+		if plain := stripANSI(got); strings.ContainsAny(plain, "\x1b[;") {
+			t.Errorf("fitWidth(width %d) cut an escape sequence: %q", width, got)
+		}
+	}
+	if got, want := stripANSI(fitWidth(styled, 12)), "=^.^= pur..."; got != want {
+		t.Errorf("fitWidth(styled, 12) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"strings"
+
+	"console-ai/pkg/logger"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// renderMarkdown renders text as markdown wrapped to width. It falls back to
+// plain wrapped text when the markdown renderer can't be created or fails.
+func (m *Model) renderMarkdown(text string, width int) string {
+	if width <= 0 {
+		width = 80
+	}
+
+	// Renderers are tied to a wrap width, so only rebuild one on resize
+	if m.markdown == nil || m.markdownWidth != width {
+		renderer, err := glamour.NewTermRenderer(
+			// Theme names match glamour's standard dark and light styles
+			glamour.WithStandardStyle(m.Theme.Name),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			logger.Debug("Failed to create markdown renderer: %v", err)
+			return m.wrapText(text, width)
+		}
+		m.markdown = renderer
+		m.markdownWidth = width
+	}
+
+	rendered, err := m.markdown.Render(text)
+	if err != nil {
+		logger.Debug("Failed to render markdown: %v", err)
+		return m.wrapText(text, width)
+	}
+	return strings.Trim(rendered, "\n")
+}
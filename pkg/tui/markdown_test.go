@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// ansiEscape matches ANSI escape sequences
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+func TestRenderMarkdownStylesCodeBlocks(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	out := m.renderMarkdown("Run this:\n\n```go\nfmt.Println(\"hi\")\n```\n", 60)
+
+	if !strings.Contains(out, "\x1b[") {
+		t.Errorf("rendered code block has no ANSI styling: %q", out)
+	}
+	if !strings.Contains(stripANSI(out), `fmt.Println("hi")`) {
+		t.Errorf("rendered output lost the code: %q", out)
+	}
+}
+
+// tokenColor returns the ANSI sequence that styles the first occurrence of
+// token in s, or "" when it is unstyled
+func tokenColor(s, token string) string {
+	i := strings.Index(s, token)
+	if i < 0 {
+		return ""
+	}
+	seqs := ansiEscape.FindAllString(s[:i], -1)
+	if len(seqs) == 0 || !strings.HasSuffix(s[:i], seqs[len(seqs)-1]) {
+		return ""
+	}
+	return seqs[len(seqs)-1]
+}
+
+func TestGoCodeBlockIsHighlighted(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	out := m.renderMarkdown("```go\nfunc main() {\n\treturn\n}\n```\n", 60)
+
+	keyword, name := tokenColor(out, "func"), tokenColor(out, "main")
+	if keyword == "" || name == "" {
+		t.Fatalf("go tokens are not colored: %q", out)
+	}
+	if keyword == name {
+		t.Errorf("keyword and function name share the color %q", keyword)
+	}
+}
+
+func TestUnknownLanguageKeepsCode(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	out := m.renderMarkdown("```nosuchlanguage\nfrobnicate --all <input>\n```\n", 60)
+	if !strings.Contains(stripANSI(out), "frobnicate --all <input>") {
+		t.Errorf("unknown language lost the code: %q", stripANSI(out))
+	}
+}
+
+func TestCodeBlockFollowsTheme(t *testing.T) {
+	dark := testConfig(t)
+	dark.UI.Theme = "dark"
+	light := testConfig(t)
+	light.UI.Theme = "light"
+	block := "```go\nfunc main() {}\n```\n"
+
+	darkModel, lightModel := InitialModel(dark), InitialModel(light)
+	darkOut := darkModel.renderMarkdown(block, 60)
+	lightOut := lightModel.renderMarkdown(block, 60)
+	if darkOut == lightOut {
+		t.Error("the dark and light themes highlight code the same way")
+	}
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isMultilinePaste reports whether a bracketed paste contains line breaks.
+// The single-line input would flatten those, so such pastes are kept aside.
+func isMultilinePaste(text string) bool {
+	return strings.ContainsAny(text, "\r\n")
+}
+
+// insertPaste keeps multi-line pasted text aside and inserts a placeholder
+// for it at the cursor. The text is put back in place when the prompt is
+// submitted, so pasted newlines never submit a partial prompt.
+func (m *Model) insertPaste(text string) {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	m.pastes = append(m.pastes, text)
+	placeholder := pastePlaceholder(len(m.pastes), strings.Count(strings.TrimRight(text, "\n"), "\n")+1)
+
+	value := []rune(m.TextInput.Value())
+	pos := min(m.TextInput.Position(), len(value))
+	m.TextInput.SetValue(string(value[:pos]) + placeholder + string(value[pos:]))
+	m.TextInput.SetCursor(pos + len([]rune(placeholder)))
+}
+
+// flattenPaste puts pasted text on one line the way the input box would, so
+// a recalled prompt matches what the input shows
+func flattenPaste(text string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(text)
+}
+
+// pastePlaceholder is the input text standing in for the n-th paste
+func pastePlaceholder(n, lines int) string {
+	return fmt.Sprintf("[Pasted text #%d, %d lines]", n, lines)
+}
+
+// expandPastes replaces the paste placeholders in input with the pasted text
+// and forgets the pastes.
+func (m *Model) expandPastes(input string) string {
+	for i, text := range m.pastes {
+		placeholder := pastePlaceholder(i+1, strings.Count(strings.TrimRight(text, "\n"), "\n")+1)
+		input = strings.Replace(input, placeholder, text, 1)
+	}
+	m.pastes = nil
+	return input
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paste sends text to m as a bracketed paste
+func paste(m Model, text string) (Model, tea.Cmd) {
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true})
+	return updated.(Model), cmd
+}
+
+func TestMultilinePasteDoesNotSubmit(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 30)
+	m.TextInput.SetValue("review ")
+	m.TextInput.CursorEnd()
+
+	m, cmd := paste(m, "func main() {\n\tfmt.Println(\"hi\")\n}\n")
+	if cmd != nil {
+		if _, ok := cmd().(startConversationMsg); ok {
+			t.Fatal("pasting text with newlines submitted the prompt")
+		}
+	}
+	if m.Loading || m.stream != nil {
+		t.Fatal("pasting text with newlines started a request")
+	}
+	if got, want := m.TextInput.Value(), "review [Pasted text #1, 3 lines]"; got != want {
+		t.Errorf("input = %q, want %q", got, want)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Loading {
+		t.Fatal("Enter after the paste did not submit the prompt")
+	}
+	if want := "review func main() {\n\tfmt.Println(\"hi\")\n}\n"; m.submittedInput != want {
+		t.Errorf("submitted %q, want the pasted text expanded: %q", m.submittedInput, want)
+	}
+	if m.pastes != nil {
+		t.Error("pastes were kept after submitting")
+	}
+}
+
+func TestCarriageReturnPasteIsNormalized(t *testing.T) {
+	m := InitialModel(testConfig(t))
+
+	m, _ = paste(m, "one\r\ntwo\rthree")
+	if got, want := m.TextInput.Value(), "[Pasted text #1, 3 lines]"; got != want {
+		t.Errorf("input = %q, want %q", got, want)
+	}
+	if got := m.expandPastes(m.TextInput.Value()); got != "one\ntwo\nthree" {
+		t.Errorf("expanded paste = %q, want normalized line breaks", got)
+	}
+}
+
+func TestSingleLinePasteIsTyped(t *testing.T) {
+	m := InitialModel(testConfig(t))
+
+	m, _ = paste(m, "just one line")
+	if m.Loading || len(m.pastes) != 0 {
+		t.Fatal("a single-line paste was kept aside or submitted")
+	}
+	if got := m.TextInput.Value(); got != "just one line" {
+		t.Errorf("input = %q, want the pasted line", got)
+	}
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxScrollbackTurns bounds how many restored turns are rendered on startup
+const maxScrollbackTurns = 20
+
+// RestoreScrollback renders the most recent turns of the loaded conversation
+// into the viewport, so a restarted session shows where it left off. Prompts
+// are quoted and the replies follow as regular text.
+func (m *Model) RestoreScrollback() {
+	scrollback := formatScrollback(m.ConversationHistory, maxScrollbackTurns)
+	if scrollback == "" {
+		return
+	}
+	m.resetOutput()
+	m.writeOutput(scrollback, false)
+	m.renderView()
+}
+
+// formatScrollback formats the last maxTurns turns of history as markdown
+func formatScrollback(conversationHistory []string, maxTurns int) string {
+	turns := len(conversationHistory) / 2
+	if turns == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	first := max(0, turns-maxTurns)
+	if first > 0 {
+		builder.WriteString(fmt.Sprintf("*%d earlier turns hidden, see /history*\n\n", first))
+	}
+	for turn := first; turn < turns; turn++ {
+		prompt := strings.TrimSpace(conversationHistory[turn*2])
+		reply := strings.TrimSpace(conversationHistory[turn*2+1])
+		builder.WriteString("> **You:** " + strings.ReplaceAll(prompt, "\n", "\n> ") + "\n\n")
+		builder.WriteString(reply + "\n\n")
+		if turn < turns-1 {
+			builder.WriteString("---\n\n")
+		}
+	}
+	builder.WriteString("---\n\n*Previous session restored.*")
+	return builder.String()
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRestoreScrollback(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 40)
+	m.ConversationHistory = []string{"list the files", "There are **3** files.", "and the tests?", "All tests pass."}
+
+	m.RestoreScrollback()
+
+	content := stripANSI(m.Viewport.View())
+	if strings.TrimSpace(content) == "" {
+		t.Fatal("restored history left the viewport empty")
+	}
+	for _, want := range []string{"You: list the files", "There are 3 files.", "You: and the tests?", "All tests pass.", "Previous session restored."} {
+		if !strings.Contains(content, want) {
+			t.Errorf("viewport is missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestRestoreEmptyScrollback(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 40)
+	m.RestoreScrollback()
+	if m.currentResponse.Len() != 0 {
+		t.Errorf("empty history rendered %q", m.currentResponse.String())
+	}
+}
+
+func TestFormatScrollback(t *testing.T) {
+	out := formatScrollback([]string{"first\nprompt", "reply one", "second", "reply two"}, 10)
+	if want := "> **You:** first\n> prompt\n\nreply one\n\n---\n\n> **You:** second\n\nreply two\n\n---\n\n*Previous session restored.*"; out != want {
+		t.Errorf("scrollback = %q, want %q", out, want)
+	}
+
+	var history []string
+	for i := 0; i < 5; i++ {
+		history = append(history, fmt.Sprintf("prompt %d", i), fmt.Sprintf("reply %d", i))
+	}
+	out = formatScrollback(history, 2)
+	if !strings.HasPrefix(out, "*3 earlier turns hidden, see /history*") || strings.Contains(out, "prompt 2") || !strings.Contains(out, "prompt 4") {
+		t.Errorf("bounded scrollback = %q", out)
+	}
+	if formatScrollback([]string{"unanswered"}, 10) != "" {
+		t.Error("a lone prompt produced scrollback")
+	}
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"strings"
+
+	"console-ai/pkg/history"
+)
+
+// interruptedNote marks a turn that was cut short by quitting mid-request.
+const interruptedNote = "[Interrupted before the reply was complete]"
+
+// Shutdown stops any in-flight request and saves the session, including the
+// turn in progress with whatever output it produced so far. It is called
+// once the program has exited, however it was stopped.
+func (m *Model) Shutdown() error {
+	if m.stream != nil {
+		m.stream.stop()
+	}
+
+	if m.Loading {
+		input := strings.TrimSpace(m.submittedInput)
+		if input != "" {
+			// Only the answer text is kept; the tool steps aren't part of the reply
+			reply := interruptedNote
+			if partial := strings.TrimSpace(m.answer.String()); partial != "" {
+				reply = partial + "\n\n" + interruptedNote
+			}
+			m.ToolActions = append(history.AlignToolActions(m.ToolActions, m.ConversationHistory), nil)
+			m.ConversationHistory = append(m.ConversationHistory, input, reply)
+			m.ConversationHistory = history.PruneHistory(m.ConversationHistory, m.Config.MaxHistoryTurns)
+			m.ToolActions = history.AlignToolActions(m.ToolActions, m.ConversationHistory)
+		}
+		m.Loading = false
+	}
+
+	return history.SaveSession(m.Config.ConversationHistory, m.ConversationHistory, m.ToolActions, m.ProjectInfo, m.Config.HumorLevel, m.Config.MaxHistoryTurns)
+}
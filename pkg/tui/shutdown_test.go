@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/gemini"
+	"console-ai/pkg/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestShutdownSavesInterruptedTurn(t *testing.T) {
+	cfg := testConfig(t)
+	m := InitialModel(cfg)
+	m.ConversationHistory = []string{"earlier", "answer"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stream = &conversationStream{ch: make(chan tea.Msg), cancel: cancel}
+	m.Loading = true
+	m.submittedInput = "refactor the parser"
+	m = stream(m,
+		StreamMsg{Title: gemini.StepToolCall, Content: `read_file with args: {"path":"parser.go"}`},
+		StreamMsg{Title: gemini.StepToolOutput, Content: "package parser"},
+		StreamMsg{Title: gemini.StepResponse, Content: "Started on the parser"},
+	)
+
+	if err := m.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("the in-flight request was not cancelled")
+	}
+
+	data, err := history.LoadSession(cfg.ConversationHistory)
+	if err != nil || data == nil {
+		t.Fatalf("LoadSession = %v, %v", data, err)
+	}
+	if len(data.Conversations) != 4 || data.Conversations[2] != "refactor the parser" {
+		t.Fatalf("saved conversation = %q", data.Conversations)
+	}
+	if reply := data.Conversations[3]; !strings.HasPrefix(reply, "Started on the parser") || !strings.HasSuffix(reply, interruptedNote) {
+		t.Errorf("saved reply = %q, want the partial output and a note", reply)
+	}
+	if reply := data.Conversations[3]; strings.Contains(reply, "read_file") || strings.Contains(reply, "package parser") {
+		t.Errorf("saved reply = %q, want the tool steps left out", reply)
+	}
+	if len(data.ToolActions) != 2 {
+		t.Errorf("tool actions = %v, want one entry per turn", data.ToolActions)
+	}
+}
+
+func TestShutdownWhenIdle(t *testing.T) {
+	cfg := testConfig(t)
+	m := InitialModel(cfg)
+	m.ConversationHistory = []string{"question", "answer"}
+
+	if err := m.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	data, err := history.LoadSession(cfg.ConversationHistory)
+	if err != nil || data == nil {
+		t.Fatalf("LoadSession = %v, %v", data, err)
+	}
+	if !reflect.DeepEqual(data.Conversations, m.ConversationHistory) {
+		t.Errorf("saved conversation = %q, want %q", data.Conversations, m.ConversationHistory)
+	}
+}
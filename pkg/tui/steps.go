@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"console-ai/pkg/gemini"
+)
+
+// minStepLogHeight is the smallest height of the step log panel
+const minStepLogHeight = 3
+
+// isStepMsg reports whether a stream message belongs in the step log rather
+// than the answer
+func isStepMsg(title string) bool {
+	return title != gemini.StepResponse && title != gemini.StepThinking
+}
+
+// writeOutput appends text to the output of the current request. Step text
+// also goes to the step log, everything else to the answer.
+func (m *Model) writeOutput(text string, step bool) {
+	m.currentResponse.WriteString(text)
+	if step {
+		m.steps.WriteString(text)
+	} else {
+		m.answer.WriteString(text)
+	}
+}
+
+// replaceAnswer swaps the streamed answer of the current request for the
+// final reply after response transformers changed it. The steps are kept
+// and the reply follows them.
+func (m *Model) replaceAnswer(reply string) {
+	steps := m.steps.String()
+	m.resetOutput()
+	m.writeOutput(steps, true)
+	m.writeOutput(reply, false)
+	m.renderView()
+}
+
+// resetOutput clears the output of the previous request
+func (m *Model) resetOutput() {
+	m.currentResponse.Reset()
+	m.answer.Reset()
+	m.steps.Reset()
+	m.lastRendered = ""
+	m.lastRenderedSteps = ""
+}
+
+// toggleSteps shows or hides the step log panel. While it is shown the main
+// viewport only holds the answer, so long tool runs don't push it off-screen.
+func (m *Model) toggleSteps() {
+	m.showSteps = !m.showSteps
+	m.updateSizes()
+	m.renderView()
+}
+
+// splitHeight divides the height available for output between the answer
+// viewport and, when shown, the step log panel including its border.
+func (m *Model) splitHeight(available int) (answerHeight, stepsHeight int) {
+	if !m.showSteps {
+		return available, 0
+	}
+	stepsHeight = available / 3
+	if stepsHeight < minStepLogHeight {
+		stepsHeight = minStepLogHeight
+	}
+	return available - stepsHeight - 2, stepsHeight
+}
+
+// renderSteps updates the step log panel with the latest steps.
+func (m *Model) renderSteps() {
+	steps := m.steps.String()
+	// A panel that was just opened is always filled in
+	if steps == m.lastRenderedSteps && m.renderedSplit {
+		return
+	}
+	content := steps
+	if content == "" {
+		content = "No tool steps yet."
+	}
+	m.StepLog.SetContent(m.renderMarkdown(content, m.StepLog.Width-4))
+	m.lastRenderedSteps = steps
+	m.StepLog.GotoBottom()
+}
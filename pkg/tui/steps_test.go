@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"console-ai/pkg/gemini"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleStepsKey is the key that shows and hides the step log
+var toggleStepsKey = tea.KeyMsg{Type: tea.KeyCtrlT}
+
+func TestStepLogToggle(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 40)
+	fullHeight := m.Viewport.Height
+
+	m = press(m, toggleStepsKey)
+	if !m.showSteps {
+		t.Fatal("ctrl+t did not show the step log")
+	}
+	if m.StepLog.Height < minStepLogHeight {
+		t.Errorf("step log height = %d, want at least %d", m.StepLog.Height, minStepLogHeight)
+	}
+	if want := fullHeight - m.StepLog.Height - 2; m.Viewport.Height != want {
+		t.Errorf("answer height = %d, want %d (full %d minus the panel and its border)", m.Viewport.Height, want, fullHeight)
+	}
+	if m.StepLog.Width != m.Viewport.Width {
+		t.Errorf("step log width = %d, want the viewport width %d", m.StepLog.Width, m.Viewport.Width)
+	}
+
+	m = press(m, toggleStepsKey)
+	if m.showSteps || m.StepLog.Height != 0 || m.Viewport.Height != fullHeight {
+		t.Errorf("after hiding: shown %v, step log height %d, answer height %d; want false, 0, %d", m.showSteps, m.StepLog.Height, m.Viewport.Height, fullHeight)
+	}
+}
+
+func TestStepLogKeepsAnswerSeparate(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 40)
+	m = stream(m,
+		StreamMsg{Title: gemini.StepToolCall, Content: `run_tests with args: {}`},
+		StreamMsg{Title: gemini.StepToolOutput, Content: "ok  console-ai/pkg/tui"},
+		StreamMsg{Title: gemini.StepResponse, Content: "All tests pass."},
+	)
+
+	if got := m.answer.String(); got != "All tests pass." {
+		t.Errorf("answer = %q, want only the response", got)
+	}
+	if steps := m.steps.String(); !strings.Contains(steps, "run_tests") || strings.Contains(steps, "All tests pass.") {
+		t.Errorf("steps = %q, want only the tool call and output", steps)
+	}
+
+	m = press(m, toggleStepsKey)
+	if m.lastRendered != "All tests pass." {
+		t.Errorf("viewport shows %q with the step log open, want only the answer", m.lastRendered)
+	}
+	if view := m.StepLog.View(); !strings.Contains(view, "ok  console-ai/pkg/tui") {
+		t.Errorf("step log panel does not show the steps:\n%s", view)
+	}
+}
+
+func TestStepLogOnSmallTerminal(t *testing.T) {
+	m := press(resize(InitialModel(testConfig(t)), 40, 12), toggleStepsKey)
+	if m.StepLog.Height != minStepLogHeight || m.Viewport.Height < 5 {
+		t.Errorf("step log %d and answer %d high, want %d and at least 5", m.StepLog.Height, m.Viewport.Height, minStepLogHeight)
+	}
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"console-ai/pkg/gemini"
+)
+
+const (
+	// maxToolOutputLines is the number of tool output lines shown before the rest is collapsed.
+	maxToolOutputLines = 5
+
+	// maxToolArgsChars truncates long tool arguments in the transcript.
+	maxToolArgsChars = 200
+)
+
+// formatStreamMsg renders a conversation step as a markdown section of the
+// transcript. Response text is passed through unchanged.
+func formatStreamMsg(msg StreamMsg) string {
+	switch msg.Title {
+	case gemini.StepThinking:
+		return ""
+	case gemini.StepResponse:
+		return msg.Content
+	case gemini.StepToolCall:
+		return fmt.Sprintf("\n\n> ⚙ Tool: %s\n\n", truncateText(msg.Content, maxToolArgsChars))
+	case gemini.StepToolOutput:
+		return formatToolOutput(msg.Content)
+	case gemini.StepToolError:
+		return formatToolError(msg.Content)
+	case gemini.StepLimitReached:
+		return fmt.Sprintf("\n\n> ⚠ %s\n", msg.Content)
+	default:
+		if msg.Content == "" {
+			return ""
+		}
+		return fmt.Sprintf("\n\n> %s: %s\n\n", msg.Title, msg.Content)
+	}
+}
+
+// toolErrorLabels are the icon and label shown for each kind of tool error
+var toolErrorLabels = map[gemini.ToolErrorKind]string{
+	gemini.ToolErrorExecution:  "✗ Tool failed",
+	gemini.ToolErrorValidation: "⚠ Invalid tool call",
+	gemini.ToolErrorNotFound:   "? Not found",
+	gemini.ToolErrorPermission: "⛔ Permission denied",
+	gemini.ToolErrorBlocked:    "⛔ Blocked",
+	gemini.ToolErrorTimeout:    "⏱ Timed out",
+}
+
+// formatToolError renders a tool error with a label for its kind, so a
+// rejected call can be told from a failing one at a glance.
+func formatToolError(content string) string {
+	kind, message := gemini.ParseToolErrorStep(content)
+	return fmt.Sprintf("> %s: %s\n\n", toolErrorLabels[kind], message)
+}
+
+// formatToolOutput renders tool output as a code block collapsed to its first lines.
+func formatToolOutput(output string) string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return ""
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) > maxToolOutputLines {
+		hidden := len(lines) - maxToolOutputLines
+		lines = append(lines[:maxToolOutputLines], fmt.Sprintf("… (%d more lines)", hidden))
+	}
+
+	// Don't let fences inside the output close the block early
+	fence := "```"
+	if strings.Contains(output, fence) {
+		fence = "~~~~"
+	}
+	return fmt.Sprintf("%s\n%s\n%s\n\n", fence, strings.Join(lines, "\n"), fence)
+}
+
+// truncateText shortens text to at most limit bytes without splitting a UTF-8 character.
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return strings.ToValidUTF8(text[:limit], "") + "..."
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"console-ai/pkg/gemini"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stream sends conversation steps to m as if they came from a request
+func stream(m Model, msgs ...StreamMsg) Model {
+	if m.stream == nil {
+		m.stream = &conversationStream{ch: make(chan tea.Msg)}
+		m.Loading = true
+	}
+	for _, msg := range msgs {
+		updated, _ := m.Update(streamedMsg{id: m.stream.id, msg: msg})
+		m = updated.(Model)
+	}
+	return m
+}
+
+func TestToolCallRendersWithTitle(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 40)
+	var output []string
+	for i := 1; i <= 8; i++ {
+		output = append(output, fmt.Sprintf("file%d.go", i))
+	}
+
+	m = stream(m,
+		StreamMsg{Title: gemini.StepThinking},
+		StreamMsg{Title: gemini.StepToolCall, Content: `list_files with args: {"path":"."}`},
+		StreamMsg{Title: gemini.StepToolOutput, Content: strings.Join(output, "\n")},
+		StreamMsg{Title: gemini.StepResponse, Content: "There are eight files."},
+	)
+
+	out := m.currentResponse.String()
+	if !strings.Contains(out, `> ⚙ Tool: list_files with args: {"path":"."}`) {
+		t.Errorf("tool call has no title label:\n%s", out)
+	}
+	if !strings.Contains(out, "file5.go") || strings.Contains(out, "file6.go") || !strings.Contains(out, "… (3 more lines)") {
+		t.Errorf("tool output is not collapsed:\n%s", out)
+	}
+	if view := stripANSI(m.View()); !strings.Contains(view, "⚙ Tool: list_files") {
+		t.Errorf("view does not show the tool call:\n%s", view)
+	}
+	if answer := m.answer.String(); answer != "There are eight files." {
+		t.Errorf("answer = %q, want only the reply text", answer)
+	}
+}
+
+func TestToolOutputFences(t *testing.T) {
+	if got := formatToolOutput("```go\nx\n```"); !strings.HasPrefix(got, "~~~~\n") {
+		t.Errorf("output containing a fence = %q, want a ~~~~ block", got)
+	}
+	if got := formatToolOutput("\n"); got != "" {
+		t.Errorf("empty output = %q, want nothing", got)
+	}
+}
+
+func TestTransformedReplyReplacesStreamedAnswer(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 100, 40)
+
+	m = stream(m,
+		StreamMsg{Title: gemini.StepToolCall, Content: `list_files with args: {"path":"."}`},
+		StreamMsg{Title: gemini.StepResponse, Content: "All done 🎉"},
+		StreamMsg{Title: gemini.StepTransformed, Content: "All done "},
+	)
+
+	if got := m.answer.String(); got != "All done " {
+		t.Errorf("answer = %q, want the transformed reply", got)
+	}
+	out := m.currentResponse.String()
+	if strings.Contains(out, "🎉") || strings.Contains(out, gemini.StepTransformed) {
+		t.Errorf("output still shows the streamed reply:\n%s", out)
+	}
+	if !strings.Contains(out, "Tool: list_files") || !strings.HasSuffix(out, "All done ") {
+		t.Errorf("output lost the steps or the reply:\n%s", out)
+	}
+}
+
+func TestToolErrorKindsRenderDifferently(t *testing.T) {
+	seen := make(map[string]gemini.ToolErrorKind)
+	for kind, label := range toolErrorLabels {
+		got := formatToolError(kind.String() + ": it broke")
+		if want := "> " + label + ": it broke\n\n"; got != want {
+			t.Errorf("%v error renders as %q, want %q", kind, got, want)
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("%v and %v errors render the same: %q", kind, other, got)
+		}
+		seen[got] = kind
+	}
+	if len(toolErrorLabels) != int(gemini.ToolErrorTimeout)+1 {
+		t.Errorf("%d labels, want one per tool error kind", len(toolErrorLabels))
+	}
+}
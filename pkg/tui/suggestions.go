@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// updateSuggestions refreshes the autocomplete list for the current input.
+// Suggestions are shown while a command name is being typed.
+func (m *Model) updateSuggestions() {
+	previous := len(m.suggestions)
+
+	m.suggestions = nil
+	value := m.TextInput.Value()
+	if strings.HasPrefix(value, "/") && !strings.ContainsAny(value, " \t") {
+		m.suggestions = filterCommands(value)
+	}
+	if m.suggestionIndex >= len(m.suggestions) {
+		m.suggestionIndex = 0
+	}
+
+	// The list takes space from the viewport
+	if len(m.suggestions) != previous {
+		m.updateSizes()
+	}
+}
+
+// moveSuggestion moves the highlighted suggestion by step, wrapping around.
+func (m *Model) moveSuggestion(step int) {
+	n := len(m.suggestions)
+	m.suggestionIndex = (m.suggestionIndex + step + n) % n
+}
+
+// completeSuggestion replaces the input with the highlighted command.
+func (m *Model) completeSuggestion() {
+	m.TextInput.SetValue(m.suggestions[m.suggestionIndex].name + " ")
+	m.TextInput.CursorEnd()
+	m.updateSuggestions()
+}
+
+// suggestionsView renders the autocomplete list, one command per line.
+func (m Model) suggestionsView() string {
+	if len(m.suggestions) == 0 {
+		return ""
+	}
+
+	highlight := lipgloss.NewStyle().Foreground(m.Theme.Accent).Bold(true)
+	muted := lipgloss.NewStyle().Foreground(m.Theme.Muted)
+
+	var lines []string
+	for i, command := range m.suggestions {
+		line := fmt.Sprintf("  %-16s %s", command.usage, command.description)
+		if i == m.suggestionIndex {
+			line = highlight.Render("> " + line[2:])
+		} else {
+			line = muted.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
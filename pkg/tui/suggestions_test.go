@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func commandNames(commands []slashCommand) []string {
+	var names []string
+	for _, command := range commands {
+		names = append(names, command.name)
+	}
+	return names
+}
+
+func TestFilterCommands(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"/s", []string{"/save", "/search", "/sessions"}},
+		{"/SE", []string{"/search", "/sessions"}},
+		{"/clear", []string{"/clear"}},
+		{"/x", nil},
+	}
+	for _, tt := range tests {
+		if got := commandNames(filterCommands(tt.prefix)); !slices.Equal(got, tt.want) {
+			t.Errorf("filterCommands(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+	if got := filterCommands("/"); len(got) != maxSuggestions {
+		t.Errorf("filterCommands(\"/\") returned %d commands, want %d", len(got), maxSuggestions)
+	}
+}
+
+// typeText sends text to m one rune at a time
+func typeText(m Model, text string) Model {
+	for _, r := range text {
+		m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestTabCompletesHighlightedCommand(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 80, 30)
+	height := m.Viewport.Height
+
+	m = typeText(m, "/se")
+	if got := commandNames(m.suggestions); len(got) != 2 {
+		t.Fatalf("suggestions = %v, want /search and /sessions", got)
+	}
+	if m.Viewport.Height != height-2 {
+		t.Errorf("viewport height = %d with 2 suggestions, want %d", m.Viewport.Height, height-2)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.TextInput.Value(); got != "/sessions " {
+		t.Errorf("input after tab = %q, want \"/sessions \"", got)
+	}
+	if len(m.suggestions) != 0 || m.Viewport.Height != height {
+		t.Errorf("suggestions still shown after completion: %v", commandNames(m.suggestions))
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"strings"
+
+	"console-ai/pkg/logger"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the named colors used to render the TUI.
+type Theme struct {
+	Name             string
+	HeaderForeground lipgloss.Color
+	HeaderBackground lipgloss.Color
+	StatusForeground lipgloss.Color
+	StatusBackground lipgloss.Color
+	Border           lipgloss.Color
+	Accent           lipgloss.Color // loading animation
+	Muted            lipgloss.Color // help descriptions
+	Warning          lipgloss.Color // token budget past the warning threshold
+	Danger           lipgloss.Color // token budget near the context limit
+}
+
+// DefaultThemeName is used when no theme or an unknown theme is configured.
+const DefaultThemeName = "dark"
+
+// themes are the built-in color themes by name.
+var themes = map[string]Theme{
+	"dark": {
+		Name:             "dark",
+		HeaderForeground: lipgloss.Color("#FAFAFA"),
+		HeaderBackground: lipgloss.Color("#7D56F4"),
+		StatusForeground: lipgloss.Color("#FFF"),
+		StatusBackground: lipgloss.Color("#5C5C5C"),
+		Border:           lipgloss.Color("62"),
+		Accent:           lipgloss.Color("205"),
+		Muted:            lipgloss.Color("#626262"),
+		Warning:          lipgloss.Color("#FFB300"),
+		Danger:           lipgloss.Color("#FF5252"),
+	},
+	"light": {
+		Name:             "light",
+		HeaderForeground: lipgloss.Color("#FFFFFF"),
+		HeaderBackground: lipgloss.Color("#5A3FC0"),
+		StatusForeground: lipgloss.Color("#1A1A1A"),
+		StatusBackground: lipgloss.Color("#D0D0D0"),
+		Border:           lipgloss.Color("#5A3FC0"),
+		Accent:           lipgloss.Color("#C2185B"),
+		Muted:            lipgloss.Color("#6E6E6E"),
+		Warning:          lipgloss.Color("#8A5A00"),
+		Danger:           lipgloss.Color("#B00020"),
+	},
+}
+
+// ThemeByName returns the built-in theme with the given name, falling back to
+// the default theme when it is unknown.
+func ThemeByName(name string) Theme {
+	if theme, ok := themes[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return theme
+	}
+	if name != "" {
+		logger.Warn("Unknown theme %q, using %q", name, DefaultThemeName)
+	}
+	return themes[DefaultThemeName]
+}
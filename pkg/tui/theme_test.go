@@ -0,0 +1,38 @@
+package tui
+
+import "testing"
+
+func TestThemeByName(t *testing.T) {
+	if got := ThemeByName("Light ").Name; got != "light" {
+		t.Errorf("ThemeByName(\"Light \") = %s, want light", got)
+	}
+	if got := ThemeByName("solarized").Name; got != DefaultThemeName {
+		t.Errorf("unknown theme = %s, want %s", got, DefaultThemeName)
+	}
+	if got := ThemeByName("").Name; got != DefaultThemeName {
+		t.Errorf("empty theme = %s, want %s", got, DefaultThemeName)
+	}
+}
+
+func TestSwitchingThemeChangesStyles(t *testing.T) {
+	darkCfg := testConfig(t)
+	darkCfg.UI.Theme = "dark"
+	dark := InitialModel(darkCfg)
+
+	lightCfg := testConfig(t)
+	lightCfg.UI.Theme = "light"
+	light := InitialModel(lightCfg)
+
+	if dark.Theme.StatusBackground == light.Theme.StatusBackground {
+		t.Error("dark and light themes share the status bar background")
+	}
+	if got, want := light.Viewport.Style.GetBorderTopForeground(), themes["light"].Border; got != want {
+		t.Errorf("light viewport border = %v, want %v", got, want)
+	}
+	if dark.Viewport.Style.GetBorderTopForeground() == light.Viewport.Style.GetBorderTopForeground() {
+		t.Error("switching themes kept the viewport border color")
+	}
+	if dark.Help.Styles.ShortDesc.GetForeground() == light.Help.Styles.ShortDesc.GetForeground() {
+		t.Error("switching themes kept the help colors")
+	}
+}
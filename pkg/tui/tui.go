@@ -1,45 +1,84 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"console-ai/pkg/agent"
+	"console-ai/pkg/cat"
 	"console-ai/pkg/config"
 	"console-ai/pkg/gemini"
 	"console-ai/pkg/history"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/google/generative-ai-go/genai"
 )
 
 type (
 	ErrMsg               error
-	SuccessMsg           string
+	SuccessMsg           struct {
+		Reply   string
+		Actions []history.ToolAction
+	}
 	StreamMsg            struct{ Title, Content string }
 	startConversationMsg struct{ input string }
 	finalMsg             struct{}
+
+	// streamedMsg carries a message from the conversation stream with the
+	// given id, so messages from an earlier stream can be told apart.
+	streamedMsg struct {
+		id  int
+		msg tea.Msg
+	}
 )
 
 // Model represents the state of the TUI application.
 type Model struct {
 	Viewport            viewport.Model
+	StepLog             viewport.Model // tool steps, shown when showSteps is set
 	TextInput           textinput.Model
-	Spinner             spinner.Model
+	Cat                 cat.Cat
+	animating           bool
 	Loading             bool
 	Gemini              *genai.GenerativeModel
+	GeminiClient        *genai.Client // owns Gemini, closed when the model is switched
 	ConversationHistory []string
+	ToolActions         [][]history.ToolAction // tool calls made in each turn of ConversationHistory
+	InputHistory        []string
+	inputIndex          int
+	pastes              []string // multi-line pastes standing in the input as placeholders
+	submittedInput      string   // the prompt of the current request, with pastes expanded
 	ProjectInfo         *agent.ProjectInfo
+	analyzing           bool                  // the startup project analysis is running
+	analysisTimeout     time.Duration         // time limit of the startup project analysis
+	pendingStart        *startConversationMsg // prompt submitted before the analysis finished
 	stream              *conversationStream
-	currentResponse     *strings.Builder
+	streamCount         int // streams started so far, the last one's id
+	currentResponse     *strings.Builder // answer and steps of the current request, in order
+	answer              *strings.Builder // the current request without its steps
+	steps               *strings.Builder // the steps of the current request
+	showSteps           bool
 	lastRendered        string
+	lastRenderedSteps   string
+	renderedSplit       bool // whether the viewport last rendered the answer alone
+	statusMessage       string
+	confirmClear        bool
+	suggestions         []slashCommand
+	suggestionIndex     int
+	markdown            *glamour.TermRenderer
+	markdownWidth       int
 	Config              *config.Config
+	Theme               Theme
+	Version             string // build description shown in /help
 	Help                help.Model
 	Keys                *helpKeyMap
 	width               int
@@ -48,7 +87,10 @@ type Model struct {
 
 // conversationStream holds the channel for receiving messages from the Gemini API.
 type conversationStream struct {
-	ch chan tea.Msg
+	id        int
+	ch        chan tea.Msg
+	cancel    context.CancelFunc
+	cancelled bool
 }
 
 // InitialModel creates the initial state of the TUI.
@@ -58,28 +100,37 @@ func InitialModel(cfg *config.Config) Model {
 	ti.Focus()
 	ti.CharLimit = 0 // No limit
 
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	theme := ThemeByName(cfg.UI.Theme)
 
 	// Start with reasonable defaults, will be updated on first resize
 	vp := viewport.New(100, 20)
 	vp.Style = lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
+		BorderForeground(theme.Border).
+		Padding(0, 1)
+
+	stepLog := viewport.New(100, minStepLogHeight)
+	stepLog.Style = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Muted).
 		Padding(0, 1)
 
 	keys := newHelpKeyMap()
-	h := newHelp(keys)
+	h := newHelp(keys, theme)
 
 	return Model{
 		TextInput:       ti,
-		Spinner:         s,
+		Cat:             cat.New(),
 		Viewport:        vp,
+		StepLog:         stepLog,
 		currentResponse: &strings.Builder{},
+		answer:          &strings.Builder{},
+		steps:           &strings.Builder{},
 		Config:          cfg,
+		Theme:           theme,
 		Help:            h,
 		Keys:            keys,
+		inputIndex:      -1,
 		width:           100,
 		height:          24,
 	}
@@ -87,7 +138,19 @@ func InitialModel(cfg *config.Config) Model {
 
 // Init initializes the TUI.
 func (m Model) Init() tea.Cmd {
-	return m.Spinner.Tick
+	if m.analyzing {
+		return tea.Batch(textinput.Blink, m.analyzeProject())
+	}
+	return textinput.Blink
+}
+
+// startAnimation starts the cat animation unless it is already running.
+func (m *Model) startAnimation() tea.Cmd {
+	if m.animating {
+		return nil
+	}
+	m.animating = true
+	return cat.Animate()
 }
 
 // Update handles all incoming messages and updates the model accordingly.
@@ -99,15 +162,91 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.updateSizes()
+		// Re-wrap what is shown, e.g. the restored scrollback, to the new width
+		m.lastRendered = ""
+		m.lastRenderedSteps = ""
+		m.renderView()
 		return m, nil
 		
 	case tea.KeyMsg:
+		if msg.Paste && isMultilinePaste(string(msg.Runes)) {
+			m.insertPaste(string(msg.Runes))
+			m.updateSuggestions()
+			return m, nil
+		}
 		switch {
 		case key.Matches(msg, m.Keys.help):
 			m.Help.ShowAll = !m.Help.ShowAll
 			return m, nil
+		case key.Matches(msg, m.Keys.steps):
+			m.toggleSteps()
+			return m, nil
+		case m.Loading && key.Matches(msg, m.Keys.cancel):
+			return m, m.cancelRequest()
 		case key.Matches(msg, m.Keys.quit):
 			return m, tea.Quit
+		case len(m.suggestions) > 0 && key.Matches(msg, m.Keys.complete):
+			m.completeSuggestion()
+			return m, nil
+		case key.Matches(msg, m.Keys.focus):
+			if m.TextInput.Focused() {
+				m.TextInput.Blur()
+				return m, nil
+			}
+			return m, m.TextInput.Focus()
+		case key.Matches(msg, m.Keys.pageUp):
+			m.Viewport.PageUp()
+			return m, nil
+		case key.Matches(msg, m.Keys.pageDown):
+			m.Viewport.PageDown()
+			return m, nil
+		}
+
+		// Up/Down recall previous prompts while the input has focus
+		if m.TextInput.Focused() && !m.Loading && m.canRecallInput() {
+			switch {
+			case key.Matches(msg, m.Keys.up):
+				m.recallInput(-1)
+				m.updateSuggestions()
+				return m, nil
+			case key.Matches(msg, m.Keys.down):
+				m.recallInput(1)
+				m.updateSuggestions()
+				return m, nil
+			}
+		}
+
+		// Up/Down move through the autocomplete list while it is shown
+		if len(m.suggestions) > 0 {
+			switch {
+			case key.Matches(msg, m.Keys.up):
+				m.moveSuggestion(-1)
+				return m, nil
+			case key.Matches(msg, m.Keys.down):
+				m.moveSuggestion(1)
+				return m, nil
+			}
+		}
+
+		// Line and jump scrolling only apply while the output has focus,
+		// otherwise the keys belong to the input box
+		if !m.TextInput.Focused() {
+			switch {
+			case key.Matches(msg, m.Keys.up):
+				m.Viewport.ScrollUp(1)
+				return m, nil
+			case key.Matches(msg, m.Keys.down):
+				m.Viewport.ScrollDown(1)
+				return m, nil
+			case key.Matches(msg, m.Keys.home):
+				m.Viewport.GotoTop()
+				return m, nil
+			case key.Matches(msg, m.Keys.end):
+				m.Viewport.GotoBottom()
+				return m, nil
+			case key.Matches(msg, m.Keys.copy):
+				return m, m.copyLastResponse()
+			}
 		}
 
 		switch msg.Type {
@@ -115,35 +254,101 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.Loading {
 				return m, nil
 			}
-			m.Loading = true
-			m.currentResponse.Reset()
-			m.lastRendered = ""
-			return m, func() tea.Msg {
-				return startConversationMsg{input: m.TextInput.Value()}
+			input := m.expandPastes(m.TextInput.Value())
+			m.rememberInput(flattenPaste(input))
+			if isCommand(input) {
+				m.handleCommand(input)
+				m.TextInput.Reset()
+				m.updateSuggestions()
+				return m, nil
 			}
+			m.confirmClear = false
+			m.Loading = true
+			m.resetOutput()
+			m.submittedInput = input
+			return m, tea.Batch(m.startAnimation(), func() tea.Msg {
+				return startConversationMsg{input: input}
+			})
 		case tea.KeyCtrlC, tea.KeyEsc:
 			return m, tea.Quit
 		}
 
+	case streamedMsg:
+		// Drop messages still in flight from a cancelled or earlier request
+		if m.stream == nil || m.stream.cancelled || msg.id != m.stream.id {
+			return m, nil
+		}
+		return m.handleStreamMsg(msg.msg)
+
+	case projectAnalyzedMsg:
+		return m.handleProjectAnalyzed(msg)
+
 	case startConversationMsg:
-		m.stream = newConversationStream(m.Gemini, m.ConversationHistory, msg.input, m.Config.HumorLevel, m.Config)
+		// Wait for the project context so the first prompt can use it
+		if m.analyzing {
+			m.pendingStart = &msg
+			return m, nil
+		}
+		prompt, notes := gemini.ExpandAttachments(m.Config, msg.input)
+		for _, note := range notes {
+			m.writeOutput(fmt.Sprintf("> 📎 %s\n\n", note), true)
+		}
+		if len(notes) > 0 {
+			m.renderView()
+		}
+		m.streamCount++
+		m.stream = newConversationStream(m.streamCount, m.Gemini, m.ConversationHistory, m.ProjectInfo, prompt, m.Config.HumorLevel, m.Config)
 		return m, m.stream.waitForNextMsg()
 
+	case clearStatusMsg:
+		m.statusMessage = ""
+		return m, nil
+
+	case cat.Msg:
+		// The animation stops on the first tick after loading finishes
+		if !m.Loading {
+			m.animating = false
+			return m, nil
+		}
+		m.Cat.NextFrame()
+		return m, cat.Animate()
+	}
+
+	var cmd tea.Cmd
+	m.TextInput, cmd = m.TextInput.Update(msg)
+	cmds = append(cmds, cmd)
+	m.updateSuggestions()
+	m.Viewport, cmd = m.Viewport.Update(msg)
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+// handleStreamMsg handles the messages sent by the active conversation stream.
+func (m Model) handleStreamMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
 	case ErrMsg:
 		m.Loading = false
-		m.currentResponse.WriteString(fmt.Sprintf("\nError: %v", msg))
+		m.writeOutput(fmt.Sprintf("\nError: %v", msg), false)
 		m.renderView()
 		return m, nil
 
 	case SuccessMsg:
-		m.ConversationHistory = append(m.ConversationHistory, m.TextInput.Value(), string(msg))
+		m.ToolActions = append(history.AlignToolActions(m.ToolActions, m.ConversationHistory), msg.Actions)
+		m.ConversationHistory = append(m.ConversationHistory, m.submittedInput, msg.Reply)
+		m.ConversationHistory = history.PruneHistory(m.ConversationHistory, m.Config.MaxHistoryTurns)
+		m.ToolActions = history.AlignToolActions(m.ToolActions, m.ConversationHistory)
 		// Save session data with project context
-		history.SaveSession(m.Config.ConversationHistory, m.ConversationHistory, m.ProjectInfo, m.Config.HumorLevel)
+		history.SaveSession(m.Config.ConversationHistory, m.ConversationHistory, m.ToolActions, m.ProjectInfo, m.Config.HumorLevel, m.Config.MaxHistoryTurns)
 		m.TextInput.Reset()
 		return m, m.stream.waitForNextMsg()
 
 	case StreamMsg:
-		m.currentResponse.WriteString(msg.Content)
+		if msg.Title == gemini.StepTransformed {
+			m.replaceAnswer(msg.Content)
+			return m, m.stream.waitForNextMsg()
+		}
+		m.writeOutput(formatStreamMsg(msg), isStepMsg(msg.Title))
 		m.renderView()
 		return m, m.stream.waitForNextMsg()
 
@@ -151,29 +356,33 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Loading = false
 		m.TextInput.Focus()
 		return m, textinput.Blink
-
-	case spinner.TickMsg:
-		var cmd tea.Cmd
-		m.Spinner, cmd = m.Spinner.Update(msg)
-		cmds = append(cmds, cmd)
 	}
+	return m, nil
+}
 
-	var cmd tea.Cmd
-	m.TextInput, cmd = m.TextInput.Update(msg)
-	cmds = append(cmds, cmd)
-	m.Viewport, cmd = m.Viewport.Update(msg)
-	cmds = append(cmds, cmd)
-
-	return m, tea.Batch(cmds...)
+// cancelRequest stops the in-flight request, keeping the output streamed so far.
+func (m *Model) cancelRequest() tea.Cmd {
+	if m.stream != nil {
+		m.stream.stop()
+	}
+	m.pendingStart = nil
+	m.Loading = false
+	m.writeOutput("\n\n[Request cancelled]", false)
+	m.renderView()
+	return m.TextInput.Focus()
 }
 
 // updateSizes updates component sizes based on terminal dimensions
 func (m *Model) updateSizes() {
 	// Calculate available space
-	headerHeight := 1
+	headerHeight := 0
+	if m.Config.UI.ShowHeader {
+		headerHeight = 1
+	}
 	statusHeight := 1
 	helpHeight := 2
 	inputHeight := 1
+	suggestionsHeight := len(m.suggestions)
 	padding := 2
 	
 	// Update text input width
@@ -184,7 +393,7 @@ func (m *Model) updateSizes() {
 	m.TextInput.Width = inputWidth
 	
 	// Update viewport dimensions
-	viewportHeight := m.height - headerHeight - statusHeight - helpHeight - inputHeight - padding
+	viewportHeight, stepsHeight := m.splitHeight(m.height - headerHeight - statusHeight - helpHeight - inputHeight - suggestionsHeight - padding)
 	if viewportHeight < 5 {
 		viewportHeight = 5
 	}
@@ -196,42 +405,65 @@ func (m *Model) updateSizes() {
 	
 	m.Viewport.Width = viewportWidth
 	m.Viewport.Height = viewportHeight
+	m.StepLog.Width = viewportWidth
+	m.StepLog.Height = stepsHeight
 }
 
 // View renders the entire UI.
 func (m Model) View() string {
-	header := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
-		Background(lipgloss.Color("#7D56F4")).
-		Padding(0, 1).
-		Width(m.width-2).
-		Align(lipgloss.Center).
-		Render("Console Buddy")
+	header := ""
+	if m.Config.UI.ShowHeader {
+		header = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(m.Theme.HeaderForeground).
+			Background(m.Theme.HeaderBackground).
+			Padding(0, 1).
+			Width(m.width-2).
+			Align(lipgloss.Center).
+			Render(m.Config.UI.HeaderText) + "\n"
+	}
 
 	statusText := "Ready. (? for help)"
-	if m.Loading {
-		statusText = m.Spinner.View() + " AI is working..."
+	if m.Loading && m.pendingStart != nil {
+		statusText = lipgloss.NewStyle().Foreground(m.Theme.Accent).Render(m.Cat.View()) + " Waiting for the project analysis..."
+	} else if m.Loading {
+		statusText = lipgloss.NewStyle().Foreground(m.Theme.Accent).Render(m.Cat.View()) + " AI is working..."
+	} else if m.statusMessage != "" {
+		statusText = m.statusMessage
 	}
 
 	projectStatus := ""
-	if m.ProjectInfo != nil {
+	if m.analyzing {
+		projectStatus = " | Analyzing project..."
+	} else if m.ProjectInfo != nil {
 		projectStatus = fmt.Sprintf(" | %s", m.ProjectInfo.Language)
 		if m.ProjectInfo.Framework != "" && len(m.ProjectInfo.Framework) < 20 {
 			projectStatus += fmt.Sprintf(" (%s)", m.ProjectInfo.Framework)
 		}
 	}
 	
-	// Create status text and truncate if too long
-	statusFullText := fmt.Sprintf("%s | Model: %s%s", statusText, m.Config.ModelName, projectStatus)
-	if len(statusFullText) > m.width-4 {
-		// Truncate to fit
-		statusFullText = statusFullText[:m.width-7] + "..."
+	sessionStatus := ""
+	if m.Config.Session != "" {
+		sessionStatus = fmt.Sprintf(" | Session: %s", m.Config.Session)
 	}
 	
+	dryRunStatus := ""
+	if m.Config.Agent.DryRun {
+		dryRunStatus = " | [dry-run]"
+	}
+	if m.Config.Agent.NoTools {
+		dryRunStatus += " | [no tools]"
+	}
+	
+	// Create status text and truncate if too long
+	statusFullText := fmt.Sprintf("%s | Model: %s%s%s%s", statusText, m.Config.ModelName, dryRunStatus, sessionStatus, projectStatus)
+	// The token estimate is kept whole so its warning stays visible
+	budgetStatus := m.tokenBudgetView()
+	statusFullText = fitWidth(statusFullText, m.width-4-lipgloss.Width(budgetStatus)) + budgetStatus
+	
 	statusBar := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFF")).
-		Background(lipgloss.Color("#5C5C5C")).
+		Foreground(m.Theme.StatusForeground).
+		Background(m.Theme.StatusBackground).
 		Padding(0, 1).
 		Width(m.width-2).
 		Render(statusFullText)
@@ -243,91 +475,170 @@ func (m Model) View() string {
 		var truncatedLines []string
 		for _, line := range helpLines {
 			if len(line) > m.width-2 {
-				line = line[:m.width-5] + "..."
+				line = fitWidth(line, m.width-2)
 			}
 			truncatedLines = append(truncatedLines, line)
 		}
 		helpView = strings.Join(truncatedLines, "\n")
 	}
 
+	suggestions := m.suggestionsView()
+	if suggestions != "" {
+		suggestions += "\n"
+	}
+
+	stepLog := ""
+	if m.showSteps {
+		stepLog = m.StepLog.View() + "\n"
+	}
+
 	return fmt.Sprintf(
-		"%s\n%s\n%s\n%s\n%s",
+		"%s%s%s\n%s\n%s%s\n%s",
 		header,
+		stepLog,
 		m.Viewport.View(),
 		m.TextInput.View(),
+		suggestions,
 		statusBar,
 		helpView,
 	)
 }
 
-// renderView updates the viewport with the latest content.
+// fitWidth shortens text to at most width terminal cells, marking the cut with
+// "..." when there is room for it. Width is measured on screen, so styling
+// escapes count for nothing and are never cut in half, and wide runes count
+// double. Tiny or negative widths yield what fits.
+func fitWidth(text string, width int) string {
+	if lipgloss.Width(text) <= width {
+		return text
+	}
+	if width <= 0 {
+		return ""
+	}
+	if width <= 3 {
+		return ansi.Truncate(text, width, "")
+	}
+	return ansi.Truncate(text, width, "...")
+}
+
+// renderView updates the viewport with the latest content. With the step log
+// shown, the viewport holds the answer and the panel the steps.
 func (m *Model) renderView() {
 	newContent := m.currentResponse.String()
-	if newContent != m.lastRendered {
-		// Wrap long lines to prevent overflow
-		wrappedContent := m.wrapText(newContent, m.Viewport.Width-4)
-		m.Viewport.SetContent(wrappedContent)
+	if m.showSteps {
+		newContent = m.answer.String()
+		m.renderSteps()
+	}
+	if newContent != m.lastRendered || m.showSteps != m.renderedSplit {
+		m.Viewport.SetContent(m.renderMarkdown(newContent, m.Viewport.Width-4))
 		m.lastRendered = newContent
+		m.renderedSplit = m.showSteps
 		m.Viewport.GotoBottom()
 	}
 }
 
-// wrapText wraps text to fit within the specified width
+// wrapText wraps text to fit within the specified display width. Lines are
+// measured and split by rune display width, so multi-byte and wide characters
+// are never cut in half.
 func (m *Model) wrapText(text string, width int) string {
 	if width <= 0 {
 		width = 80 // fallback width
 	}
-	
+
 	lines := strings.Split(text, "\n")
 	var wrappedLines []string
-	
+
 	for _, line := range lines {
-		if len(line) <= width {
+		if lipgloss.Width(line) <= width {
+			wrappedLines = append(wrappedLines, line)
+			continue
+		}
+		// Break long lines into multiple lines
+		for lipgloss.Width(line) > width {
+			var head string
+			head, line = splitAtWidth(line, width)
+			wrappedLines = append(wrappedLines, head)
+			line = strings.TrimSpace(line)
+		}
+		if len(line) > 0 {
 			wrappedLines = append(wrappedLines, line)
-		} else {
-			// Break long lines into multiple lines
-			for len(line) > width {
-				// Try to break at word boundaries
-				breakPoint := width
-				if spaceIdx := strings.LastIndex(line[:width], " "); spaceIdx > width/2 {
-					breakPoint = spaceIdx
-				}
-				
-				wrappedLines = append(wrappedLines, line[:breakPoint])
-				line = strings.TrimSpace(line[breakPoint:])
-			}
-			if len(line) > 0 {
-				wrappedLines = append(wrappedLines, line)
-			}
 		}
 	}
-	
+
 	return strings.Join(wrappedLines, "\n")
 }
 
+// splitAtWidth splits line after as many runes as fit in width, preferring
+// the last space when it lies past half of the cut. At least one rune is
+// always taken so a character wider than width can't stall wrapping.
+func splitAtWidth(line string, width int) (string, string) {
+	runes := []rune(line)
+	used, cut, space := 0, 0, -1
+	for i, r := range runes {
+		w := lipgloss.Width(string(r))
+		if used+w > width {
+			break
+		}
+		used += w
+		cut = i + 1
+		if r == ' ' {
+			space = i
+		}
+	}
+	if cut == 0 {
+		cut = 1
+	}
+	// Try to break at word boundaries
+	if space > cut/2 {
+		cut = space
+	}
+	return string(runes[:cut]), string(runes[cut:])
+}
+
 // newConversationStream creates a new stream for handling the Gemini conversation.
-func newConversationStream(geminiModel *genai.GenerativeModel, history []string, input string, humorLevel int, cfg *config.Config) *conversationStream {
+func newConversationStream(id int, geminiModel *genai.GenerativeModel, history []string, projectInfo *agent.ProjectInfo, input string, humorLevel int, cfg *config.Config) *conversationStream {
+	ctx, cancel := context.WithCancel(context.Background())
 	ch := make(chan tea.Msg)
+
+	// Once cancelled nobody reads from ch anymore, so sends give up on ctx.Done
+	send := func(msg tea.Msg) {
+		select {
+		case ch <- msg:
+		case <-ctx.Done():
+		}
+	}
+
 	go func() {
 		defer close(ch)
-		reply, err := gemini.ContinueConversation(geminiModel, history, input, humorLevel, cfg, func(title, content string) {
-			ch <- StreamMsg{Title: title, Content: content}
+		defer cancel()
+		reply, actions, err := gemini.ContinueConversation(ctx, geminiModel, history, projectInfo, input, humorLevel, cfg, func(title, content string) {
+			send(StreamMsg{Title: title, Content: content})
 		})
 
 		if err != nil {
-			ch <- ErrMsg(err)
+			send(ErrMsg(err))
 			return
 		}
 
-		ch <- SuccessMsg(reply)
-		ch <- finalMsg{}
+		send(SuccessMsg{Reply: reply, Actions: actions})
+		send(finalMsg{})
 	}()
-	return &conversationStream{ch: ch}
+	return &conversationStream{id: id, ch: ch, cancel: cancel}
+}
+
+// stop cancels the request behind the stream.
+func (s *conversationStream) stop() {
+	s.cancelled = true
+	s.cancel()
 }
 
 // waitForNextMsg waits for the next message from the conversation stream.
 func (s *conversationStream) waitForNextMsg() tea.Cmd {
 	return func() tea.Msg {
-		return <-s.ch
+		msg, ok := <-s.ch
+		if !ok {
+			return nil
+		}
+		return streamedMsg{id: s.id, msg: msg}
 	}
 }
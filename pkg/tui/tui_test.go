@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"console-ai/pkg/cat"
+	"console-ai/pkg/config"
+	"console-ai/pkg/gemini"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testConfig returns the default configuration without an API key, keeping
+// the session in a temporary history file
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig("")
+	if err != nil && !errors.Is(err, config.ErrMissingAPIKey) {
+		t.Fatal(err)
+	}
+	cfg.ConversationHistory = filepath.Join(t.TempDir(), "CB.hist")
+	return cfg
+}
+
+// press sends a key message to m
+func press(m Model, msg tea.KeyMsg) Model {
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
+
+// resize sends a window size message to m
+func resize(m Model, width, height int) Model {
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return updated.(Model)
+}
+
+func TestHeaderText(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.UI.HeaderText = "Acme Dev Assistant"
+	m := resize(InitialModel(cfg), 80, 30)
+
+	if view := m.View(); !strings.Contains(view, "Acme Dev Assistant") {
+		t.Errorf("view does not contain the configured header:\n%s", view)
+	}
+}
+
+func TestHiddenHeaderReclaimsHeight(t *testing.T) {
+	cfg := testConfig(t)
+	shown := resize(InitialModel(cfg), 80, 30)
+
+	hiddenCfg := testConfig(t)
+	hiddenCfg.UI.ShowHeader = false
+	hidden := resize(InitialModel(hiddenCfg), 80, 30)
+
+	if hidden.Viewport.Height != shown.Viewport.Height+1 {
+		t.Errorf("viewport height = %d without header, %d with it; want one more line", hidden.Viewport.Height, shown.Viewport.Height)
+	}
+	if view := hidden.View(); strings.Contains(view, cfg.UI.HeaderText) {
+		t.Errorf("hidden header is rendered:\n%s", view)
+	}
+}
+
+func TestCatFrameAdvancesWhileLoading(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	m.Loading = true
+	m.animating = true
+
+	for i := 1; i <= 2; i++ {
+		updated, cmd := m.Update(cat.Msg{})
+		m = updated.(Model)
+		if m.Cat.Index != i {
+			t.Errorf("frame after %d ticks = %d, want %d", i, m.Cat.Index, i)
+		}
+		if cmd == nil {
+			t.Error("animation stopped while loading")
+		}
+	}
+	if view := m.View(); !strings.Contains(view, m.Cat.View()) {
+		t.Error("status bar does not show the current cat frame")
+	}
+
+	m.Loading = false
+	updated, cmd := m.Update(cat.Msg{})
+	m = updated.(Model)
+	if cmd != nil || m.animating || m.Cat.Index != 2 {
+		t.Errorf("animation kept running after loading finished (frame %d)", m.Cat.Index)
+	}
+}
+
+func TestPageDownScrollsViewport(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 80, 30)
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	m.Viewport.SetContent(strings.Join(lines, "\n"))
+	m.Viewport.GotoTop()
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.Viewport.YOffset != m.Viewport.Height {
+		t.Errorf("offset after pgdn = %d, want one page (%d)", m.Viewport.YOffset, m.Viewport.Height)
+	}
+
+	// Line scrolling needs the output focused, otherwise the keys go to the input
+	m.TextInput.Blur()
+	offset := m.Viewport.YOffset
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	if m.Viewport.YOffset != offset+1 {
+		t.Errorf("offset after down = %d, want %d", m.Viewport.YOffset, offset+1)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyHome})
+	if m.Viewport.YOffset != 0 {
+		t.Errorf("offset after home = %d, want 0", m.Viewport.YOffset)
+	}
+}
+
+func TestEscCancelsRequest(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 80, 30)
+
+	// A request that only ends once it is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan tea.Msg)
+	go func() {
+		defer close(ch)
+		<-ctx.Done()
+	}()
+	m.stream = &conversationStream{ch: ch, cancel: cancel}
+	m.Loading = true
+
+	updated, _ := m.Update(streamedMsg{msg: StreamMsg{Title: gemini.StepResponse, Content: "partial answer"}})
+	m = updated.(Model)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("esc while loading quit or did nothing")
+	}
+	if m.Loading {
+		t.Error("Loading still set after cancelling")
+	}
+	if out := m.currentResponse.String(); !strings.Contains(out, "partial answer") || !strings.Contains(out, "[Request cancelled]") {
+		t.Errorf("output = %q, want the partial answer and a cancel note", out)
+	}
+
+	done := make(chan tea.Msg)
+	go func() { done <- m.stream.waitForNextMsg()() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForNextMsg still blocked after cancelling")
+	}
+
+	// Messages still in flight from the cancelled request are dropped
+	updated, _ = m.Update(streamedMsg{msg: StreamMsg{Title: gemini.StepResponse, Content: "late chunk"}})
+	m = updated.(Model)
+	if strings.Contains(m.currentResponse.String(), "late chunk") {
+		t.Error("a message from the cancelled request was shown")
+	}
+}
+
+func TestLateMessageFromEarlierStreamIsDropped(t *testing.T) {
+	m := resize(InitialModel(testConfig(t)), 80, 30)
+	m.Loading = true
+	m.stream = &conversationStream{id: 1, ch: make(chan tea.Msg), cancel: func() {}}
+	m.stream.stop()
+	// A new request has started before the first one's last chunk arrived
+	m.stream = &conversationStream{id: 2, ch: make(chan tea.Msg), cancel: func() {}}
+
+	for _, msg := range []streamedMsg{
+		{id: 1, msg: StreamMsg{Title: gemini.StepResponse, Content: "late chunk"}},
+		{id: 2, msg: StreamMsg{Title: gemini.StepResponse, Content: "new answer"}},
+	} {
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	out := m.currentResponse.String()
+	if strings.Contains(out, "late chunk") {
+		t.Errorf("output = %q, a message from the earlier request was shown", out)
+	}
+	if !strings.Contains(out, "new answer") {
+		t.Errorf("output = %q, want the current request's answer", out)
+	}
+}
+
+func TestDryRunBadge(t *testing.T) {
+	cfg := testConfig(t)
+	m := resize(InitialModel(cfg), 120, 30)
+	if strings.Contains(m.View(), "[dry-run]") {
+		t.Error("dry-run badge shown without dry-run mode")
+	}
+
+	cfg.Agent.DryRun = true
+	if view := m.View(); !strings.Contains(view, "[dry-run]") {
+		t.Errorf("status bar has no dry-run badge:\n%s", view)
+	}
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestWrapTextMultibyte(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	texts := []string{
+		strings.Repeat("café crème ", 12),
+		strings.Repeat("日本語のテキスト", 10),
+		strings.Repeat("🎉🚀✨", 20),
+		"naïve " + strings.Repeat("é", 100),
+	}
+
+	for _, text := range texts {
+		for _, width := range []int{1, 3, 10, 17} {
+			wrapped := m.wrapText(text, width)
+			if !utf8.ValidString(wrapped) {
+				t.Errorf("wrapping %q at %d broke a rune: %q", text[:12], width, wrapped)
+			}
+			for _, line := range strings.Split(wrapped, "\n") {
+				if w := lipgloss.Width(line); w > width && utf8.RuneCountInString(line) > 1 {
+					t.Errorf("line %q is %d wide, want at most %d", line, w, width)
+				}
+			}
+			if strings.ReplaceAll(strings.ReplaceAll(wrapped, "\n", ""), " ", "") != strings.ReplaceAll(text, " ", "") {
+				t.Errorf("wrapping %q at %d lost characters", text[:12], width)
+			}
+		}
+	}
+}
+
+func TestWrapTextPrefersSpaces(t *testing.T) {
+	m := InitialModel(testConfig(t))
+	if got, want := m.wrapText("über naïve façade", 11), "über naïve\nfaçade"; got != want {
+		t.Errorf("wrapText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"console-ai/pkg/config"
+)
+
+func TestEnterProjectRoot(t *testing.T) {
+	t.Chdir(t.TempDir())
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+
+	cfg := &config.Config{ProjectRoot: "app"}
+	if err := enterProjectRoot(cfg); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.EvalSymlinks(filepath.Join(root, "app"))
+	got, _ := filepath.EvalSymlinks(cfg.ProjectRoot)
+	if got != want || !filepath.IsAbs(cfg.ProjectRoot) {
+		t.Errorf("ProjectRoot = %q, want the absolute path %q", cfg.ProjectRoot, want)
+	}
+	if cwd, _ := os.Getwd(); cwd != cfg.ProjectRoot {
+		t.Errorf("working directory = %q, want the project root %q", cwd, cfg.ProjectRoot)
+	}
+}
+
+func TestEnterProjectRootDefaultsToCwd(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	cfg := &config.Config{}
+	if err := enterProjectRoot(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cwd, _ := os.Getwd(); cfg.ProjectRoot != cwd {
+		t.Errorf("ProjectRoot = %q, want the current directory %q", cfg.ProjectRoot, cwd)
+	}
+}
+
+func TestEnterProjectRootErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, root := range []string{filepath.Join(dir, "missing"), file} {
+		if err := enterProjectRoot(&config.Config{ProjectRoot: root}); err == nil {
+			t.Errorf("entering %s succeeded", root)
+		}
+	}
+	if cwd, _ := os.Getwd(); cwd != dir {
+		t.Errorf("a failed root changed the working directory to %s", cwd)
+	}
+}
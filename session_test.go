@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"console-ai/pkg/config"
+	"console-ai/pkg/history"
+)
+
+func TestRestoreSessionSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		session history.SessionData
+		want    int
+	}{
+		{"nothing saved", history.SessionData{}, 50},
+		{"saved level", history.SessionData{HumorLevel: 20}, 20},
+		{"humor turned off", history.SessionData{HumorLevelSet: true}, 0},
+	}
+	for _, tt := range tests {
+		cfg, err := config.LoadConfig("")
+		if err != nil && !errors.Is(err, config.ErrMissingAPIKey) {
+			t.Fatal(err)
+		}
+		cfg.HumorLevel = 50
+
+		restoreSessionSettings(cfg, &tt.session)
+		if cfg.HumorLevel != tt.want {
+			t.Errorf("%s: HumorLevel = %d, want %d", tt.name, cfg.HumorLevel, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, set at build time with
+// -ldflags "-X main.version=1.2.0 -X main.commit=abc1234 -X main.buildDate=2024-05-01"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// buildMetadata fills in the commit and date from the VCS information Go
+// embeds in the binary when they weren't set via -ldflags.
+func buildMetadata() (string, string, string) {
+	rev, date := commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && rev == "":
+				rev = setting.Value
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	return version, rev, date
+}
+
+// versionString describes the running build for --version and the TUI help
+func versionString() string {
+	v, rev, date := buildMetadata()
+	s := "console-ai " + v
+	if rev != "" {
+		s += " (commit " + rev
+		if date != "" {
+			s += ", built " + date
+		}
+		s += ")"
+	} else if date != "" {
+		s += " (built " + date + ")"
+	}
+	return fmt.Sprintf("%s %s/%s", s, runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestVersionFlag(t *testing.T) {
+	if os.Getenv("CONSOLE_AI_TEST_MAIN") == "1" {
+		os.Args = []string{"console-ai", "--version"}
+		main()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestVersionFlag$")
+	cmd.Env = append(os.Environ(), "CONSOLE_AI_TEST_MAIN=1")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("--version failed: %v", err)
+	}
+	line, _, _ := strings.Cut(string(out), "\n")
+	if !strings.HasPrefix(line, "console-ai ") || len(line) <= len("console-ai ") {
+		t.Errorf("--version printed %q, want a version string", line)
+	}
+}
+
+func TestVersionStringUsesLdflags(t *testing.T) {
+	defer func(v, c, d string) { version, commit, buildDate = v, c, d }(version, commit, buildDate)
+	version, commit, buildDate = "1.2.0", "0123456789abcdef", "2024-05-01"
+
+	got := versionString()
+	if !strings.HasPrefix(got, "console-ai 1.2.0 (commit 0123456789ab, built 2024-05-01) ") {
+		t.Errorf("versionString() = %q", got)
+	}
+}